@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/config"
+	applog "github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/internal/runnerapi"
+	"github.com/makeasinger/api/internal/service"
+	"github.com/makeasinger/api/internal/worker"
+)
+
+// providerAwareRetryDelay paces a failed task's retry to its upstream
+// provider's own circuit breaker cooldown when that breaker is open,
+// instead of asynq's default exponential backoff. Without this, every
+// render/master/export task already queued behind a failing one retries on
+// its own independent schedule the moment Suno or the audio microservice
+// starts erroring, refilling the queue with retries that are just as
+// likely to trip the breaker straight back open; pacing them to the
+// breaker's own clock lets the queue drain only once the provider is
+// actually healthy again. Tasks that fail for any other reason (or whose
+// breaker isn't open) fall back to asynq's default schedule.
+func providerAwareRetryDelay(sunoClient *client.SunoClient, audioClient *client.AudioClient) asynq.RetryDelayFunc {
+	return func(n int, err error, task *asynq.Task) time.Duration {
+		switch task.Type() {
+		case service.TaskTypeRender:
+			if d := sunoClient.BreakerCooldownRemaining(); d > 0 {
+				return d
+			}
+		case service.TaskTypeMaster, service.TaskTypeExport:
+			if d := audioClient.BreakerCooldownRemaining(); d > 0 {
+				return d
+			}
+		}
+		return asynq.DefaultRetryDelayFunc(n, err, task)
+	}
+}
+
+// The runner is the standalone binary that pulls render/master/export tasks
+// off the Asynq queues and executes them against the Suno/audio
+// microservices.
+// It never touches job state directly — all progress, log, completion, and
+// failure reporting goes back to the API over the internal/runnerapi RPC
+// contract, so it can be scaled and deployed independently of the API
+// process (e.g. in containers close to GPUs/FFmpeg).
+func main() {
+	cfgLive, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	cfg := cfgLive.Current()
+	applog.Init(cfg.Server)
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	sunoClient := client.NewSunoClient(&cfg.Suno)
+	audioClient := client.NewAudioClient(&cfg.Audio)
+
+	storageClient, err := client.NewStorageClient(&cfg.Storage)
+	if err != nil {
+		applog.Warn(context.Background(), "storage client not initialized", "err", err)
+	} else if storageClient == nil {
+		applog.Info(context.Background(), "object storage not configured, using mock storage")
+	}
+
+	runnerClient := runnerapi.NewClient(cfg.Runner.APIBaseURL, cfg.Runner.SharedSecret)
+
+	asynqLogLevel := asynq.InfoLevel
+	if strings.EqualFold(cfg.Server.LogLevel, "debug") {
+		asynqLogLevel = asynq.DebugLevel
+	} else if strings.EqualFold(cfg.Server.LogLevel, "warn") {
+		asynqLogLevel = asynq.WarnLevel
+	} else if strings.EqualFold(cfg.Server.LogLevel, "error") {
+		asynqLogLevel = asynq.ErrorLevel
+	}
+
+	srv := asynq.NewServer(
+		asynq.RedisClientOpt{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		},
+		asynq.Config{
+			Concurrency: 10,
+			Queues: map[string]int{
+				"render":  6,
+				"master":  4,
+				"export":  4,
+				"webhook": 2,
+			},
+			LogLevel:       asynqLogLevel,
+			RetryDelayFunc: providerAwareRetryDelay(sunoClient, audioClient),
+		},
+	)
+
+	renderWorker := worker.NewRenderWorker(redisClient, sunoClient, storageClient, runnerClient)
+	masterWorker := worker.NewMasterWorker(redisClient, audioClient, storageClient, runnerClient)
+	exportWorker := worker.NewExportWorker(redisClient, audioClient, storageClient, runnerClient)
+	webhookWorker := worker.NewWebhookWorker()
+
+	mux := asynq.NewServeMux()
+	mux.Use(worker.MetricsMiddleware())
+	mux.HandleFunc(service.TaskTypeRender, renderWorker.ProcessTask)
+	mux.HandleFunc(service.TaskTypeMaster, masterWorker.ProcessTask)
+	mux.HandleFunc(service.TaskTypeExport, exportWorker.ProcessTask)
+	mux.HandleFunc(service.TaskTypeWebhookDelivery, webhookWorker.ProcessTask)
+
+	if cfg.Runner.MetricsPort != "" {
+		go func() {
+			metricsMux := http.NewServeMux()
+			metricsMux.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(":"+cfg.Runner.MetricsPort, metricsMux); err != nil {
+				applog.Warn(context.Background(), "runner metrics server stopped", "err", err)
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-quit
+		applog.Info(context.Background(), "shutting down runner")
+		srv.Shutdown()
+	}()
+
+	applog.Info(context.Background(), "runner starting, consuming render/master/export queues")
+	if err := srv.Run(mux); err != nil {
+		applog.Fatal(context.Background(), "asynq runner error", "err", err)
+	}
+}
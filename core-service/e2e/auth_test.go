@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAuthRevoke_RejectsTokenImmediately(t *testing.T) {
+	ta := setupApp(t)
+
+	token := generateTokenWithJTI(t, "revoke-test-jti-1", time.Now().Add(time.Hour))
+
+	resp, err := doRequest(ta.app, http.MethodPost, "/api/jobs/doesnotexist/archive", "", map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		t.Fatalf("expected token to be accepted before revocation")
+	}
+
+	revokeBody := `{"jti":"revoke-test-jti-1","exp":` + fmt.Sprint(time.Now().Add(time.Hour).Unix()) + `}`
+	resp, err = doRequest(ta.app, http.MethodPost, "/api/auth/revoke", revokeBody, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("revoke request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusNoContent)
+
+	resp, err = doRequest(ta.app, http.MethodPost, "/api/jobs/doesnotexist/archive", "", map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusUnauthorized)
+}
+
+func TestAuthRevoke_RequiresTokenOrJTI(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/auth/revoke", `{}`)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusBadRequest)
+}
+
+// TestAuthRefresh_NotConfigured exercises the refresh endpoint's unconfigured
+// path: the test app has no Zitadel issuer/client-assertion key, so
+// session.Manager is never built and Refresh should report 503 rather than
+// panic on a nil *session.Manager.
+func TestAuthRefresh_NotConfigured(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/auth/refresh", `{"refresh_token":"rt","client_id":"client-1"}`)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusServiceUnavailable)
+}
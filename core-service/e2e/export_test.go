@@ -8,7 +8,7 @@ import (
 	"github.com/google/uuid"
 )
 
-func TestExportMP3_Success(t *testing.T) {
+func TestExportMP3_Queued(t *testing.T) {
 	ta := setupApp(t)
 
 	projectID := uuid.New().String()
@@ -23,18 +23,14 @@ func TestExportMP3_Success(t *testing.T) {
 		t.Fatalf("request failed: %v", err)
 	}
 
-	assertStatus(t, resp, http.StatusOK)
+	assertStatus(t, resp, http.StatusAccepted)
 
 	result := parseJSON(t, resp)
-	if result["fileUrl"] == nil || result["fileUrl"] == "" {
-		t.Error("expected 'fileUrl' in response")
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
 	}
-	if result["format"] != "mp3" {
-		t.Errorf("expected format 'mp3', got %v", result["format"])
-	}
-	// quality is returned as float64 from JSON
-	if result["quality"] != float64(320) {
-		t.Errorf("expected quality 320, got %v", result["quality"])
+	if result["status"] != "queued" {
+		t.Errorf("expected status 'queued', got %v", result["status"])
 	}
 }
 
@@ -69,7 +65,7 @@ func TestExportMP3_InvalidBody(t *testing.T) {
 	assertStatus(t, resp, http.StatusBadRequest)
 }
 
-func TestExportWAV_Success(t *testing.T) {
+func TestExportWAV_Queued(t *testing.T) {
 	ta := setupApp(t)
 
 	projectID := uuid.New().String()
@@ -85,21 +81,15 @@ func TestExportWAV_Success(t *testing.T) {
 		t.Fatalf("request failed: %v", err)
 	}
 
-	assertStatus(t, resp, http.StatusOK)
+	assertStatus(t, resp, http.StatusAccepted)
 
 	result := parseJSON(t, resp)
-	if result["format"] != "wav" {
-		t.Errorf("expected format 'wav', got %v", result["format"])
-	}
-	if result["bitDepth"] != float64(24) {
-		t.Errorf("expected bitDepth 24, got %v", result["bitDepth"])
-	}
-	if result["sampleRate"] != float64(48000) {
-		t.Errorf("expected sampleRate 48000, got %v", result["sampleRate"])
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
 	}
 }
 
-func TestExportStems_Success(t *testing.T) {
+func TestExportStems_Queued(t *testing.T) {
 	ta := setupApp(t)
 
 	projectID := uuid.New().String()
@@ -117,14 +107,183 @@ func TestExportStems_Success(t *testing.T) {
 		t.Fatalf("request failed: %v", err)
 	}
 
-	assertStatus(t, resp, http.StatusOK)
+	assertStatus(t, resp, http.StatusAccepted)
+
+	result := parseJSON(t, resp)
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
+	}
+}
+
+func TestExportALAC_Queued(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/final.wav",
+		"bitDepth": 24,
+		"sampleRate": 48000
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/alac", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusAccepted)
+
+	result := parseJSON(t, resp)
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
+	}
+}
+
+func TestExportFLAC_Queued(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/final.wav",
+		"bitDepth": 24,
+		"sampleRate": 96000,
+		"compressionLevel": 8
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/flac", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusAccepted)
+
+	result := parseJSON(t, resp)
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
+	}
+}
+
+func TestExportFLAC_InvalidCompressionLevel(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/final.wav",
+		"compressionLevel": 9
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/flac", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusBadRequest)
+}
+
+func TestExportAtmos_Queued(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/bed.wav",
+		"bedLayout": "7.1.4",
+		"objectCount": 12,
+		"binaural": true,
+		"objectAutomationUrls": ["https://cdn.example.com/automation/obj1.json"]
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/atmos", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusAccepted)
 
 	result := parseJSON(t, resp)
-	if result["fileUrl"] == nil || result["fileUrl"] == "" {
-		t.Error("expected 'fileUrl' in response")
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
+	}
+}
+
+func TestExportMP3_WithCoverAndLyrics(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/final.wav",
+		"embedLyrics": true,
+		"embedCover": true,
+		"metadata": {
+			"title": "Test Song",
+			"lyrics": "La la la",
+			"coverUrl": "https://cdn.example.com/art/cover.jpg",
+			"coverSize": "1024",
+			"coverFormat": "jpg"
+		}
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/mp3", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
 	}
-	fileCount, ok := result["fileCount"].(float64)
-	if !ok || fileCount < 1 {
-		t.Errorf("expected fileCount >= 1, got %v", result["fileCount"])
+
+	assertStatus(t, resp, http.StatusAccepted)
+
+	result := parseJSON(t, resp)
+	if result["jobId"] == nil || result["jobId"] == "" {
+		t.Error("expected 'jobId' in response")
 	}
 }
+
+func TestExportMP3_InvalidCoverFormat(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/final.wav",
+		"metadata": {
+			"coverUrl": "https://cdn.example.com/art/cover.tiff",
+			"coverFormat": "tiff"
+		}
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/mp3", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusBadRequest)
+}
+
+func TestExportAtmos_MissingBedLayout(t *testing.T) {
+	ta := setupApp(t)
+
+	projectID := uuid.New().String()
+	body := fmt.Sprintf(`{
+		"projectId": "%s",
+		"masterFileUrl": "https://cdn.example.com/master/bed.wav"
+	}`, projectID)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/export/atmos", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusBadRequest)
+}
+
+func TestExportStatus_NotFound(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodGet, "/api/export/status/"+uuid.New().String(), "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusNotFound)
+}
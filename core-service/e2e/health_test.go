@@ -51,6 +51,38 @@ func TestAuthVerify_NoToken(t *testing.T) {
 	assertStatus(t, resp, http.StatusUnauthorized)
 }
 
+func TestCORS_AllowedOrigin(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doRequest(ta.app, http.MethodGet, "/health", "", map[string]string{
+		"Origin": "https://app.example.com",
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusOK)
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("expected Access-Control-Allow-Origin to echo the allowed origin, got %q", got)
+	}
+}
+
+func TestCORS_RejectedOrigin(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doRequest(ta.app, http.MethodGet, "/health", "", map[string]string{
+		"Origin": "https://evil.example.com",
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusOK)
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no Access-Control-Allow-Origin for an unlisted origin, got %q", got)
+	}
+}
+
 func TestAuthVerify_ValidToken(t *testing.T) {
 	ta := setupApp(t)
 
@@ -1,8 +1,10 @@
 package e2e
 
 import (
+	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -20,10 +22,13 @@ func setupLyricsRealApp(t *testing.T) *fiber.App {
 	t.Helper()
 	loadEnvFile(t)
 
-	cfg, err := config.Load()
+	cfgLive, err := config.Load()
 	if err != nil {
 		t.Fatalf("failed to load config: %v", err)
 	}
+	cfg := cfgLive.Current()
+	cfg.RateLimit = map[string]config.RateLimitConfig{"free": {LyricsPerMin: 10000}}
+	cfgLive = config.NewLive(cfg)
 
 	if cfg.Groq.APIKey == "" {
 		t.Skip("skipping: GROQ_API_KEY not configured")
@@ -45,16 +50,18 @@ func setupLyricsRealApp(t *testing.T) *fiber.App {
 		t.Skip("skipping: Groq client not configured")
 	}
 
-	lyricsService := service.NewLyricsService(groqClient)
+	lyricsCache := service.NewLyricsCache(redisClient, time.Duration(cfg.Lyrics.CacheTTL)*time.Second)
+	lyricsAgents := service.BuildLyricsAgentChain(context.Background(), cfg.Lyrics.EffectiveAgents(), cfg.Lyrics.FilesystemPath, cfg.Lyrics.LRCLIBBaseURL, groqClient, lyricsCache, cfg.Lyrics.BreakerThreshold, cfg.Lyrics.BreakerCooldownMS)
+	lyricsService := service.NewLyricsService(lyricsAgents, lyricsCache)
 	lyricsHandler := handler.NewLyricsHandler(lyricsService, validate)
 
 	authMiddleware := middleware.NewLegacyAuthMiddleware(testJWTSecret)
-	rateLimiter := middleware.NewRateLimiter(redisClient)
+	rateLimiter := middleware.NewRateLimiter(redisClient, cfgLive)
 
 	app := fiber.New(fiber.Config{BodyLimit: 50 * 1024 * 1024})
 
 	api := app.Group("/api", authMiddleware.Authenticate())
-	lyrics := api.Group("/lyrics", rateLimiter.LyricsLimit(10000))
+	lyrics := api.Group("/lyrics", rateLimiter.LyricsLimit())
 	lyrics.Post("/generate", lyricsHandler.Generate)
 	lyrics.Post("/rewrite", lyricsHandler.Rewrite)
 
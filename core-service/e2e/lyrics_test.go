@@ -2,7 +2,10 @@ package e2e
 
 import (
 	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func TestLyricsGenerate_Success(t *testing.T) {
@@ -144,3 +147,83 @@ func TestLyricsRewrite_ValidationError(t *testing.T) {
 		t.Errorf("expected error code VALIDATION_ERROR, got %v", errObj["code"])
 	}
 }
+
+func TestLyricsExport_LRC(t *testing.T) {
+	ta := setupApp(t)
+
+	body := `{
+		"projectId": "` + uuid.New().String() + `",
+		"format": "lrc",
+		"includeTitle": true,
+		"title": "Test Song",
+		"lines": [
+			{"startSeconds": 0, "text": "First line"},
+			{"startSeconds": 12.34, "text": "Second line"}
+		]
+	}`
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/lyrics/export", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusOK)
+
+	result := parseJSON(t, resp)
+	content, ok := result["content"].(string)
+	if !ok {
+		t.Fatal("expected 'content' to be a string")
+	}
+	if !strings.Contains(content, "[ti:Test Song]") {
+		t.Errorf("expected title tag in content, got %q", content)
+	}
+	if !strings.Contains(content, "[00:12.34]Second line") {
+		t.Errorf("expected timed second line in content, got %q", content)
+	}
+}
+
+func TestLyricsExport_SRT(t *testing.T) {
+	ta := setupApp(t)
+
+	body := `{
+		"projectId": "` + uuid.New().String() + `",
+		"format": "srt",
+		"lines": [
+			{"startSeconds": 0, "text": "First line"},
+			{"startSeconds": 5, "text": "Second line"}
+		]
+	}`
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/lyrics/export", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusOK)
+
+	result := parseJSON(t, resp)
+	content, ok := result["content"].(string)
+	if !ok {
+		t.Fatal("expected 'content' to be a string")
+	}
+	if !strings.Contains(content, "00:00:00,000 --> 00:00:05,000") {
+		t.Errorf("expected first cue timing in content, got %q", content)
+	}
+}
+
+func TestLyricsExport_InvalidBody(t *testing.T) {
+	ta := setupApp(t)
+
+	// Missing lines
+	body := `{
+		"projectId": "` + uuid.New().String() + `",
+		"format": "lrc"
+	}`
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/lyrics/export", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusBadRequest)
+}
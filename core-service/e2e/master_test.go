@@ -89,6 +89,102 @@ func TestMasterFinal_Success(t *testing.T) {
 	}
 }
 
+func TestMasterFinal_IdempotencyKeyReplaysResponse(t *testing.T) {
+	ta := setupApp(t)
+	headers := map[string]string{
+		"Authorization":   "Bearer " + generateToken(t),
+		"Idempotency-Key": uuid.New().String(),
+	}
+	body := validMasterFinalBody()
+
+	resp, err := doRequest(ta.app, http.MethodPost, "/api/master/final", body, headers)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	first := parseJSON(t, resp)
+
+	// Same key, same body: the second call must never reach MasterHandler
+	// again and instead replay the first response verbatim, so a client
+	// retrying after a dropped connection doesn't start a second job.
+	resp, err = doRequest(ta.app, http.MethodPost, "/api/master/final", body, headers)
+	if err != nil {
+		t.Fatalf("replay request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	replayed := parseJSON(t, resp)
+
+	if replayed["jobId"] != first["jobId"] {
+		t.Errorf("expected replayed response to reuse jobId %v, got %v", first["jobId"], replayed["jobId"])
+	}
+}
+
+func TestMasterFinal_IdempotencyKeyConflictOnDifferentBody(t *testing.T) {
+	ta := setupApp(t)
+	headers := map[string]string{
+		"Authorization":   "Bearer " + generateToken(t),
+		"Idempotency-Key": uuid.New().String(),
+	}
+
+	resp, err := doRequest(ta.app, http.MethodPost, "/api/master/final", validMasterFinalBody(), headers)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+
+	// Same key, different body (validMasterFinalBody generates fresh IDs
+	// each call): reusing the key this way is a client bug, not a retry,
+	// so it must be rejected rather than silently replayed or re-run.
+	resp, err = doRequest(ta.app, http.MethodPost, "/api/master/final", validMasterFinalBody(), headers)
+	if err != nil {
+		t.Fatalf("second request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusConflict)
+
+	result := parseJSON(t, resp)
+	errObj := result["error"].(map[string]interface{})
+	if errObj["code"] != "IDEMPOTENCY_KEY_CONFLICT" {
+		t.Errorf("expected error code IDEMPOTENCY_KEY_CONFLICT, got %v", errObj["code"])
+	}
+}
+
+func TestMasterBatch_PerItemValidationErrors(t *testing.T) {
+	ta := setupApp(t)
+
+	// Both items are invalid (missing required fields), so the batch
+	// never needs to reach MasterService -- it should still respond 202
+	// with each item's errors keyed by its index rather than aborting on
+	// the first bad entry.
+	body := `{"items": [{"profile": "not-a-real-profile"}, {"projectId": "not-a-uuid"}]}`
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/master/batch", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusAccepted)
+
+	result := parseJSON(t, resp)
+	if result["groupId"] == nil || result["groupId"] == "" {
+		t.Error("expected 'groupId' in response")
+	}
+
+	items, ok := result["items"].([]interface{})
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected 2 items, got %v", result["items"])
+	}
+	for i, raw := range items {
+		item := raw.(map[string]interface{})
+		if item["jobId"] != nil && item["jobId"] != "" {
+			t.Errorf("item %d: expected no jobId for an invalid item, got %v", i, item["jobId"])
+		}
+		errs, ok := item["errors"].([]interface{})
+		if !ok || len(errs) == 0 {
+			t.Errorf("item %d: expected validation errors, got %v", i, item["errors"])
+		}
+	}
+}
+
 func TestMasterStatus_Success(t *testing.T) {
 	ta := setupApp(t)
 
@@ -118,6 +214,91 @@ func TestMasterStatus_Success(t *testing.T) {
 	}
 }
 
+func TestMasterEvents_Success(t *testing.T) {
+	ta := setupApp(t)
+
+	jobID := uuid.New().String()
+	resp, err := doAuthRequest(t, ta.app, http.MethodGet, "/api/master/events/"+jobID, "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusOK)
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", got)
+	}
+}
+
+func TestMasterStatusStream_Success(t *testing.T) {
+	ta := setupApp(t)
+
+	// /status/:jobId/stream is an alias for /events/:jobId, for clients
+	// that expect the stream to live under the status path.
+	jobID := uuid.New().String()
+	resp, err := doAuthRequest(t, ta.app, http.MethodGet, "/api/master/status/"+jobID+"/stream", "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusOK)
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", got)
+	}
+}
+
+func TestMasterReplayWebhook_NotFound(t *testing.T) {
+	ta := setupApp(t)
+
+	fakeJobID := uuid.New().String()
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/master/"+fakeJobID+"/webhook/replay", "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusNotFound)
+}
+
+func TestMasterReplayWebhook_JobNotCompleted(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/master/final", validMasterFinalBody())
+	if err != nil {
+		t.Fatalf("final request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	jobID := parseJSON(t, resp)["jobId"].(string)
+
+	// The job is still queued, so there's nothing to replay yet.
+	resp, err = doAuthRequest(t, ta.app, http.MethodPost, "/api/master/"+jobID+"/webhook/replay", "")
+	if err != nil {
+		t.Fatalf("replay request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusBadRequest)
+	result := parseJSON(t, resp)
+	errObj := result["error"].(map[string]interface{})
+	if errObj["code"] != "JOB_NOT_COMPLETED" {
+		t.Errorf("expected error code JOB_NOT_COMPLETED, got %v", errObj["code"])
+	}
+}
+
+func TestMasterReplayWebhook_RequiresAdminOrOwnerRole(t *testing.T) {
+	ta := setupApp(t)
+
+	fakeJobID := uuid.New().String()
+	token := generateTokenWithClaims(t, []string{"member"}, "render:write")
+	resp, err := doRequest(ta.app, http.MethodPost, "/api/master/"+fakeJobID+"/webhook/replay", "", map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("replay request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusForbidden)
+}
+
 func TestMasterStatus_NotFound(t *testing.T) {
 	ta := setupApp(t)
 
@@ -2,6 +2,7 @@ package e2e
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/makeasinger/api/internal/audit"
 	"github.com/makeasinger/api/internal/auth"
 	"github.com/makeasinger/api/internal/client"
 	"github.com/makeasinger/api/internal/config"
@@ -61,10 +63,15 @@ func setupRealApp(t *testing.T) (*fiber.App, func()) {
 	t.Helper()
 	loadEnvFile(t)
 
-	cfg, err := config.Load()
+	cfgLive, err := config.Load()
 	if err != nil {
 		t.Fatalf("failed to load config: %v", err)
 	}
+	cfg := cfgLive.Current()
+	cfg.RateLimit = map[string]config.RateLimitConfig{
+		"free": {LyricsPerMin: 10000, RenderPerHour: 10000, MasterPerHour: 10000, ExportPerHour: 10000, UploadPerHour: 10000},
+	}
+	cfgLive = config.NewLive(cfg)
 
 	if cfg.Suno.APIKey == "" {
 		t.Skip("skipping: SUNO_API_KEY not configured")
@@ -92,8 +99,8 @@ func setupRealApp(t *testing.T) (*fiber.App, func()) {
 
 	// R2 client (optional)
 	var r2Client *client.R2Client
-	if cfg.R2.AccessKeyID != "" && cfg.R2.SecretAccessKey != "" {
-		r2Client, _ = client.NewR2Client(&cfg.R2)
+	if cfg.Storage.AccessKeyID != "" && cfg.Storage.SecretAccessKey != "" {
+		r2Client, _ = client.NewR2Client(&cfg.Storage)
 	}
 
 	// WebSocket hub
@@ -101,15 +108,18 @@ func setupRealApp(t *testing.T) (*fiber.App, func()) {
 	go hub.Run()
 
 	// Services
-	lyricsService := service.NewLyricsService(groqClient)
-	renderService := service.NewRenderService(redisClient, asynqClient)
+	lyricsCache := service.NewLyricsCache(redisClient, time.Duration(cfg.Lyrics.CacheTTL)*time.Second)
+	lyricsAgents := service.BuildLyricsAgentChain(context.Background(), cfg.Lyrics.EffectiveAgents(), cfg.Lyrics.FilesystemPath, cfg.Lyrics.LRCLIBBaseURL, groqClient, lyricsCache, cfg.Lyrics.BreakerThreshold, cfg.Lyrics.BreakerCooldownMS)
+	lyricsService := service.NewLyricsService(lyricsAgents, lyricsCache)
+	renderService := service.NewRenderService(redisClient, asynqClient, time.Duration(cfg.Runner.LeaseTimeoutSeconds)*time.Second)
 	masterService := service.NewMasterService(redisClient, asynqClient)
-	exportService := service.NewExportService(nil, nil)
+	exportService := service.NewExportService(nil, nil, service.NewLyricsTimedService())
 	uploadService := service.NewUploadService(nil)
 
 	// Handlers
+	auditRecorder := audit.NewRecorder(redisClient, cfg.Server.AuditEnabled)
 	lyricsHandler := handler.NewLyricsHandler(lyricsService, validate)
-	renderHandler := handler.NewRenderHandler(renderService, validate)
+	renderHandler := handler.NewRenderHandler(renderService, validate, auditRecorder)
 	masterHandler := handler.NewMasterHandler(masterService, validate)
 	exportHandler := handler.NewExportHandler(exportService, validate)
 	uploadHandler := handler.NewUploadHandler(uploadService, validate)
@@ -117,7 +127,7 @@ func setupRealApp(t *testing.T) (*fiber.App, func()) {
 
 	// Middleware
 	authMiddleware := middleware.NewLegacyAuthMiddleware(testJWTSecret)
-	rateLimiter := middleware.NewRateLimiter(redisClient)
+	rateLimiter := middleware.NewRateLimiter(redisClient, cfgLive)
 
 	// Fiber app
 	app := fiber.New(fiber.Config{BodyLimit: 50 * 1024 * 1024})
@@ -132,28 +142,28 @@ func setupRealApp(t *testing.T) (*fiber.App, func()) {
 
 	api := app.Group("/api", authMiddleware.Authenticate())
 
-	lyrics := api.Group("/lyrics", rateLimiter.LyricsLimit(10000))
+	lyrics := api.Group("/lyrics", rateLimiter.LyricsLimit())
 	lyrics.Post("/generate", lyricsHandler.Generate)
 	lyrics.Post("/rewrite", lyricsHandler.Rewrite)
 
 	render := api.Group("/render")
-	render.Post("/start", rateLimiter.RenderLimit(10000), renderHandler.Start)
+	render.Post("/start", rateLimiter.RenderLimit(), renderHandler.Start)
 	render.Get("/status/:jobId", renderHandler.Status)
 	render.Get("/result/:jobId", renderHandler.Result)
 	render.Post("/cancel/:jobId", renderHandler.Cancel)
 
-	master := api.Group("/master", rateLimiter.MasterLimit(10000))
+	master := api.Group("/master", rateLimiter.MasterLimit())
 	master.Post("/preview", masterHandler.Preview)
 	master.Post("/final", masterHandler.Final)
 	master.Get("/status/:jobId", masterHandler.Status)
 	master.Get("/result/:jobId", masterHandler.Result)
 
-	export := api.Group("/export", rateLimiter.ExportLimit(10000))
+	export := api.Group("/export", rateLimiter.ExportLimit())
 	export.Post("/mp3", exportHandler.MP3)
 	export.Post("/wav", exportHandler.WAV)
 	export.Post("/stems", exportHandler.Stems)
 
-	upload := api.Group("/upload", rateLimiter.UploadLimit(10000))
+	upload := api.Group("/upload", rateLimiter.UploadLimit())
 	upload.Post("/vocal", uploadHandler.Vocal)
 	upload.Delete("/vocal/:takeId", uploadHandler.DeleteVocal)
 
@@ -121,6 +121,72 @@ func TestRenderStatus_NotFound(t *testing.T) {
 	}
 }
 
+func TestRenderEvents_Success(t *testing.T) {
+	ta := setupApp(t)
+
+	jobID := uuid.New().String()
+	resp, err := doAuthRequest(t, ta.app, http.MethodGet, "/api/render/events/"+jobID, "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusOK)
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", got)
+	}
+}
+
+func TestRenderEvents_NoAuth(t *testing.T) {
+	ta := setupApp(t)
+
+	jobID := uuid.New().String()
+	resp, err := doRequest(ta.app, http.MethodGet, "/api/render/events/"+jobID, "", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assertStatus(t, resp, http.StatusUnauthorized)
+}
+
+func TestRenderStart_MissingScope(t *testing.T) {
+	ta := setupApp(t)
+
+	token := generateTokenWithClaims(t, []string{"admin"}, "lyrics:write")
+	resp, err := doRequest(ta.app, http.MethodPost, "/api/render/start", validRenderStartBody(), map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusForbidden)
+}
+
+func TestRenderCancel_RequiresAdminOrOwnerRole(t *testing.T) {
+	ta := setupApp(t)
+
+	// Start a render as a normal authorized user.
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/render/start", validRenderStartBody())
+	if err != nil {
+		t.Fatalf("start request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	startResult := parseJSON(t, resp)
+	jobID := startResult["jobId"].(string)
+
+	// Cancel with a token that has neither the admin nor owner role.
+	token := generateTokenWithClaims(t, []string{"member"}, "render:write")
+	resp, err = doRequest(ta.app, http.MethodPost, "/api/render/cancel/"+jobID, "", map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("cancel request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusForbidden)
+}
+
 func TestRenderCancel_Success(t *testing.T) {
 	ta := setupApp(t)
 
@@ -149,3 +215,120 @@ func TestRenderCancel_Success(t *testing.T) {
 		t.Errorf("expected status 'canceled', got %v", cancelResult["status"])
 	}
 }
+
+func TestJobsCancel_Success(t *testing.T) {
+	ta := setupApp(t)
+
+	// Start a render
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/render/start", validRenderStartBody())
+	if err != nil {
+		t.Fatalf("start request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	startResult := parseJSON(t, resp)
+	jobID := startResult["jobId"].(string)
+
+	// Cancel it through the job-type-agnostic endpoint
+	resp, err = doAuthRequest(t, ta.app, http.MethodDelete, "/api/jobs/"+jobID, "")
+	if err != nil {
+		t.Fatalf("cancel request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusOK)
+
+	cancelResult := parseJSON(t, resp)
+	if cancelResult["status"] != "canceled" {
+		t.Errorf("expected status 'canceled', got %v", cancelResult["status"])
+	}
+
+	// Cancelling an already-canceled job is a conflict, not a silent success.
+	resp, err = doAuthRequest(t, ta.app, http.MethodDelete, "/api/jobs/"+jobID, "")
+	if err != nil {
+		t.Fatalf("second cancel request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusConflict)
+}
+
+func TestJobsStatus_Success(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/render/start", validRenderStartBody())
+	if err != nil {
+		t.Fatalf("start request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	startResult := parseJSON(t, resp)
+	jobID := startResult["jobId"].(string)
+
+	// Read it back through the job-type-agnostic endpoint rather than
+	// /api/render/status/:jobId.
+	resp, err = doAuthRequest(t, ta.app, http.MethodGet, "/api/jobs/"+jobID, "")
+	if err != nil {
+		t.Fatalf("status request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusOK)
+
+	statusResult := parseJSON(t, resp)
+	if statusResult["jobId"] != jobID {
+		t.Errorf("expected jobId %s, got %v", jobID, statusResult["jobId"])
+	}
+}
+
+func TestJobsStatus_NotFound(t *testing.T) {
+	ta := setupApp(t)
+
+	fakeJobID := uuid.New().String()
+	resp, err := doAuthRequest(t, ta.app, http.MethodGet, "/api/jobs/"+fakeJobID, "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusNotFound)
+}
+
+func TestJobsWait_TimesOutOnQueuedJob(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/render/start", validRenderStartBody())
+	if err != nil {
+		t.Fatalf("start request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	startResult := parseJSON(t, resp)
+	jobID := startResult["jobId"].(string)
+
+	// No worker is running in this test, so the job stays queued and
+	// /wait must return once its 1s timeout elapses rather than hanging.
+	resp, err = doAuthRequest(t, ta.app, http.MethodGet, "/api/jobs/"+jobID+"/wait?timeout=1", "")
+	if err != nil {
+		t.Fatalf("wait request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusOK)
+
+	waitResult := parseJSON(t, resp)
+	if waitResult["status"] != "queued" {
+		t.Errorf("expected status 'queued', got %v", waitResult["status"])
+	}
+}
+
+func TestJobsCancel_RequiresAdminOrOwnerRole(t *testing.T) {
+	ta := setupApp(t)
+
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/render/start", validRenderStartBody())
+	if err != nil {
+		t.Fatalf("start request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusAccepted)
+	startResult := parseJSON(t, resp)
+	jobID := startResult["jobId"].(string)
+
+	token := generateTokenWithClaims(t, []string{"member"}, "render:write")
+	resp, err = doRequest(ta.app, http.MethodDelete, "/api/jobs/"+jobID, "", map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		t.Fatalf("cancel request failed: %v", err)
+	}
+	assertStatus(t, resp, http.StatusForbidden)
+}
@@ -6,19 +6,14 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/hibiken/asynq"
-	"github.com/redis/go-redis/v9"
 
+	"github.com/makeasinger/api/internal/app"
 	"github.com/makeasinger/api/internal/auth"
-	"github.com/makeasinger/api/internal/client"
 	"github.com/makeasinger/api/internal/config"
-	"github.com/makeasinger/api/internal/handler"
-	"github.com/makeasinger/api/internal/middleware"
-	"github.com/makeasinger/api/internal/service"
 )
 
 const testJWTSecret = "test-secret-for-e2e"
@@ -28,114 +23,56 @@ type testApp struct {
 	app *fiber.App
 }
 
-// setupApp creates a Fiber app identical to main.go but with unconfigured external clients.
-// This triggers mock/fallback responses in all services.
+// setupApp wires a Fiber app through app.InitializeTestApp: real Redis/Asynq
+// on DB 15, but every external client left unconfigured so services fall
+// back to their mock paths. Rate limits are set high so tests don't trip
+// them.
 func setupApp(t *testing.T) *testApp {
 	t.Helper()
 
-	// Redis (localhost — must be running)
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-		DB:   15, // use DB 15 for tests to avoid collision
-	})
+	testConfig := &config.Config{
+		Redis:  config.RedisConfig{Addr: "localhost:6379", DB: 15},
+		JWT:    config.JWTConfig{Secret: testJWTSecret},
+		Server: config.ServerConfig{AllowedOrigins: "https://app.example.com"},
+		RateLimit: map[string]config.RateLimitConfig{
+			"free": {
+				LyricsPerMin:  10000,
+				RenderPerHour: 10000,
+				MasterPerHour: 10000,
+				ExportPerHour: 10000,
+				UploadPerHour: 10000,
+			},
+		},
+	}
 
-	// Asynq client
-	asynqClient := asynq.NewClient(asynq.RedisClientOpt{
-		Addr: "localhost:6379",
-		DB:   15,
-	})
-	t.Cleanup(func() { asynqClient.Close() })
-
-	validate := validator.New()
-
-	// External clients — all unconfigured so services use mock fallbacks
-	groqClient := client.NewGroqClient(&config.GroqConfig{}) // no API key → mock
-	// r2Client = nil → mock
-	// audioClient = nil → mock
-	// sunoClient not needed for handler tests
-
-	// Services
-	lyricsService := service.NewLyricsService(groqClient)
-	renderService := service.NewRenderService(redisClient, asynqClient)
-	masterService := service.NewMasterService(redisClient, asynqClient)
-	exportService := service.NewExportService(nil, nil) // nil triggers mock fallbacks
-	uploadService := service.NewUploadService(nil)
-
-	// Handlers
-	lyricsHandler := handler.NewLyricsHandler(lyricsService, validate)
-	renderHandler := handler.NewRenderHandler(renderService, validate)
-	masterHandler := handler.NewMasterHandler(masterService, validate)
-	exportHandler := handler.NewExportHandler(exportService, validate)
-	uploadHandler := handler.NewUploadHandler(uploadService, validate)
-
-	// Auth handler (for /auth/verify)
-	authHandler := handler.NewAuthHandler(nil, testJWTSecret)
-
-	// Auth middleware — legacy HMAC only
-	authMiddleware := middleware.NewLegacyAuthMiddleware(testJWTSecret)
-	rateLimiter := middleware.NewRateLimiter(redisClient)
-
-	// Fiber app
-	app := fiber.New(fiber.Config{
-		BodyLimit: 50 * 1024 * 1024,
-	})
+	a, cleanup, err := app.InitializeTestApp(config.NewLive(testConfig))
+	if err != nil {
+		t.Fatalf("failed to initialize test app: %v", err)
+	}
+	t.Cleanup(cleanup)
+	go a.Hub.Run()
 
-	// Base routes
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{"timestamp": 1234567890})
-	})
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"status": "ok",
-			"services": fiber.Map{
-				"groq":  false,
-				"suno":  false,
-				"r2":    false,
-				"audio": false,
-				"auth":  true,
-			},
-		})
-	})
-	app.Get("/auth/verify", authHandler.Verify)
-
-	// API routes (authenticated)
-	api := app.Group("/api", authMiddleware.Authenticate())
-
-	// Use very high rate limits so tests don't get blocked
-	lyrics := api.Group("/lyrics", rateLimiter.LyricsLimit(10000))
-	lyrics.Post("/generate", lyricsHandler.Generate)
-	lyrics.Post("/rewrite", lyricsHandler.Rewrite)
-
-	render := api.Group("/render")
-	render.Post("/start", rateLimiter.RenderLimit(10000), renderHandler.Start)
-	render.Get("/status/:jobId", renderHandler.Status)
-	render.Get("/result/:jobId", renderHandler.Result)
-	render.Post("/cancel/:jobId", renderHandler.Cancel)
-
-	master := api.Group("/master", rateLimiter.MasterLimit(10000))
-	master.Post("/preview", masterHandler.Preview)
-	master.Post("/final", masterHandler.Final)
-	master.Get("/status/:jobId", masterHandler.Status)
-	master.Get("/result/:jobId", masterHandler.Result)
-
-	export := api.Group("/export", rateLimiter.ExportLimit(10000))
-	export.Post("/mp3", exportHandler.MP3)
-	export.Post("/wav", exportHandler.WAV)
-	export.Post("/stems", exportHandler.Stems)
-
-	upload := api.Group("/upload", rateLimiter.UploadLimit(10000))
-	upload.Post("/vocal", uploadHandler.Vocal)
-	upload.Delete("/vocal/:takeId", uploadHandler.DeleteVocal)
-
-	return &testApp{app: app}
+	return &testApp{app: a.Fiber}
 }
 
-// generateToken creates a legacy HMAC JWT token for test requests.
+// generateToken creates a legacy HMAC JWT token for test requests, carrying
+// the roles/scope an ordinary authenticated user holds in production
+// (admin/owner plus render:write) so it satisfies every role/scope guard.
 func generateToken(t *testing.T) string {
+	t.Helper()
+	return generateTokenWithClaims(t, []string{"admin", "owner"}, "render:write")
+}
+
+// generateTokenWithClaims creates a legacy HMAC JWT token with an explicit
+// roles/scope set, for tests that need to exercise RequireRole/RequireScope
+// with a token missing the required grant.
+func generateTokenWithClaims(t *testing.T, roles []string, scope string) string {
 	t.Helper()
 	claims := auth.LegacyClaims{
 		UserID: "test-user-123",
 		Email:  "test@example.com",
+		Roles:  roles,
+		Scope:  scope,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer: "makeasinger-api",
 		},
@@ -148,6 +85,27 @@ func generateToken(t *testing.T) string {
 	return signed
 }
 
+// generateTokenWithJTI creates a legacy HMAC JWT token with an explicit jti
+// and expiry, for tests that need to revoke the exact token they hold.
+func generateTokenWithJTI(t *testing.T, jti string, expiresAt time.Time) string {
+	t.Helper()
+	claims := auth.LegacyClaims{
+		UserID: "test-user-123",
+		Email:  "test@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "makeasinger-api",
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("failed to generate test token: %v", err)
+	}
+	return signed
+}
+
 // doRequest is a helper to perform HTTP requests against the test app.
 func doRequest(app *fiber.App, method, path string, body string, headers map[string]string) (*http.Response, error) {
 	var bodyReader io.Reader
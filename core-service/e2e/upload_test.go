@@ -2,6 +2,7 @@ package e2e
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -11,6 +12,40 @@ import (
 	"github.com/google/uuid"
 )
 
+// buildMinimalWAV returns a real, parseable RIFF/WAVE file — 16-bit mono PCM
+// at 44.1kHz — with correctly declared chunk sizes, so it passes the
+// container probe the upload handler runs before accepting a file.
+func buildMinimalWAV() []byte {
+	const (
+		sampleRate = 44100
+		channels   = 1
+		bitDepth   = 16
+	)
+	data := make([]byte, 1024)
+	blockAlign := channels * bitDepth / 8
+	byteRate := sampleRate * blockAlign
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(byteRate))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(bitDepth))
+
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+
+	return buf.Bytes()
+}
+
 // createMultipartVocalRequest builds a multipart/form-data request with a fake audio file.
 func createMultipartVocalRequest(t *testing.T, token string) *http.Request {
 	t.Helper()
@@ -25,7 +60,8 @@ func createMultipartVocalRequest(t *testing.T, token string) *http.Request {
 	_ = writer.WriteField("sectionId", sectionID)
 	_ = writer.WriteField("takeName", "Take 1")
 
-	// Create a fake WAV file with correct Content-Type
+	// Attach a real, parseable WAV file (the handler probes the container
+	// itself, so Content-Type alone no longer determines acceptance).
 	partHeader := make(textproto.MIMEHeader)
 	partHeader.Set("Content-Disposition", `form-data; name="file"; filename="vocal.wav"`)
 	partHeader.Set("Content-Type", "audio/wav")
@@ -33,11 +69,7 @@ func createMultipartVocalRequest(t *testing.T, token string) *http.Request {
 	if err != nil {
 		t.Fatalf("failed to create form file: %v", err)
 	}
-	// Minimal WAV header + some data
-	wavHeader := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
-	fakeData := make([]byte, 1024)
-	_, _ = part.Write(wavHeader)
-	_, _ = part.Write(fakeData)
+	_, _ = part.Write(buildMinimalWAV())
 
 	writer.Close()
 
@@ -113,6 +145,30 @@ func TestUploadVocal_MissingFile(t *testing.T) {
 	assertStatus(t, resp, http.StatusBadRequest)
 }
 
+func TestPresignVocal_ValidationError(t *testing.T) {
+	ta := setupApp(t)
+
+	body := fmt.Sprintf(`{"projectId":%q,"sectionId":%q,"contentType":"audio/wav"}`, uuid.New().String(), uuid.New().String())
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, "/api/upload/vocal/presign", body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusBadRequest)
+}
+
+func TestCompletePresignedVocal_NotFound(t *testing.T) {
+	ta := setupApp(t)
+
+	path := fmt.Sprintf("/api/upload/vocal/complete/%s", uuid.New().String())
+	resp, err := doAuthRequest(t, ta.app, http.MethodPost, path, "")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	assertStatus(t, resp, http.StatusNotFound)
+}
+
 func TestDeleteVocal_Success(t *testing.T) {
 	ta := setupApp(t)
 
@@ -0,0 +1,23 @@
+// Package app assembles the Fiber application from a *config.Config. It is
+// the single place that wires clients, services, and handlers together —
+// cmd/server and the e2e tests both build an *App through InitializeApp /
+// InitializeTestApp instead of repeating that plumbing themselves.
+package app
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/service"
+	ws "github.com/makeasinger/api/internal/websocket"
+)
+
+// App bundles the fully-wired components the process entrypoints need once
+// construction is done: the Fiber app with every route already registered,
+// the WebSocket hub (its Run loop is still the caller's to start), and the
+// Archiver (whose OngoingArchivings wait group the caller drains on
+// shutdown).
+type App struct {
+	Fiber    *fiber.App
+	Hub      *ws.Hub
+	Archiver *service.Archiver
+}
@@ -0,0 +1,446 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	fiberSwagger "github.com/gofiber/swagger"
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/auth"
+	"github.com/makeasinger/api/internal/auth/session"
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/config"
+	"github.com/makeasinger/api/internal/errs"
+	"github.com/makeasinger/api/internal/handler"
+	"github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/internal/middleware"
+	"github.com/makeasinger/api/internal/service"
+	"github.com/makeasinger/api/internal/subsonic"
+	ws "github.com/makeasinger/api/internal/websocket"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// provideConfig snapshots a *config.Live into the *config.Config the rest
+// of the wire graph is built from. Everything except the rate limiter
+// (which holds the *config.Live directly so it sees later reloads) is wired
+// once at startup from this snapshot.
+func provideConfig(cfgLive *config.Live) *config.Config {
+	return cfgLive.Current()
+}
+
+// provideStorageClient builds the configured object storage backend (R2,
+// S3, MinIO, GCS, or the local-filesystem "fs" provider -- see
+// config.StorageConfig.Provider). A nil StorageClient (rather than an
+// error) is how callers are told to fall back to mock storage, so a
+// missing/invalid config doesn't fail the whole graph.
+func provideStorageClient(cfg config.StorageConfig) client.StorageClient {
+	storageClient, err := client.NewStorageClient(&cfg)
+	if err != nil {
+		log.Warn(context.Background(), "storage client not initialized", "err", err)
+		return nil
+	}
+	if storageClient == nil {
+		log.Info(context.Background(), "object storage not configured, using mock storage")
+	}
+	return storageClient
+}
+
+// provideAsynqInspector builds the read/requeue handle used by the
+// /api/admin/queues endpoints. It opens its own Redis connection rather
+// than reusing redisClient's go-redis client because asynq.Inspector only
+// accepts an asynq.RedisConnOpt, the same way asynqClient already does.
+func provideAsynqInspector(cfg config.RedisConfig) *asynq.Inspector {
+	return asynq.NewInspector(asynq.RedisClientOpt{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
+
+// provideRevocationStore builds the store backing POST /api/auth/revoke.
+// It's Redis-backed rather than in-memory so a revocation issued against
+// one API replica is immediately visible to the others, matching how the
+// rest of the app already treats Redis as a hard dependency (rate limiter,
+// websocket hub, job state).
+func provideRevocationStore(redisClient *redis.Client) auth.RevocationStore {
+	return auth.NewRedisRevocationStore(redisClient)
+}
+
+// provideJWKSVerifier builds the optional federated JWKS verifier (Zitadel
+// plus any configured extra issuers) and the cleanup that stops its
+// background refresher. Returns a nil verifier and a no-op cleanup when no
+// issuer is configured at all, so auth falls back to legacy JWT.
+func provideJWKSVerifier(cfg config.ZitadelConfig, revocation auth.RevocationStore) (*auth.FederatedVerifier, func(), error) {
+	if cfg.Issuer == "" && len(cfg.ExtraIssuers) == 0 {
+		return nil, func() {}, nil
+	}
+	jwksVerifier, err := auth.NewFederatedVerifier(&cfg, revocation)
+	if err != nil {
+		log.Warn(context.Background(), "JWKS verifier not initialized", "err", err)
+		return nil, func() {}, nil
+	}
+	return jwksVerifier, func() { jwksVerifier.Close() }, nil
+}
+
+// provideSessionManager builds the session.Manager backing
+// POST /api/auth/refresh. Returns nil when either the issuer or the
+// client-assertion signing key isn't configured, so Refresh responds with
+// 503 instead of the whole graph failing to wire.
+func provideSessionManager(cfg config.ZitadelConfig) *session.Manager {
+	if cfg.Issuer == "" || cfg.ClientAssertionKey == "" {
+		return nil
+	}
+	mgr, err := session.NewManager(cfg.Issuer, cfg.ClientAssertionKey, cfg.ClientAssertionKeyID)
+	if err != nil {
+		log.Warn(context.Background(), "session manager not initialized", "err", err)
+		return nil
+	}
+	return mgr
+}
+
+// provideAPIAuthMiddleware picks the request-auth strategy: gateway
+// header-based auth behind Traefik, or one of the JWKS/legacy-HMAC
+// combinations for direct mode. Revocation isn't wired for gateway mode:
+// Traefik's ForwardAuth call already goes through AuthHandler.Verify, which
+// checks the same store directly.
+func provideAPIAuthMiddleware(cfg *config.Config, jwksVerifier *auth.FederatedVerifier, revocation auth.RevocationStore) fiber.Handler {
+	if cfg.Gateway.Enabled {
+		log.Info(context.Background(), "gateway mode enabled — using header-based auth")
+		return middleware.GatewayAuthMiddleware()
+	}
+
+	var authMiddleware *middleware.AuthMiddleware
+	switch {
+	case jwksVerifier != nil && cfg.JWT.Secret != "":
+		authMiddleware = middleware.NewAuthMiddlewareWithFallback(jwksVerifier, cfg.JWT.Secret)
+	case jwksVerifier != nil:
+		authMiddleware = middleware.NewAuthMiddleware(jwksVerifier)
+	default:
+		authMiddleware = middleware.NewLegacyAuthMiddleware(cfg.JWT.Secret)
+	}
+	return authMiddleware.WithRevocationStore(revocation).Authenticate()
+}
+
+// handlers bundles every wired handler so the two route-assembly providers
+// below don't need a dozen positional parameters apiece.
+type handlers struct {
+	lyrics   *handler.LyricsHandler
+	render   *handler.RenderHandler
+	master   *handler.MasterHandler
+	export   *handler.ExportHandler
+	upload   *handler.UploadHandler
+	jobs     *handler.JobsHandler
+	admin    *handler.AdminHandler
+	auth     *handler.AuthHandler
+	internal *handler.InternalJobsHandler
+	subsonic *subsonic.Handler
+}
+
+// provideFiberApp assembles the production Fiber app: global middleware,
+// health/swagger/metrics, and every route group, wired against the real
+// clients so /health can report what's actually configured.
+func provideFiberApp(cfg *config.Config, h handlers, hub *ws.Hub, groqClient *client.GroqClient, sunoClient *client.SunoClient, audioClient *client.AudioClient, storageClient client.StorageClient, jwksVerifier *auth.FederatedVerifier, apiAuthMiddleware fiber.Handler, rateLimiter *middleware.RateLimiter, redisClient *redis.Client) *fiber.App {
+	fiberApp := fiber.New(fiber.Config{
+		ErrorHandler: customErrorHandler,
+		BodyLimit:    50 * 1024 * 1024, // 50MB
+	})
+
+	fiberApp.Use(recover.New())
+	fiberApp.Use(middleware.RequestContext())
+	fiberApp.Use(middleware.Metrics())
+	isDebug := strings.EqualFold(cfg.Server.LogLevel, "debug")
+	logFormat := "[${time}] ${status} - ${latency} ${method} ${path}\n"
+	if isDebug {
+		logFormat = "[${time}] ${status} - ${latency} ${method} ${path} ${queryParams} ${body} ${reqHeaders}\n"
+		log.Debug(context.Background(), "debug logging enabled")
+	}
+	fiberApp.Use(logger.New(logger.Config{Format: logFormat}))
+	fiberApp.Use(cors.New(cors.Config{
+		AllowOrigins: cfg.Server.AllowedOrigins,
+		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
+	}))
+
+	fiberApp.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"timestamp": time.Now().Unix()})
+	})
+	fiberApp.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status": "ok",
+			"services": fiber.Map{
+				"groq":    groqClient.IsConfigured(),
+				"suno":    sunoClient.IsConfigured(),
+				"storage": storageClient != nil,
+				"audio":   audioClient.IsConfigured(),
+				"auth":    jwksVerifier != nil || cfg.JWT.Secret != "",
+			},
+		})
+	})
+	fiberApp.Get("/swagger/*", fiberSwagger.HandlerDefault)
+	fiberApp.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+	fiberApp.Get("/readyz", provideReadinessHandler(redisClient, audioClient, storageClient != nil))
+	fiberApp.Get("/auth/verify", h.auth.Verify)
+
+	// /files is only mounted for the "fs" storage provider: S3-compatible
+	// backends serve objects directly from the bucket, so there's nothing
+	// for the API process to proxy. GET is a static mount over LocalRoot;
+	// PUT accepts the body FilesystemClient.PresignPutURL pointed the
+	// client at. Both are guarded by the same HMAC signature scheme since
+	// there's no S3 SigV4 standing in for auth here.
+	if cfg.Storage.Provider == "fs" && cfg.Storage.LocalRoot != "" {
+		filesAuth := middleware.VerifySignedFileURL(cfg.Storage.SigningSecret)
+		fiberApp.Put("/files/*", filesAuth, func(c *fiber.Ctx) error {
+			key := strings.TrimPrefix(c.Path(), "/files/")
+			if storageClient == nil {
+				return response.ServiceError(c, "storage not configured")
+			}
+			if _, err := storageClient.PutObjectStream(c.Context(), key, bytes.NewReader(c.Body()), c.Get("Content-Type")); err != nil {
+				return response.ServiceError(c, "failed to store file")
+			}
+			return c.SendStatus(fiber.StatusOK)
+		})
+		fiberApp.Static("/files", cfg.Storage.LocalRoot)
+	}
+
+	runnerAuthMiddleware := middleware.RunnerAuthMiddleware(cfg.Runner.SharedSecret)
+	internalJobs := fiberApp.Group("/internal/jobs", runnerAuthMiddleware)
+	internalJobs.Post("/:jobId/logs", h.internal.Logs)
+	internalJobs.Post("/:jobId/progress", h.internal.Progress)
+	internalJobs.Post("/:jobId/complete", h.internal.Complete)
+	internalJobs.Post("/:jobId/fail", h.internal.Fail)
+
+	registerAPIRoutes(fiberApp.Group("/api", apiAuthMiddleware), h, rateLimiter, redisClient)
+	registerSubsonicRoutes(fiberApp.Group("/rest", subsonic.Authenticate(cfg.JWT.Secret)), h)
+
+	fiberApp.Use("/ws", func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			return c.Next()
+		}
+		return fiber.ErrUpgradeRequired
+	})
+	fiberApp.Get("/ws/jobs/:jobId", websocket.New(func(c *websocket.Conn) {
+		hub.HandleConnection(c, c.Params("jobId"))
+	}))
+
+	return fiberApp
+}
+
+// provideTestFiberApp assembles the minimal Fiber app e2e tests run
+// against: the same route groups as production, minus swagger/metrics and
+// the debug-log middleware tests have no use for, with legacy-HMAC-only
+// auth and rate limits high enough that tests never trip them. CORS is
+// included (unlike swagger/metrics) so e2e tests can exercise
+// cfg.Server.AllowedOrigins the same way production enforces it.
+func provideTestFiberApp(cfg *config.Config, h handlers, hub *ws.Hub, groqClient *client.GroqClient, sunoClient *client.SunoClient, audioClient *client.AudioClient, rateLimiter *middleware.RateLimiter, revocation auth.RevocationStore, redisClient *redis.Client) *fiber.App {
+	fiberApp := fiber.New(fiber.Config{BodyLimit: 50 * 1024 * 1024})
+	fiberApp.Use(middleware.RequestContext())
+	fiberApp.Use(cors.New(cors.Config{
+		AllowOrigins: cfg.Server.AllowedOrigins,
+		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
+	}))
+
+	fiberApp.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"timestamp": time.Now().Unix()})
+	})
+	fiberApp.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status": "ok",
+			"services": fiber.Map{
+				"groq":    groqClient.IsConfigured(),
+				"suno":    sunoClient.IsConfigured(),
+				"storage": false,
+				"audio":   audioClient.IsConfigured(),
+				"auth":    true,
+			},
+		})
+	})
+	fiberApp.Get("/readyz", provideReadinessHandler(redisClient, audioClient, false))
+	fiberApp.Get("/auth/verify", h.auth.Verify)
+
+	runnerAuthMiddleware := middleware.RunnerAuthMiddleware(cfg.Runner.SharedSecret)
+	internalJobs := fiberApp.Group("/internal/jobs", runnerAuthMiddleware)
+	internalJobs.Post("/:jobId/logs", h.internal.Logs)
+	internalJobs.Post("/:jobId/progress", h.internal.Progress)
+	internalJobs.Post("/:jobId/complete", h.internal.Complete)
+	internalJobs.Post("/:jobId/fail", h.internal.Fail)
+
+	authMiddleware := middleware.NewLegacyAuthMiddleware(cfg.JWT.Secret).WithRevocationStore(revocation)
+	registerAPIRoutes(fiberApp.Group("/api", authMiddleware.Authenticate()), h, rateLimiter, redisClient)
+	registerSubsonicRoutes(fiberApp.Group("/rest", subsonic.Authenticate(cfg.JWT.Secret)), h)
+
+	return fiberApp
+}
+
+// provideReadinessHandler returns the /readyz handler: unlike /health (which
+// reports what's configured), this reports what's actually reachable right
+// now, so a load balancer or orchestrator can stop sending traffic to an
+// instance that's up but can't reach its dependencies. storageConfigured is
+// passed in rather than re-derived here since neither provideFiberApp nor
+// provideTestFiberApp has a way to probe object storage more deeply than
+// "was a client constructed" without adding a Stat-style call to
+// client.StorageClient.
+func provideReadinessHandler(redisClient *redis.Client, audioClient *client.AudioClient, storageConfigured bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		checks := fiber.Map{}
+		ready := true
+
+		if err := redisClient.Ping(c.Context()).Err(); err != nil {
+			checks["redis"] = err.Error()
+			ready = false
+		} else {
+			checks["redis"] = "ok"
+		}
+
+		if err := audioClient.HealthCheck(c.Context()); err != nil {
+			checks["audio"] = err.Error()
+			ready = false
+		} else {
+			checks["audio"] = "ok"
+		}
+
+		if storageConfigured {
+			checks["storage"] = "ok"
+		} else {
+			checks["storage"] = "not configured"
+		}
+
+		if !ready {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "not ready", "checks": checks})
+		}
+		return c.JSON(fiber.Map{"status": "ready", "checks": checks})
+	}
+}
+
+// registerAPIRoutes mounts the lyrics/render/master/export/upload/jobs route
+// groups shared by the production and test Fiber apps under api.
+func registerAPIRoutes(api fiber.Router, h handlers, rateLimiter *middleware.RateLimiter, redisClient *redis.Client) {
+	idempotency := middleware.IdempotencyKeyHeader(middleware.NewRedisIdempotencyStore(redisClient))
+	lyrics := api.Group("/lyrics", rateLimiter.LyricsLimit())
+	lyrics.Post("/generate", h.lyrics.Generate)
+	lyrics.Post("/rewrite", h.lyrics.Rewrite)
+	lyrics.Post("/export", h.lyrics.Export)
+	lyrics.Delete("/cache", middleware.RequireRole("admin", "owner"), h.lyrics.PurgeCache)
+
+	render := api.Group("/render")
+	render.Post("/start", rateLimiter.RenderLimit(), middleware.RequireScope("render:write"), h.render.Start)
+	render.Get("/status/:jobId", h.render.Status)
+	render.Get("/result/:jobId", h.render.Result)
+	render.Post("/cancel/:jobId", middleware.RequireRole("admin", "owner"), h.render.Cancel)
+	render.Get("/events/:jobId", h.render.Events)
+
+	master := api.Group("/master", rateLimiter.MasterLimit())
+	master.Post("/preview", h.master.Preview)
+	master.Post("/final", idempotency, h.master.Final)
+	master.Post("/batch", h.master.Batch)
+	master.Get("/status/:jobId", h.master.Status)
+	master.Get("/status/:jobId/stream", h.master.Events) // alias for GET /events/:jobId; see MasterHandler.Events doc comment
+	master.Get("/result/:jobId", h.master.Result)
+	master.Get("/events/:jobId", h.master.Events)
+	master.Post("/:jobId/webhook/replay", middleware.RequireRole("admin", "owner"), h.master.ReplayWebhook)
+
+	export := api.Group("/export", rateLimiter.ExportLimit())
+	export.Post("/mp3", h.export.MP3)
+	export.Post("/wav", h.export.WAV)
+	export.Post("/alac", h.export.ALAC)
+	export.Post("/flac", h.export.FLAC)
+	export.Post("/opus", h.export.Opus)
+	export.Post("/ogg", h.export.Ogg)
+	export.Post("/atmos", h.export.Atmos)
+	export.Post("/stems", h.export.Stems)
+	export.Get("/status/:jobId", h.export.Status)
+	export.Get("/result/:jobId", h.export.Result)
+
+	upload := api.Group("/upload", rateLimiter.UploadLimit())
+	upload.Post("/vocal", h.upload.Vocal)
+	upload.Post("/vocal/presign", h.upload.PresignVocal)
+	upload.Post("/vocal/complete/:id", h.upload.CompletePresignedVocal)
+	upload.Post("/vocal/init", h.upload.InitChunkedVocal)
+	upload.Head("/vocal/:uploadId", h.upload.HeadChunkedVocal)
+	upload.Patch("/vocal/:uploadId", h.upload.PatchChunkedVocal)
+	upload.Post("/vocal/:uploadId/complete", h.upload.CompleteChunkedVocal)
+	upload.Delete("/vocal/:takeId", h.upload.DeleteVocal)
+	upload.Post("/vocals/batch", h.upload.VocalsBatch)
+
+	jobs := api.Group("/jobs")
+	jobs.Get("/:jobId", h.jobs.Status)
+	jobs.Get("/:jobId/wait", h.jobs.Wait)
+	jobs.Post("/:jobId/archive", h.jobs.Archive)
+	jobs.Delete("/:jobId", middleware.RequireRole("admin", "owner"), h.jobs.Cancel)
+
+	admin := api.Group("/admin", middleware.RequireRole("admin"))
+	admin.Get("/queues", h.admin.Queues)
+	admin.Get("/queues/:name/tasks", h.admin.Tasks)
+	admin.Post("/queues/:name/tasks/:id/run", h.admin.RunTask)
+	admin.Post("/queues/:name/tasks/:id/archive", h.admin.ArchiveTask)
+	admin.Delete("/queues/:name/tasks/:id", h.admin.DeleteTask)
+
+	authGroup := api.Group("/auth")
+	authGroup.Post("/revoke", h.auth.Revoke)
+	authGroup.Post("/refresh", h.auth.Refresh)
+}
+
+// registerSubsonicRoutes mounts the Subsonic REST subset under rest
+// (already wrapped by subsonic.Authenticate, since Subsonic clients pass
+// credentials as query params rather than an Authorization header). Every
+// endpoint accepts both GET and POST, matching real Subsonic servers, since
+// clients pick whichever verb suits their request size.
+func registerSubsonicRoutes(rest fiber.Router, h handlers) {
+	rest.All("/ping.view", h.subsonic.Ping)
+	rest.All("/getLicense.view", h.subsonic.GetLicense)
+	rest.All("/getMusicFolders.view", h.subsonic.GetMusicFolders)
+	rest.All("/getIndexes.view", h.subsonic.GetIndexes)
+	rest.All("/getAlbumList2.view", h.subsonic.GetAlbumList2)
+	rest.All("/getAlbum.view", h.subsonic.GetAlbum)
+	rest.All("/getSong.view", h.subsonic.GetSong)
+	rest.All("/stream.view", h.subsonic.Stream)
+	rest.All("/download.view", h.subsonic.Download)
+	rest.All("/getCoverArt.view", h.subsonic.GetCoverArt)
+	rest.All("/getLyrics.view", h.subsonic.GetLyrics)
+}
+
+// newApp bundles the already fully-routed Fiber app with the other
+// components cmd/server needs for startup and graceful shutdown.
+func newApp(fiberApp *fiber.App, hub *ws.Hub, archiver *service.Archiver) *App {
+	return &App{Fiber: fiberApp, Hub: hub, Archiver: archiver}
+}
+
+// customErrorHandler is Fiber's catch-all for errors that reach it without
+// a handler having already written a response.* envelope itself (route-not-
+// found, a panic recovered by recover.New(), a handler that returns a bare
+// error). A *errs.Error unwraps to its own code/status/message the same way
+// response.FromError does for handlers that call it directly; anything else
+// still collapses to the generic SERVICE_ERROR envelope it always has.
+func customErrorHandler(c *fiber.Ctx, err error) error {
+	var e *errs.Error
+	if errors.As(err, &e) {
+		return response.Error(c, e.Status, e.Code, e.Message, nil)
+	}
+
+	code := fiber.StatusInternalServerError
+	message := "Internal Server Error"
+
+	if fe, ok := err.(*fiber.Error); ok {
+		code = fe.Code
+		message = fe.Message
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"error": fiber.Map{
+			"code":    "SERVICE_ERROR",
+			"message": message,
+		},
+	})
+}
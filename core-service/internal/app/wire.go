@@ -0,0 +1,86 @@
+//go:build wireinject
+// +build wireinject
+
+package app
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/config"
+	"github.com/makeasinger/api/internal/handler"
+	"github.com/makeasinger/api/internal/middleware"
+	"github.com/makeasinger/api/internal/service"
+	"github.com/makeasinger/api/internal/subsonic"
+)
+
+// configSet derives the startup *config.Config snapshot from the *config.Live
+// Load returns, then pulls out the sub-structs individual providers need, so
+// client.ProviderSet etc. can keep depending on their own narrow config types
+// instead of the whole Config. middleware.NewRateLimiter depends on
+// *config.Live directly instead, so it keeps seeing config reloads.
+var configSet = wire.NewSet(
+	provideConfig,
+	wire.FieldsOf(new(*config.Config), "Groq", "Suno", "Audio", "Redis", "Storage", "Zitadel"),
+)
+
+func provideValidator() *validator.Validate {
+	return validator.New()
+}
+
+// handlersSet bundles the wired handlers into a handlers value so
+// provideFiberApp/provideTestFiberApp don't need a dozen parameters apiece.
+var handlersSet = wire.NewSet(
+	wire.Struct(new(handlers), "lyrics", "render", "master", "export", "upload", "jobs", "admin", "auth", "internal", "subsonic"),
+)
+
+// InitializeApp wires every client, service, and handler from cfgLive and
+// returns the assembled Fiber app plus a cleanup func that closes whatever
+// connections it opened (Redis, Asynq, the JWKS verifier). Regenerate
+// wire_gen.go with `wire ./internal/app` after changing this file or any
+// ProviderSet it pulls in.
+func InitializeApp(cfgLive *config.Live) (*App, func(), error) {
+	wire.Build(
+		configSet,
+		provideValidator,
+		client.ProviderSet,
+		service.ProviderSet,
+		handler.ProviderSet,
+		subsonic.ProviderSet,
+		handlersSet,
+		middleware.ProviderSet,
+		provideStorageClient,
+		provideAsynqInspector,
+		provideRevocationStore,
+		provideJWKSVerifier,
+		provideSessionManager,
+		provideAPIAuthMiddleware,
+		provideFiberApp,
+		newApp,
+	)
+	return nil, nil, nil
+}
+
+// InitializeTestApp wires the same graph as InitializeApp but against
+// unconfigured external clients (empty config sub-structs), so every
+// service falls back to its mock path. This is what e2e tests call instead
+// of hand-rolling their own setupApp plumbing.
+func InitializeTestApp(cfgLive *config.Live) (*App, func(), error) {
+	wire.Build(
+		configSet,
+		provideValidator,
+		client.ProviderSet,
+		service.ProviderSet,
+		handler.ProviderSet,
+		subsonic.ProviderSet,
+		handlersSet,
+		middleware.ProviderSet,
+		provideAsynqInspector,
+		provideRevocationStore,
+		provideSessionManager,
+		provideTestFiberApp,
+		newApp,
+	)
+	return nil, nil, nil
+}
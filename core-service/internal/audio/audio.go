@@ -0,0 +1,24 @@
+// Package audio implements a native Go mastering chain — EQ, compression,
+// ITU-R BS.1770-4 loudness measurement, and true-peak limiting — that
+// MasterWorker runs when no external audio microservice is configured,
+// along with the ffmpeg-pipe PCM decode/encode it operates on and the
+// shared linear/dB helpers the stages use.
+package audio
+
+import "math"
+
+// SampleRate is the internal working sample rate for the mastering chain.
+// Every stem is resampled to it on decode and the final master is encoded
+// at it; the loudness meter's K-weighting coefficients are specific to it.
+const SampleRate = 48000
+
+func linearToDb(v float64) float64 {
+	if v <= 1e-10 {
+		return -200
+	}
+	return 20 * math.Log10(v)
+}
+
+func dbToLinearG(db float64) float64 {
+	return math.Pow(10, db/20)
+}
@@ -0,0 +1,226 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// ProfileTarget is the integrated-loudness target and limiter ceiling a
+// mastering profile aims for.
+type ProfileTarget struct {
+	TargetLUFS float64
+	CeilingDb  float64
+}
+
+// profileTargets maps each MasterProfile to the loudness convention of its
+// playback context: "clean" aims at streaming platforms' normalization
+// target (-14 LUFS), "warm" sits a little hotter (-11 LUFS), and "loud" at
+// club/DJ systems that expect a hotter master (-9 LUFS).
+var profileTargets = map[string]ProfileTarget{
+	"clean": {TargetLUFS: -14, CeilingDb: -1.0},
+	"warm":  {TargetLUFS: -11, CeilingDb: -1.0},
+	"loud":  {TargetLUFS: -9, CeilingDb: -1.0},
+}
+
+// TargetFor returns the loudness target for profile, falling back to
+// "clean" for an unrecognized value.
+func TargetFor(profile string) ProfileTarget {
+	if t, ok := profileTargets[profile]; ok {
+		return t
+	}
+	return profileTargets["clean"]
+}
+
+// maxGainIterations bounds the gain-adjust loop so a pathological input
+// (e.g. near-silence) can't spin forever chasing the target LUFS.
+const maxGainIterations = 6
+
+// lufsToleranceLU is how close to TargetLUFS the gain-adjust loop must land
+// before it stops.
+const lufsToleranceLU = 0.5
+
+// StemInput is one decoded source to mix into the master, carrying the same
+// gain/mute/solo semantics as client.MixChannel.
+type StemInput struct {
+	Path string
+	Gain float64 // linear
+	Mute bool
+	Solo bool
+}
+
+// Result is the measured output of running the mastering chain.
+type Result struct {
+	OutPath  string
+	PeakDb   float64
+	LUFS     float64
+	Duration float64 // seconds
+}
+
+// ProgressFunc reports a stage of Run as it completes, with percent in
+// [0,100]. Passing nil is fine — Run just skips reporting.
+type ProgressFunc func(percent int, step string)
+
+// Run decodes every stem to mono float32 PCM, sums them to a center-panned
+// stereo bed (this mix model has no per-channel pan), applies the
+// profile's EQ and compressor curve, iterates output gain to land within
+// lufsToleranceLU of the profile's target loudness, limits true peak to the
+// profile's ceiling, and encodes the result to a 24-bit WAV at outPath.
+// onProgress, if non-nil, is called as each stage completes so the caller
+// can report real percentages instead of fixed guesses.
+func Run(ctx context.Context, stems []StemInput, profile, outPath string, onProgress ProgressFunc) (*Result, error) {
+	report := func(percent int, step string) {
+		if onProgress != nil {
+			onProgress(percent, step)
+		}
+	}
+
+	target := TargetFor(profile)
+
+	mix, err := mixDown(ctx, stems)
+	if err != nil {
+		return nil, err
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("no stems survived mute/solo filtering")
+	}
+	report(40, "Mixed stems down")
+
+	l := make([]float32, len(mix))
+	r := make([]float32, len(mix))
+	copy(l, mix)
+	copy(r, mix)
+
+	applyEQ(l, r, profile)
+	report(50, "Applied EQ")
+	applyCompression(l, r, profile)
+	report(60, "Applied compression")
+
+	for i := 0; i < maxGainIterations; i++ {
+		lufs, err := MeasureLUFS(l, r, SampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure loudness: %w", err)
+		}
+		diff := target.TargetLUFS - lufs
+		if math.Abs(diff) <= lufsToleranceLU {
+			break
+		}
+		gain := float32(dbToLinearG(diff))
+		for j := range l {
+			l[j] *= gain
+			r[j] *= gain
+		}
+		report(60+(i+1)*3, "Adjusting loudness")
+	}
+
+	limiter := NewTruePeakLimiter(SampleRate, target.CeilingDb, 5, 50)
+	peakDb := limiter.Process(l, r)
+	report(80, "Applied true-peak limiter")
+
+	finalLUFS, err := MeasureLUFS(l, r, SampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure final loudness: %w", err)
+	}
+
+	if err := encodeStereoWAV(ctx, l, r, SampleRate, outPath); err != nil {
+		return nil, fmt.Errorf("failed to encode master: %w", err)
+	}
+	report(90, "Encoded master")
+
+	return &Result{OutPath: outPath, PeakDb: peakDb, LUFS: finalLUFS, Duration: float64(len(mix)) / float64(SampleRate)}, nil
+}
+
+// mixDown decodes every non-muted (or, if any stem is soloed, every soloed)
+// stem and sums them into a single mono bed at its channel gain.
+func mixDown(ctx context.Context, stems []StemInput) ([]float32, error) {
+	anySolo := false
+	for _, s := range stems {
+		if s.Solo {
+			anySolo = true
+			break
+		}
+	}
+
+	var mix []float32
+	for _, s := range stems {
+		if s.Mute && !s.Solo {
+			continue
+		}
+		if anySolo && !s.Solo {
+			continue
+		}
+
+		samples, err := decodeMonoPCM(ctx, s.Path, SampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode stem: %w", err)
+		}
+		if len(samples) > len(mix) {
+			grown := make([]float32, len(samples))
+			copy(grown, mix)
+			mix = grown
+		}
+		gain := float32(s.Gain)
+		for i, v := range samples {
+			mix[i] += v * gain
+		}
+	}
+	return mix, nil
+}
+
+type eqStage struct {
+	kind   BiquadKind
+	freq   float64
+	gainDb float64
+	q      float64
+}
+
+// eqStagesFor returns the profile's tonal curve: "warm" leans on a low-mid
+// lift and a gentle top-end roll-off, "loud" pushes presence and air for
+// cut-through on club systems, "clean" (and any unrecognized profile) stays
+// close to flat with just a tightening low cut and a touch of air.
+func eqStagesFor(profile string) []eqStage {
+	switch profile {
+	case "warm":
+		return []eqStage{
+			{LowShelf, 200, 2.0, 0.707},
+			{HighShelf, 8000, -1.0, 0.707},
+		}
+	case "loud":
+		return []eqStage{
+			{Peaking, 3000, 2.0, 1.0},
+			{HighShelf, 10000, 1.5, 0.707},
+		}
+	default:
+		return []eqStage{
+			{LowShelf, 80, -1.0, 0.707},
+			{HighShelf, 10000, 1.0, 0.707},
+		}
+	}
+}
+
+func applyEQ(l, r []float32, profile string) {
+	for _, stage := range eqStagesFor(profile) {
+		NewBiquad(stage.kind, stage.freq, SampleRate, stage.gainDb, stage.q).ProcessBuffer(l)
+		NewBiquad(stage.kind, stage.freq, SampleRate, stage.gainDb, stage.q).ProcessBuffer(r)
+	}
+}
+
+// compressorParamsFor returns (thresholdDb, ratio, attackMs, releaseMs).
+// "loud" compresses hardest and fastest for a denser, club-ready master;
+// "warm" starts highest (-18 dB) with the slowest attack/release and a
+// 2.5:1 ratio; "clean" uses the lightest ratio (2:1) with medium timing.
+func compressorParamsFor(profile string) (thresholdDb, ratio, attackMs, releaseMs float64) {
+	switch profile {
+	case "warm":
+		return -18, 2.5, 15, 150
+	case "loud":
+		return -22, 4.0, 5, 80
+	default:
+		return -20, 2.0, 10, 120
+	}
+}
+
+func applyCompression(l, r []float32, profile string) {
+	threshold, ratio, attack, release := compressorParamsFor(profile)
+	NewCompressor(SampleRate, threshold, ratio, attack, release).ProcessStereo(l, r)
+}
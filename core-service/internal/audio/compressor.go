@@ -0,0 +1,55 @@
+package audio
+
+import "math"
+
+// Compressor is a feed-forward, peak-detecting dynamics compressor with
+// independent attack/release ballistics applied to the gain-reduction
+// envelope rather than to the input level.
+type Compressor struct {
+	thresholdDb float64
+	ratio       float64
+	attackMs    float64
+	releaseMs   float64
+	sampleRate  float64
+
+	envDb float64 // current smoothed gain reduction, in dB (>= 0)
+}
+
+// NewCompressor builds a compressor. ratio is expressed as N (e.g. 4 for a
+// 4:1 ratio); attackMs/releaseMs are the envelope time constants.
+func NewCompressor(sampleRate, thresholdDb, ratio, attackMs, releaseMs float64) *Compressor {
+	return &Compressor{
+		thresholdDb: thresholdDb,
+		ratio:       ratio,
+		attackMs:    attackMs,
+		releaseMs:   releaseMs,
+		sampleRate:  sampleRate,
+	}
+}
+
+// ProcessStereo compresses l and r in place using a single gain-reduction
+// envelope shared across both channels, so the stereo image isn't skewed.
+func (c *Compressor) ProcessStereo(l, r []float32) {
+	attackCoeff := math.Exp(-1.0 / (c.attackMs / 1000 * c.sampleRate))
+	releaseCoeff := math.Exp(-1.0 / (c.releaseMs / 1000 * c.sampleRate))
+
+	for i := range l {
+		peak := math.Max(math.Abs(float64(l[i])), math.Abs(float64(r[i])))
+		levelDb := linearToDb(peak)
+
+		var target float64
+		if over := levelDb - c.thresholdDb; over > 0 {
+			target = over - over/c.ratio
+		}
+
+		if target > c.envDb {
+			c.envDb = attackCoeff*c.envDb + (1-attackCoeff)*target
+		} else {
+			c.envDb = releaseCoeff*c.envDb + (1-releaseCoeff)*target
+		}
+
+		gain := dbToLinearG(-c.envDb)
+		l[i] = float32(float64(l[i]) * gain)
+		r[i] = float32(float64(r[i]) * gain)
+	}
+}
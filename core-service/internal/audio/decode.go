@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// decodeMonoPCM shells out to ffmpeg to decode path to raw 32-bit float PCM
+// at sampleRate, down-mixed to mono. Using ffmpeg for decode keeps this
+// package free of format-specific parsing (the stems this chain receives
+// can be WAV, FLAC, or whatever the upload pipeline accepted) while the DSP
+// stages that follow are plain Go.
+func decodeMonoPCM(ctx context.Context, path string, sampleRate int) ([]float32, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-i", path, "-f", "f32le", "-ar", strconv.Itoa(sampleRate), "-ac", "1", "pipe:1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg decode failed: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return bytesToFloat32(out), nil
+}
+
+// encodeStereoWAV pipes interleaved stereo float32 PCM through ffmpeg and
+// writes a 24-bit PCM WAV to outPath.
+func encodeStereoWAV(ctx context.Context, l, r []float32, sampleRate int, outPath string) error {
+	interleaved := make([]float32, len(l)*2)
+	for i := range l {
+		interleaved[i*2] = l[i]
+		interleaved[i*2+1] = r[i]
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y",
+		"-f", "f32le", "-ar", strconv.Itoa(sampleRate), "-ac", "2", "-i", "pipe:0",
+		"-codec:a", "pcm_s24le", outPath)
+	cmd.Stdin = bytes.NewReader(float32ToBytes(interleaved))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg encode failed: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func bytesToFloat32(buf []byte) []float32 {
+	out := make([]float32, len(buf)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return out
+}
+
+func float32ToBytes(buf []float32) []byte {
+	out := make([]byte, len(buf)*4)
+	for i, v := range buf {
+		binary.LittleEndian.PutUint32(out[i*4:], math.Float32bits(v))
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package audio
+
+import "math"
+
+// BiquadKind selects an RBJ Audio-EQ-Cookbook filter shape.
+type BiquadKind int
+
+const (
+	LowShelf BiquadKind = iota
+	HighShelf
+	Peaking
+)
+
+// Biquad is a Direct Form II transposed biquad, processed one sample at a
+// time so a chain of stages (EQ bands, K-weighting) can each keep their own
+// running state.
+type Biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+// NewBiquad builds a biquad from RBJ Audio-EQ-Cookbook coefficients for the
+// given shape, center/corner frequency (Hz), shelf/peak gain (dB, ignored
+// for shapes that don't use it), and Q.
+func NewBiquad(kind BiquadKind, freq, sampleRate, gainDb, q float64) *Biquad {
+	a := math.Pow(10, gainDb/40)
+	w0 := 2 * math.Pi * freq / sampleRate
+	cosw0 := math.Cos(w0)
+	sinw0 := math.Sin(w0)
+	alpha := sinw0 / (2 * q)
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch kind {
+	case LowShelf:
+		sqrtA := math.Sqrt(a)
+		b0 = a * ((a + 1) - (a-1)*cosw0 + 2*sqrtA*alpha)
+		b1 = 2 * a * ((a - 1) - (a+1)*cosw0)
+		b2 = a * ((a + 1) - (a-1)*cosw0 - 2*sqrtA*alpha)
+		a0 = (a + 1) + (a-1)*cosw0 + 2*sqrtA*alpha
+		a1 = -2 * ((a - 1) + (a+1)*cosw0)
+		a2 = (a + 1) + (a-1)*cosw0 - 2*sqrtA*alpha
+	case HighShelf:
+		sqrtA := math.Sqrt(a)
+		b0 = a * ((a + 1) + (a-1)*cosw0 + 2*sqrtA*alpha)
+		b1 = -2 * a * ((a - 1) + (a+1)*cosw0)
+		b2 = a * ((a + 1) + (a-1)*cosw0 - 2*sqrtA*alpha)
+		a0 = (a + 1) - (a-1)*cosw0 + 2*sqrtA*alpha
+		a1 = 2 * ((a - 1) - (a+1)*cosw0)
+		a2 = (a + 1) - (a-1)*cosw0 - 2*sqrtA*alpha
+	default: // Peaking
+		b0 = 1 + alpha*a
+		b1 = -2 * cosw0
+		b2 = 1 - alpha*a
+		a0 = 1 + alpha/a
+		a1 = -2 * cosw0
+		a2 = 1 - alpha/a
+	}
+
+	return newBiquadCoeffs(b0/a0, b1/a0, b2/a0, a1/a0, a2/a0)
+}
+
+func newBiquadCoeffs(b0, b1, b2, a1, a2 float64) *Biquad {
+	return &Biquad{b0: b0, b1: b1, b2: b2, a1: a1, a2: a2}
+}
+
+// Process filters a single sample.
+func (b *Biquad) Process(x float32) float32 {
+	in := float64(x)
+	out := b.b0*in + b.z1
+	b.z1 = b.b1*in - b.a1*out + b.z2
+	b.z2 = b.b2*in - b.a2*out
+	return float32(out)
+}
+
+// ProcessBuffer filters buf in place.
+func (b *Biquad) ProcessBuffer(buf []float32) {
+	for i, s := range buf {
+		buf[i] = b.Process(s)
+	}
+}
@@ -0,0 +1,132 @@
+package audio
+
+import "math"
+
+// oversampleFactor is how much the true-peak limiter oversamples before
+// measuring inter-sample peaks, per BS.1770's true-peak recommendation.
+const oversampleFactor = 4
+
+// TruePeakLimiter keeps the 4x-oversampled true peak of a stereo signal at
+// or below CeilingDb using a lookahead gain envelope, so inter-sample peaks
+// ffmpeg's encoder would otherwise clip stay under the ceiling.
+type TruePeakLimiter struct {
+	ceilingDb   float64
+	lookaheadMs float64
+	releaseMs   float64
+	sampleRate  float64
+}
+
+// NewTruePeakLimiter builds a limiter targeting ceilingDb dBTP with the
+// given lookahead and release times in milliseconds.
+func NewTruePeakLimiter(sampleRate, ceilingDb, lookaheadMs, releaseMs float64) *TruePeakLimiter {
+	return &TruePeakLimiter{ceilingDb: ceilingDb, lookaheadMs: lookaheadMs, releaseMs: releaseMs, sampleRate: sampleRate}
+}
+
+// Process attenuates l and r in place so their oversampled true peak does
+// not exceed the limiter's ceiling, and returns the resulting true peak in
+// dBTP.
+func (lim *TruePeakLimiter) Process(l, r []float32) float64 {
+	n := len(l)
+	if n == 0 {
+		return linearToDb(0)
+	}
+
+	ceiling := dbToLinearG(lim.ceilingDb)
+	lookahead := int(lim.lookaheadMs / 1000 * lim.sampleRate)
+
+	ol := oversample(l, oversampleFactor)
+	or_ := oversample(r, oversampleFactor)
+
+	// Per-sample gain required so the oversampled peak around this sample
+	// doesn't exceed the ceiling.
+	gains := make([]float64, n)
+	for i := range gains {
+		gains[i] = 1.0
+	}
+	for i := 0; i < n; i++ {
+		base := i * oversampleFactor
+		end := base + oversampleFactor
+		if end > len(ol) {
+			end = len(ol)
+		}
+		peak := 0.0
+		for j := base; j < end; j++ {
+			if v := math.Abs(float64(ol[j])); v > peak {
+				peak = v
+			}
+			if v := math.Abs(float64(or_[j])); v > peak {
+				peak = v
+			}
+		}
+		if peak > ceiling {
+			gains[i] = ceiling / peak
+		}
+	}
+
+	// Propagate reductions backward across the lookahead window, so
+	// attenuation begins before the peak arrives instead of clipping it.
+	for i := n - 1; i >= 0; i-- {
+		end := i + lookahead
+		if end > n {
+			end = n
+		}
+		min := gains[i]
+		for j := i; j < end; j++ {
+			if gains[j] < min {
+				min = gains[j]
+			}
+		}
+		gains[i] = min
+	}
+
+	releaseCoeff := math.Exp(-1.0 / (lim.releaseMs / 1000 * lim.sampleRate))
+	smoothed := 1.0
+	for i := 0; i < n; i++ {
+		if gains[i] < smoothed {
+			smoothed = gains[i]
+		} else {
+			smoothed = releaseCoeff*smoothed + (1-releaseCoeff)*gains[i]
+		}
+		l[i] = float32(float64(l[i]) * smoothed)
+		r[i] = float32(float64(r[i]) * smoothed)
+	}
+
+	return TruePeakDb(l, r)
+}
+
+// TruePeakDb measures the 4x-oversampled true peak of a stereo signal, in
+// dBTP.
+func TruePeakDb(l, r []float32) float64 {
+	peak := 0.0
+	for _, buf := range [][]float32{oversample(l, oversampleFactor), oversample(r, oversampleFactor)} {
+		for _, v := range buf {
+			if av := math.Abs(float64(v)); av > peak {
+				peak = av
+			}
+		}
+	}
+	return linearToDb(peak)
+}
+
+// oversample upsamples buf by factor using linear interpolation. This is an
+// approximation of the polyphase FIR interpolation real true-peak meters
+// use, but it's sufficient to catch the inter-sample peaks a brick-wall
+// limiter needs to react to.
+func oversample(buf []float32, factor int) []float32 {
+	if len(buf) == 0 {
+		return buf
+	}
+	out := make([]float32, 0, len(buf)*factor)
+	for i := 0; i < len(buf); i++ {
+		cur := buf[i]
+		next := cur
+		if i+1 < len(buf) {
+			next = buf[i+1]
+		}
+		for k := 0; k < factor; k++ {
+			t := float32(k) / float32(factor)
+			out = append(out, cur+(next-cur)*t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// MeasureLUFS computes ITU-R BS.1770-4 integrated loudness (in LUFS) for a
+// stereo signal: K-weighting (a high-shelf pre-filter cascaded with the RLB
+// high-pass), 400ms blocks with 75% overlap, an absolute gate at -70 LUFS,
+// then a relative gate 10 LU below the mean of the absolute-gated blocks.
+func MeasureLUFS(l, r []float32, sampleRate int) (float64, error) {
+	if sampleRate != SampleRate {
+		return 0, fmt.Errorf("loudness measurement requires %d Hz input, got %d", SampleRate, sampleRate)
+	}
+	if len(l) == 0 {
+		return -70, nil
+	}
+
+	kl := kWeight(l)
+	kr := kWeight(r)
+
+	blockSize := int(0.4 * float64(sampleRate)) // 400ms
+	hop := blockSize / 4                        // 75% overlap
+	if hop == 0 {
+		hop = 1
+	}
+
+	var loudness, z []float64
+	for start := 0; start+blockSize <= len(kl); start += hop {
+		var sumL, sumR float64
+		for i := start; i < start+blockSize; i++ {
+			sumL += float64(kl[i]) * float64(kl[i])
+			sumR += float64(kr[i]) * float64(kr[i])
+		}
+		blockZ := (sumL + sumR) / float64(blockSize)
+		if blockZ <= 0 {
+			continue
+		}
+		blockLoudness := -0.691 + 10*math.Log10(blockZ)
+		if blockLoudness < -70 { // absolute gate
+			continue
+		}
+		loudness = append(loudness, blockLoudness)
+		z = append(z, blockZ)
+	}
+	if len(z) == 0 {
+		return -70, nil
+	}
+
+	relativeThreshold := -0.691 + 10*math.Log10(mean(z)) - 10
+
+	var gatedZ []float64
+	for i, blockLoudness := range loudness {
+		if blockLoudness >= relativeThreshold {
+			gatedZ = append(gatedZ, z[i])
+		}
+	}
+	if len(gatedZ) == 0 {
+		return -70, nil
+	}
+
+	return -0.691 + 10*math.Log10(mean(gatedZ)), nil
+}
+
+// kWeight applies the BS.1770 K-weighting filter (pre-filter high-shelf
+// cascaded with the RLB high-pass) used ahead of loudness summing. The
+// coefficients below are the standard published values for a 48kHz signal.
+func kWeight(buf []float32) []float32 {
+	preFilter := newBiquadCoeffs(1.53512485958697, -2.69169618940638, 1.19839281085285, -1.69065929318241, 0.73248077421585)
+	rlb := newBiquadCoeffs(1.0, -2.0, 1.0, -1.99004745483398, 0.99007225036621)
+
+	out := make([]float32, len(buf))
+	copy(out, buf)
+	preFilter.ProcessBuffer(out)
+	rlb.ProcessBuffer(out)
+	return out
+}
+
+func mean(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
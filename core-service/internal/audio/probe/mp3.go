@@ -0,0 +1,112 @@
+package probe
+
+import (
+	"fmt"
+	"io"
+)
+
+// mpegSampleRates is indexed by the frame header's 2-bit MPEG version ID:
+// 0=MPEG 2.5, 1=reserved, 2=MPEG 2, 3=MPEG 1. Each row is indexed by the
+// 2-bit sample-rate index from the header.
+var mpegSampleRates = [4][3]int{
+	{11025, 12000, 8000},
+	{},
+	{22050, 24000, 16000},
+	{44100, 48000, 32000},
+}
+
+// mpegLayer3Bitrates is indexed by [isMPEG1][bitrateIndex], in kbps. MPEG
+// 2/2.5 share the same Layer III bitrate table.
+var mpegLayer3Bitrates = [2][16]int{
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+}
+
+func looksLikeMP3(magic []byte) bool {
+	if len(magic) >= 3 && string(magic[0:3]) == "ID3" {
+		return true
+	}
+	return len(magic) >= 2 && magic[0] == 0xFF && magic[1]&0xE0 == 0xE0
+}
+
+// probeMP3 scans for the first valid MPEG audio frame header, skipping an
+// ID3v2 tag if present. MP3 has no container-level sample-rate/duration
+// metadata the way WAV/MP4 do, so duration is estimated from the frame's
+// bitrate assuming CBR (true for the vast majority of vocal-take exports).
+func probeMP3(r io.ReaderAt, declaredSize int64) (*Result, error) {
+	offset, err := skipID3v2(r, declaredSize)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 4)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+	}
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return nil, ErrUnrecognizedContainer
+	}
+
+	versionID := (header[1] >> 3) & 0x3
+	layerID := (header[1] >> 1) & 0x3
+	bitrateIndex := (header[2] >> 4) & 0xF
+	sampleRateIndex := (header[2] >> 2) & 0x3
+	channelMode := (header[3] >> 6) & 0x3
+
+	if layerID != 0x1 { // "01" = Layer III
+		return nil, ErrUnsupportedCodec
+	}
+	if sampleRateIndex == 0x3 {
+		return nil, ErrUnrecognizedContainer
+	}
+	sampleRate := mpegSampleRates[versionID][sampleRateIndex]
+	if sampleRate == 0 {
+		return nil, ErrUnrecognizedContainer
+	}
+
+	isMPEG1 := 0
+	if versionID == 0x3 {
+		isMPEG1 = 1
+	}
+	if bitrateIndex == 0 || bitrateIndex == 0xF {
+		return nil, ErrUnrecognizedContainer
+	}
+	bitrateKbps := mpegLayer3Bitrates[isMPEG1][bitrateIndex]
+
+	channels := 2
+	if channelMode == 0x3 {
+		channels = 1
+	}
+
+	mediaBytes := declaredSize - offset
+	if mediaBytes <= 0 {
+		return nil, ErrTruncated
+	}
+
+	return &Result{
+		Codec:      CodecMP3,
+		SampleRate: sampleRate,
+		Channels:   channels,
+		Duration:   float64(mediaBytes*8) / float64(bitrateKbps*1000),
+	}, nil
+}
+
+// skipID3v2 returns the byte offset of the first audio frame, past any
+// ID3v2 tag at the start of the file.
+func skipID3v2(r io.ReaderAt, declaredSize int64) (int64, error) {
+	tag := make([]byte, 10)
+	n, err := r.ReadAt(tag, 0)
+	if err != nil && err != io.EOF {
+		return 0, fmt.Errorf("%w: %v", ErrTruncated, err)
+	}
+	if n < 10 || string(tag[0:3]) != "ID3" {
+		return 0, nil
+	}
+
+	size := int64(tag[6])<<21 | int64(tag[7])<<14 | int64(tag[8])<<7 | int64(tag[9])
+	offset := 10 + size
+	if offset >= declaredSize {
+		return 0, ErrTruncated
+	}
+	return offset, nil
+}
@@ -0,0 +1,91 @@
+package probe
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/abema/go-mp4"
+)
+
+// probeMP4 walks the moov box of an MP4/M4A container down to the first
+// audio track's sample description (stsd) and media header (mdhd), so it
+// can report the real codec — ALAC vs AAC, both of which use the "M4A"
+// extension and an audio/mp4 Content-Type — instead of trusting either.
+func probeMP4(r io.ReaderAt, declaredSize int64) (*Result, error) {
+	sr := io.NewSectionReader(r, 0, declaredSize)
+
+	var (
+		codec      Codec
+		sampleRate uint32
+		channels   uint16
+		timescale  uint32
+		duration   uint64
+		found      bool
+	)
+
+	_, err := mp4.ReadBoxStructure(sr, func(h *mp4.ReadHandle) (interface{}, error) {
+		switch h.BoxInfo.Type.String() {
+		case "moov", "trak", "mdia", "minf", "stbl", "stsd":
+			return h.Expand()
+
+		case "mdhd":
+			if found {
+				return nil, nil
+			}
+			box, _, err := h.ReadPayload()
+			if err != nil {
+				return nil, err
+			}
+			mdhd := box.(*mp4.Mdhd)
+			if mdhd.GetVersion() == 0 {
+				timescale = mdhd.TimescaleV0
+				duration = uint64(mdhd.DurationV0)
+			} else {
+				timescale = mdhd.TimescaleV1
+				duration = mdhd.DurationV1
+			}
+			return nil, nil
+
+		case "mp4a", "alac":
+			if found {
+				return nil, nil
+			}
+			box, _, err := h.ReadPayload()
+			if err != nil {
+				return nil, err
+			}
+			entry, ok := box.(*mp4.AudioSampleEntry)
+			if !ok {
+				return nil, nil
+			}
+			if h.BoxInfo.Type.String() == "alac" {
+				codec = CodecALAC
+			} else {
+				codec = CodecAAC
+			}
+			sampleRate = entry.SampleRate >> 16
+			channels = entry.ChannelCount
+			found = true
+			return nil, nil
+
+		default:
+			return nil, nil
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+	}
+	if !found {
+		return nil, ErrUnrecognizedContainer
+	}
+	if timescale == 0 {
+		return nil, fmt.Errorf("%w: missing mdhd", ErrTruncated)
+	}
+
+	return &Result{
+		Codec:      codec,
+		SampleRate: int(sampleRate),
+		Channels:   int(channels),
+		Duration:   float64(duration) / float64(timescale),
+	}, nil
+}
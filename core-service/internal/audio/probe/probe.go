@@ -0,0 +1,106 @@
+// Package probe sniffs the real container format of an uploaded audio file
+// instead of trusting the client-supplied Content-Type, which is easily
+// spoofed and can't distinguish M4A/ALAC from M4A/AAC or catch a truncated
+// upload. UploadHandler.Vocal calls Probe before accepting a file and
+// persists the result onto UploadVocalResponse so downstream mastering can
+// make decisions without re-probing.
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Codec identifies the audio codec found inside a container.
+type Codec string
+
+const (
+	CodecPCM  Codec = "pcm"  // WAV (RIFF/WAVE) with a PCM fmt chunk
+	CodecMP3  Codec = "mp3"  // raw MPEG-1/2 Layer III frames
+	CodecALAC Codec = "alac" // Apple Lossless inside an MP4/M4A container
+	CodecAAC  Codec = "aac"  // AAC inside an MP4/M4A container
+)
+
+// allowedCodecs is the set of codecs UploadHandler.Vocal accepts. Anything
+// else a container parses to (e.g. an MP4 carrying an unrecognized codec
+// fourcc) is rejected even though the container itself is well-formed.
+var allowedCodecs = map[Codec]bool{
+	CodecPCM:  true,
+	CodecMP3:  true,
+	CodecALAC: true,
+	CodecAAC:  true,
+}
+
+const (
+	minSampleRateHz = 16000
+	maxSampleRateHz = 96000
+)
+
+// Result is the metadata recovered from parsing a container.
+type Result struct {
+	Codec      Codec
+	SampleRate int
+	Channels   int
+	Duration   float64  // seconds
+	PeakDBFS   *float64 // nil when not cheaply computable for this codec
+}
+
+var (
+	// ErrUnrecognizedContainer is returned when the first bytes of the file
+	// don't match any supported container's magic number.
+	ErrUnrecognizedContainer = errors.New("probe: unrecognized audio container")
+	// ErrUnsupportedCodec is returned when the container parses cleanly but
+	// the codec it carries isn't in the allow-list.
+	ErrUnsupportedCodec = errors.New("probe: unsupported codec")
+	// ErrSampleRateOutOfRange is returned when the probed sample rate falls
+	// outside [16kHz, 96kHz].
+	ErrSampleRateOutOfRange = errors.New("probe: sample rate out of range")
+	// ErrTruncated is returned when the container's own structure expects
+	// more bytes than the file actually has, or a chunk/box can't be read.
+	ErrTruncated = errors.New("probe: truncated or corrupt file")
+	// ErrSizeMismatch is returned when the container's declared length
+	// disagrees with the Content-Length the client sent.
+	ErrSizeMismatch = errors.New("probe: declared size does not match parseable media data")
+)
+
+// Probe sniffs r's container format from its first bytes and parses it for
+// codec/sample-rate/channel/duration metadata, validating against the
+// allow-list and supported sample-rate range. declaredSize is the
+// Content-Length the client reported (e.g. multipart.FileHeader.Size).
+func Probe(r io.ReaderAt, declaredSize int64) (*Result, error) {
+	if declaredSize < 12 {
+		return nil, ErrTruncated
+	}
+
+	magic := make([]byte, 12)
+	if _, err := r.ReadAt(magic, 0); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+	}
+
+	var (
+		result *Result
+		err    error
+	)
+	switch {
+	case string(magic[0:4]) == "RIFF" && string(magic[8:12]) == "WAVE":
+		result, err = probeWAV(r, declaredSize)
+	case string(magic[4:8]) == "ftyp":
+		result, err = probeMP4(r, declaredSize)
+	case looksLikeMP3(magic):
+		result, err = probeMP3(r, declaredSize)
+	default:
+		return nil, ErrUnrecognizedContainer
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowedCodecs[result.Codec] {
+		return nil, ErrUnsupportedCodec
+	}
+	if result.SampleRate < minSampleRateHz || result.SampleRate > maxSampleRateHz {
+		return nil, ErrSampleRateOutOfRange
+	}
+	return result, nil
+}
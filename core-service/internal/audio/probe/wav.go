@@ -0,0 +1,99 @@
+package probe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// wavFmtFormatPCM and wavFmtFormatExtensible are the only wFormatTag values
+// probeWAV accepts; anything else (e.g. ADPCM, MP3-in-WAV) isn't PCM.
+const (
+	wavFmtFormatPCM        = 1
+	wavFmtFormatExtensible = 0xFFFE
+)
+
+type wavFmt struct {
+	formatTag  uint16
+	channels   uint16
+	sampleRate uint32
+	byteRate   uint32
+}
+
+// probeWAV walks a RIFF/WAVE file's chunk list to find the fmt and data
+// chunks, since WAV carries no single header with everything needed.
+func probeWAV(r io.ReaderAt, declaredSize int64) (*Result, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+	}
+	riffSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+	if riffSize+8 > declaredSize+2 {
+		return nil, ErrSizeMismatch
+	}
+
+	var (
+		format   *wavFmt
+		dataSize int64
+	)
+
+	offset := int64(12) // past "RIFF"+size+"WAVE"
+	for offset+8 <= declaredSize {
+		chunkHeader := make([]byte, 8)
+		if _, err := r.ReadAt(chunkHeader, offset); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+		}
+		id := string(chunkHeader[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			buf := make([]byte, size)
+			if _, err := r.ReadAt(buf, body); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+			}
+			f, err := parseWAVFmt(buf)
+			if err != nil {
+				return nil, err
+			}
+			format = f
+		case "data":
+			dataSize = size
+		}
+
+		offset = body + size
+		if size%2 == 1 {
+			offset++ // chunks are word-aligned
+		}
+	}
+
+	if format == nil || dataSize == 0 {
+		return nil, fmt.Errorf("%w: missing fmt or data chunk", ErrTruncated)
+	}
+	if format.formatTag != wavFmtFormatPCM && format.formatTag != wavFmtFormatExtensible {
+		return nil, ErrUnsupportedCodec
+	}
+	if format.byteRate == 0 {
+		return nil, fmt.Errorf("%w: fmt chunk has zero byte rate", ErrTruncated)
+	}
+
+	return &Result{
+		Codec:      CodecPCM,
+		SampleRate: int(format.sampleRate),
+		Channels:   int(format.channels),
+		Duration:   float64(dataSize) / float64(format.byteRate),
+	}, nil
+}
+
+func parseWAVFmt(buf []byte) (*wavFmt, error) {
+	if len(buf) < 16 {
+		return nil, fmt.Errorf("%w: fmt chunk too short", ErrTruncated)
+	}
+	return &wavFmt{
+		formatTag:  binary.LittleEndian.Uint16(buf[0:2]),
+		channels:   binary.LittleEndian.Uint16(buf[2:4]),
+		sampleRate: binary.LittleEndian.Uint32(buf[4:8]),
+		byteRate:   binary.LittleEndian.Uint32(buf[8:12]),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Waveform decodes the audio file at path and returns a peak-bucketed
+// summary of it: buckets values, each the maximum absolute sample in that
+// slice of the signal. This is for the lightweight visual waveform the
+// frontend draws under a stem, not for playback or measurement — callers
+// that need actual loudness/peak figures should decode via MeasureLUFS/
+// TruePeakDb instead.
+func Waveform(ctx context.Context, path string, buckets int) ([]float64, error) {
+	samples, err := decodeMonoPCM(ctx, path, SampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode for waveform: %w", err)
+	}
+	return peakBucket(samples, buckets), nil
+}
+
+// peakBucket splits samples into `buckets` equal-length (except possibly the
+// last) windows and returns the max absolute value in each.
+func peakBucket(samples []float32, buckets int) []float64 {
+	out := make([]float64, buckets)
+	if len(samples) == 0 || buckets <= 0 {
+		return out
+	}
+
+	bucketSize := len(samples) / buckets
+	if bucketSize == 0 {
+		bucketSize = 1
+	}
+
+	for i := 0; i < buckets; i++ {
+		start := i * bucketSize
+		if start >= len(samples) {
+			break
+		}
+		end := start + bucketSize
+		if i == buckets-1 || end > len(samples) {
+			end = len(samples)
+		}
+
+		var peak float32
+		for _, s := range samples[start:end] {
+			if v := float32(math.Abs(float64(s))); v > peak {
+				peak = v
+			}
+		}
+		out[i] = float64(peak)
+	}
+	return out
+}
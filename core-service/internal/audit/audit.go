@@ -0,0 +1,74 @@
+// Package audit records a narrow subset of security- and job-lifecycle
+// events (auth, job submission, cancellation) to a Redis stream for later
+// export, separate from the normal structured request logs in internal/log.
+// A stream rather than a list or plain log line because consumers (e.g. a
+// SIEM export job) can read it with a consumer group and pick up exactly
+// where they left off after a restart.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/log"
+)
+
+// StreamKey is the Redis stream every Recorder writes events to.
+const StreamKey = "audit:events"
+
+// maxStreamLen caps the stream with an approximate trim (Redis's "~" form,
+// which doesn't block on an exact count) so it can't grow unbounded if
+// nothing is consuming it.
+const maxStreamLen = 100_000
+
+// Event is one audit-worthy occurrence. Fields is free-form (jobId, jti,
+// reason, ...) since different event types carry different context.
+type Event struct {
+	Type   string // e.g. "auth.revoke", "render.submit", "render.cancel"
+	UserID string
+	Fields map[string]string
+}
+
+// Recorder writes Events to the audit:events Redis stream. A nil *Recorder
+// or one built with enabled=false makes Record a no-op, so call sites don't
+// need their own config check.
+type Recorder struct {
+	client  *redis.Client
+	enabled bool
+}
+
+// NewRecorder creates a Recorder. enabled should come from
+// config.ServerConfig.AuditEnabled; when false, Record does nothing.
+func NewRecorder(client *redis.Client, enabled bool) *Recorder {
+	return &Recorder{client: client, enabled: enabled}
+}
+
+// Record appends evt to the stream. Failures are logged and swallowed
+// rather than returned: a missed audit write shouldn't fail the request
+// that triggered it.
+func (r *Recorder) Record(ctx context.Context, evt Event) {
+	if r == nil || !r.enabled {
+		return
+	}
+
+	values := map[string]interface{}{
+		"type":      evt.Type,
+		"userId":    evt.UserID,
+		"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	for k, v := range evt.Fields {
+		values[k] = v
+	}
+
+	err := r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: StreamKey,
+		MaxLen: maxStreamLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+	if err != nil {
+		log.Warn(ctx, "failed to record audit event", "type", evt.Type, "err", err)
+	}
+}
@@ -0,0 +1,384 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/makeasinger/api/internal/config"
+	"github.com/makeasinger/api/internal/log"
+)
+
+// TokenVerifier defines the interface for JWT token verification
+type TokenVerifier interface {
+	Validate(ctx context.Context, tokenString string) (*Claims, error)
+	Close() error
+}
+
+// Claims represents the JWT claims from Zitadel or any other federated issuer
+type Claims struct {
+	UserID            string              `json:"sub"`
+	Email             string              `json:"email,omitempty"`
+	EmailVerified     bool                `json:"email_verified,omitempty"`
+	Name              string              `json:"name,omitempty"`
+	PreferredUsername string              `json:"preferred_username,omitempty"`
+	Roles             []string            `json:"roles,omitempty"`
+	Scope             string              `json:"scope,omitempty"`
+	Plan              string              `json:"plan,omitempty"`
+	// Azp is the OIDC "authorized party" claim: the client ID that actually
+	// requested the token. Distinct from Audience, which may list several
+	// trusted API resources -- Azp is who's holding the token, not who it's
+	// valid for. Not currently checked against config, just surfaced for
+	// callers (e.g. audit logging) that need to tell clients apart.
+	Azp string `json:"azp,omitempty"`
+	Grants            map[string][]string `json:"urn:zitadel:iam:org:project:roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GetRoles returns the roles granted to the token, combining the flat
+// `roles` claim with the role portion of any Zitadel project-role grants
+// (e.g. a grant of "admin" for org "123" also counts as role "admin").
+// Satisfies the RoleScoper interface consumed by middleware.RequireRole.
+func (c Claims) GetRoles() []string {
+	if len(c.Grants) == 0 {
+		return c.Roles
+	}
+	roles := make([]string, 0, len(c.Roles)+len(c.Grants))
+	roles = append(roles, c.Roles...)
+	for role := range c.Grants {
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// GetScopes splits the space-separated scope claim, satisfying the
+// RoleScoper interface consumed by middleware.RequireScope.
+func (c Claims) GetScopes() []string {
+	return splitScope(c.Scope)
+}
+
+// GetPlan returns the token's billing plan, satisfying the PlanScoper
+// interface consumed by middleware.RateLimiter. Zitadel custom claims are
+// optional, so tokens with none default to "free".
+func (c Claims) GetPlan() string {
+	if c.Plan == "" {
+		return "free"
+	}
+	return c.Plan
+}
+
+var (
+	jwksRotationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_jwks_rotations_total",
+		Help: "Total number of successful per-issuer JWKS refreshes.",
+	}, []string{"issuer"})
+	jwksRefreshFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_jwks_refresh_failures_total",
+		Help: "Total number of failed per-issuer JWKS refresh attempts.",
+	}, []string{"issuer"})
+)
+
+// allowedSigningMethods restricts Validate to the asymmetric algorithms
+// every federated issuer here actually signs with. Without an explicit
+// allowlist, jwt.ParseWithClaims accepts whatever alg the token header
+// names as long as entry.keyfunc() returns a key for it -- including HS256,
+// which would let a caller who knows a public RSA key forge a token by
+// signing it with that key as an HMAC secret (the classic alg-confusion
+// attack). RS256/ES256 cover every IdP this package discovers keys from
+// (Zitadel, Auth0, Keycloak, Google all publish one or the other).
+var allowedSigningMethods = []string{"RS256", "ES256"}
+
+// jwksRefreshInterval is how often the background refresher re-discovers
+// each issuer's JWKS on success; failures back off exponentially from
+// jwksRefreshMinBackoff up to this interval instead of hammering a down IdP.
+const (
+	jwksRefreshInterval   = 15 * time.Minute
+	jwksRefreshMinBackoff = 30 * time.Second
+)
+
+// issuerEntry holds one federated issuer's cached discovery state: the
+// keyfunc used to verify signatures, plus the audience/role policy applied
+// once a token has been cryptographically verified.
+type issuerEntry struct {
+	mu       sync.RWMutex
+	jwks     keyfunc.Keyfunc
+	audience string
+	roles    []string // allowed roles; empty means any role is accepted
+}
+
+func (e *issuerEntry) keyfunc() jwt.Keyfunc {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.jwks.Keyfunc
+}
+
+func (e *issuerEntry) setJWKS(jwks keyfunc.Keyfunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.jwks = jwks
+}
+
+// FederatedVerifier implements TokenVerifier against multiple trusted JWKS
+// issuers (Zitadel plus any staff IdP / partner tenant configured via
+// config.ZitadelConfig.ExtraIssuers). It picks the right key set from the
+// token's own `iss` claim, and refreshes every issuer's JWKS in the
+// background so rotated keys are picked up without a restart.
+type FederatedVerifier struct {
+	issuers     map[string]*issuerEntry // issuer -> entry
+	revocation  RevocationStore         // may be nil, in which case no revocation check is performed
+	clock       Clock
+	stopRefresh chan struct{}
+}
+
+// WithClock swaps the Clock used to evaluate exp/nbf, for tests that need to
+// advance past a token's expiry without sleeping in real time. Returns v for
+// chaining at the construction site.
+func (v *FederatedVerifier) WithClock(clk Clock) *FederatedVerifier {
+	v.clock = clk
+	return v
+}
+
+// NewFederatedVerifier discovers the JWKS for cfg.Issuer plus every entry in
+// cfg.ExtraIssuers and starts the background refresher. revocation may be
+// nil to skip the revocation check entirely (e.g. in tests that don't
+// exercise /api/auth/revoke).
+func NewFederatedVerifier(cfg *config.ZitadelConfig, revocation RevocationStore) (*FederatedVerifier, error) {
+	issuers := make([]config.OIDCIssuer, 0, 1+len(cfg.ExtraIssuers))
+	if cfg.Issuer != "" {
+		issuers = append(issuers, config.OIDCIssuer{Issuer: cfg.Issuer, Audience: cfg.ClientID})
+	}
+	issuers = append(issuers, cfg.ExtraIssuers...)
+	if len(issuers) == 0 {
+		return nil, fmt.Errorf("at least one issuer is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	v := &FederatedVerifier{
+		issuers:     make(map[string]*issuerEntry, len(issuers)),
+		revocation:  revocation,
+		clock:       realClock(),
+		stopRefresh: make(chan struct{}),
+	}
+
+	for _, iss := range issuers {
+		jwks, err := discoverAndBuildKeyfunc(ctx, iss.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up issuer %q: %w", iss.Issuer, err)
+		}
+		v.issuers[iss.Issuer] = &issuerEntry{jwks: jwks, audience: iss.Audience, roles: iss.Roles}
+	}
+
+	go v.refreshLoop()
+
+	return v, nil
+}
+
+// discoverAndBuildKeyfunc fetches the OIDC discovery document for issuer
+// and builds a keyfunc.Keyfunc from its jwks_uri.
+func discoverAndBuildKeyfunc(ctx context.Context, issuer string) (keyfunc.Keyfunc, error) {
+	jwksURL, err := discoverJWKSURL(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover JWKS URL: %w", err)
+	}
+	jwks, err := keyfunc.NewDefaultCtx(ctx, []string{jwksURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS keyfunc: %w", err)
+	}
+	return jwks, nil
+}
+
+// discoverJWKSURL fetches the OIDC discovery document and extracts the jwks_uri.
+func discoverJWKSURL(ctx context.Context, issuer string) (string, error) {
+	doc, err := DiscoverOIDCConfiguration(ctx, issuer)
+	if err != nil {
+		return "", err
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("jwks_uri not found in discovery document")
+	}
+	return doc.JWKSURI, nil
+}
+
+// OIDCDiscoveryDocument holds the subset of an issuer's
+// /.well-known/openid-configuration response the rest of the package cares
+// about: where to fetch keys from, and where to exchange tokens.
+type OIDCDiscoveryDocument struct {
+	JWKSURI       string `json:"jwks_uri"`
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// DiscoverOIDCConfiguration fetches and decodes issuer's OIDC discovery
+// document. Shared by the JWKS verifier (which needs jwks_uri) and the
+// session package (which needs token_endpoint), so both stay in sync on how
+// an issuer is discovered.
+func DiscoverOIDCConfiguration(ctx context.Context, issuer string) (*OIDCDiscoveryDocument, error) {
+	discoveryURL := fmt.Sprintf("%s/.well-known/openid-configuration", issuer)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint returned status %d", resp.StatusCode)
+	}
+
+	var doc OIDCDiscoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// refreshLoop re-discovers every issuer's JWKS on a fixed interval so
+// rotated signing keys are picked up without a restart, backing off
+// exponentially per-issuer on failure instead of retrying at full speed
+// against a down IdP.
+func (v *FederatedVerifier) refreshLoop() {
+	ctx := context.Background()
+	backoff := make(map[string]time.Duration, len(v.issuers))
+	timer := time.NewTimer(jwksRefreshInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-v.stopRefresh:
+			return
+		case <-timer.C:
+		}
+
+		next := jwksRefreshInterval
+		for issuer, entry := range v.issuers {
+			reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			jwks, err := discoverAndBuildKeyfunc(reqCtx, issuer)
+			cancel()
+
+			if err != nil {
+				jwksRefreshFailuresTotal.WithLabelValues(issuer).Inc()
+				d := backoff[issuer]
+				if d == 0 {
+					d = jwksRefreshMinBackoff
+				} else {
+					d = time.Duration(math.Min(float64(d*2), float64(jwksRefreshInterval)))
+				}
+				backoff[issuer] = d
+				log.Error(ctx, "jwks refresh failed", "issuer", issuer, "err", err, "retryIn", d)
+				if d < next {
+					next = d
+				}
+				continue
+			}
+
+			delete(backoff, issuer)
+			entry.setJWKS(jwks)
+			jwksRotationsTotal.WithLabelValues(issuer).Inc()
+			log.Info(ctx, "jwks refreshed", "issuer", issuer)
+		}
+
+		// Jitter avoids every issuer's failed refresh retrying in lockstep.
+		jitter := time.Duration(rand.Int63n(int64(time.Second)))
+		timer.Reset(next + jitter)
+	}
+}
+
+// Validate validates a JWT token and returns its claims. The issuer is read
+// from the token's own (unverified) claims to pick which issuer's keyfunc,
+// audience, and role policy apply, then the signature/issuer/expiration are
+// verified against that issuer specifically.
+func (v *FederatedVerifier) Validate(ctx context.Context, tokenString string) (*Claims, error) {
+	var unverified Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &unverified); err != nil {
+		return nil, fmt.Errorf("failed to read token claims: %w", err)
+	}
+
+	entry, ok := v.issuers[unverified.Issuer]
+	if !ok {
+		return nil, fmt.Errorf("untrusted issuer %q", unverified.Issuer)
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, entry.keyfunc(),
+		jwt.WithIssuer(unverified.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(v.clock.Now),
+		jwt.WithValidMethods(allowedSigningMethods),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	if entry.audience != "" {
+		aud, err := claims.GetAudience()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get audience: %w", err)
+		}
+		if !contains(aud, entry.audience) {
+			return nil, fmt.Errorf("invalid audience")
+		}
+	}
+
+	if len(entry.roles) > 0 && !containsAny(claims.Roles, entry.roles) {
+		return nil, fmt.Errorf("issuer %q is not authorized for any of this token's roles", unverified.Issuer)
+	}
+
+	if v.revocation != nil && claims.ID != "" {
+		revoked, err := v.revocation.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, fmt.Errorf("token has been revoked")
+		}
+	}
+
+	return claims, nil
+}
+
+// Close stops the background refresher.
+func (v *FederatedVerifier) Close() error {
+	close(v.stopRefresh)
+	return nil
+}
+
+// contains checks if a slice contains a string
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether any element of a is also in b.
+func containsAny(a, b []string) bool {
+	for _, item := range a {
+		if contains(b, item) {
+			return true
+		}
+	}
+	return false
+}
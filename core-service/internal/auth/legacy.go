@@ -1,24 +1,66 @@
 package auth
 
 import (
+	"strings"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // LegacyClaims represents legacy JWT claims (HMAC-signed tokens)
 type LegacyClaims struct {
-	UserID string `json:"userId"`
-	Email  string `json:"email"`
+	UserID string   `json:"userId"`
+	Email  string   `json:"email"`
+	Roles  []string `json:"roles,omitempty"`
+	Scope  string   `json:"scope,omitempty"`
+	Plan   string   `json:"plan,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// ValidateLegacyToken validates a token using HMAC signing
+// GetRoles returns the roles granted to the token, satisfying the
+// RoleScoper interface consumed by middleware.RequireRole.
+func (c LegacyClaims) GetRoles() []string {
+	return c.Roles
+}
+
+// GetScopes splits the space-separated scope claim, satisfying the
+// RoleScoper interface consumed by middleware.RequireScope.
+func (c LegacyClaims) GetScopes() []string {
+	return splitScope(c.Scope)
+}
+
+// GetPlan returns the token's billing plan, satisfying the PlanScoper
+// interface consumed by middleware.RateLimiter. Tokens issued before plans
+// existed carry no claim, so an empty value defaults to "free".
+func (c LegacyClaims) GetPlan() string {
+	if c.Plan == "" {
+		return "free"
+	}
+	return c.Plan
+}
+
+// splitScope splits a space-separated OAuth2-style scope string into its
+// individual scope values, ignoring extra whitespace.
+func splitScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// ValidateLegacyToken validates a token using HMAC signing against the
+// wall clock. It's a thin wrapper around ValidateLegacyTokenWithClock for
+// production callers that have no need to control time.
 func ValidateLegacyToken(tokenString, secret string) (*LegacyClaims, error) {
+	return ValidateLegacyTokenWithClock(tokenString, secret, realClock())
+}
+
+// ValidateLegacyTokenWithClock validates a token using HMAC signing,
+// evaluating exp/nbf/iat against clk instead of the wall clock. Tests use
+// this with a *clock.Mock to exercise expiry paths deterministically.
+func ValidateLegacyTokenWithClock(tokenString, secret string, clk Clock) (*LegacyClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &LegacyClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
 		return []byte(secret), nil
-	})
+	}, jwt.WithTimeFunc(clk.Now))
 
 	if err != nil {
 		return nil, err
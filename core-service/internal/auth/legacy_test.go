@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signLegacy(t *testing.T, secret string, claims LegacyClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestValidateLegacyTokenWithClock_ExpiresOnMockClock(t *testing.T) {
+	const secret = "test-secret"
+	mock := clock.NewMock()
+	mock.Set(time.Unix(1_700_000_000, 0))
+
+	token := signLegacy(t, secret, LegacyClaims{
+		UserID: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(mock.Now()),
+			ExpiresAt: jwt.NewNumericDate(mock.Now().Add(time.Minute)),
+		},
+	})
+
+	if _, err := ValidateLegacyTokenWithClock(token, secret, mock); err != nil {
+		t.Fatalf("expected token to be valid before expiry, got: %v", err)
+	}
+
+	mock.Add(2 * time.Minute)
+
+	if _, err := ValidateLegacyTokenWithClock(token, secret, mock); err == nil {
+		t.Fatal("expected token to be rejected once the mock clock passes exp")
+	}
+}
+
+func TestMockVerifier_ReturnsConfiguredClaimsOrError(t *testing.T) {
+	claims := &Claims{UserID: "user-1"}
+	v := &MockVerifier{Claims: claims}
+
+	got, err := v.Validate(nil, "irrelevant")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != claims {
+		t.Fatalf("expected configured claims back, got %+v", got)
+	}
+
+	v = &MockVerifier{Err: jwt.ErrTokenExpired}
+	if _, err := v.Validate(nil, "irrelevant"); err != jwt.ErrTokenExpired {
+		t.Fatalf("expected configured error, got %v", err)
+	}
+}
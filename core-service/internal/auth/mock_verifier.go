@@ -0,0 +1,24 @@
+package auth
+
+import "context"
+
+// MockVerifier is a TokenVerifier test double that returns a configured
+// *Claims (or Err) without touching the network or a signing key, for tests
+// that need a deterministic identity without minting a real JWT.
+type MockVerifier struct {
+	Claims *Claims
+	Err    error
+}
+
+// Validate ignores tokenString and returns the configured Claims/Err.
+func (m *MockVerifier) Validate(ctx context.Context, tokenString string) (*Claims, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Claims, nil
+}
+
+// Close is a no-op; MockVerifier owns no background refresher to stop.
+func (m *MockVerifier) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisRevocationKeyPrefix namespaces revocation entries in the shared Redis
+// keyspace from job/session/rate-limit keys.
+const redisRevocationKeyPrefix = "auth:revoked:"
+
+// RedisRevocationStore is a RevocationStore backed by Redis, so a revocation
+// issued against one API replica is immediately visible to the others.
+// Entries are stored as plain keys with Redis-native TTL, so there's nothing
+// to sweep.
+type RedisRevocationStore struct {
+	client *redis.Client
+}
+
+// NewRedisRevocationStore creates a Redis-backed RevocationStore using an
+// already-connected client.
+func NewRedisRevocationStore(client *redis.Client) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client}
+}
+
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, redisRevocationKeyPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *RedisRevocationStore) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		return nil // already expired; nothing to track
+	}
+	return s.client.Set(ctx, redisRevocationKeyPrefix+jti, "1", ttl).Err()
+}
+
+// Close is a no-op: the underlying *redis.Client is shared and owned by
+// whoever constructed it (see app.InitializeApp's cleanup).
+func (s *RedisRevocationStore) Close() error {
+	return nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore tracks JWT IDs (jti) that have been revoked before their
+// natural expiry, so a verifier can reject an otherwise-valid token. Entries
+// are expected to expire on their own once the underlying token would have
+// expired anyway, so the store never grows unbounded.
+type RevocationStore interface {
+	// IsRevoked reports whether jti has been revoked and not yet expired.
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// Revoke marks jti as revoked until exp. Callers are expected to pass
+	// the token's own expiry so the entry disappears once the token would
+	// have stopped being valid regardless.
+	Revoke(ctx context.Context, jti string, exp time.Time) error
+	Close() error
+}
+
+// MemoryRevocationStore is an in-process RevocationStore backed by a TTL
+// map. It's the default for single-instance/dev deployments; multi-replica
+// deployments should use RedisRevocationStore instead so a revocation issued
+// against one instance is visible to the others.
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Time // jti -> expiry
+
+	stopSweep chan struct{}
+}
+
+// NewMemoryRevocationStore creates a MemoryRevocationStore and starts a
+// background goroutine that periodically evicts expired entries. Call
+// Close to stop it.
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	s := &MemoryRevocationStore{
+		entries:   make(map[string]time.Time),
+		stopSweep: make(chan struct{}),
+	}
+	go s.sweepLoop()
+	return s
+}
+
+func (s *MemoryRevocationStore) sweepLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stopSweep:
+			return
+		}
+	}
+}
+
+func (s *MemoryRevocationStore) sweep() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, exp := range s.entries {
+		if !exp.After(now) {
+			delete(s.entries, jti)
+		}
+	}
+}
+
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.entries[jti]
+	if !ok {
+		return false, nil
+	}
+	if !exp.After(time.Now()) {
+		delete(s.entries, jti)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[jti] = exp
+	return nil
+}
+
+func (s *MemoryRevocationStore) Close() error {
+	close(s.stopSweep)
+	return nil
+}
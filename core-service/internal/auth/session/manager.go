@@ -0,0 +1,163 @@
+// Package session exchanges a refresh token for a new Zitadel access token
+// using the private_key_jwt client assertion flow (RFC 7523 §2.2), so the
+// API can rotate a client's session without ever handling its client
+// secret directly.
+package session
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/makeasinger/api/internal/auth"
+)
+
+// clientAssertionTTL is the lifetime of the JWT this package mints to
+// authenticate itself to the token endpoint. Kept short because it's a
+// bearer credential in its own right: per RFC 7523 it should not outlive
+// the single request it's presented with by much.
+const clientAssertionTTL = 2 * time.Minute
+
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// TokenResponse is the subset of an OAuth2 token endpoint's response the
+// refresh flow cares about.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Manager exchanges refresh tokens for new access tokens against a single
+// Zitadel (or other OIDC-compliant) issuer, authenticating itself with a
+// private_key_jwt client assertion instead of a static client secret.
+type Manager struct {
+	issuer     string
+	signingKey *rsa.PrivateKey
+	keyID      string
+	httpClient *http.Client
+	clock      auth.Clock
+
+	tokenEndpoint string // cached after first discovery
+}
+
+// NewManager parses signingKeyPEM (a PEM-encoded RSA private key) and
+// returns a Manager that signs client assertions with it. Returns an error
+// if the key doesn't parse, so misconfiguration is caught at wiring time
+// rather than on the first refresh request.
+func NewManager(issuer, signingKeyPEM, keyID string) (*Manager, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(signingKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client assertion key: %w", err)
+	}
+	return &Manager{
+		issuer:     issuer,
+		signingKey: key,
+		keyID:      keyID,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		clock:      clock.New(),
+	}, nil
+}
+
+// WithClock swaps the Clock used to stamp the client assertion's iat/exp,
+// for tests that need deterministic timestamps. Returns m for chaining at
+// the construction site.
+func (m *Manager) WithClock(clk auth.Clock) *Manager {
+	m.clock = clk
+	return m
+}
+
+// Refresh exchanges refreshToken for a new TokenResponse, authenticating as
+// clientID via a freshly-signed private_key_jwt assertion. The token
+// endpoint is discovered from the issuer's OIDC configuration on first use
+// and cached for subsequent calls.
+func (m *Manager) Refresh(ctx context.Context, refreshToken, clientID string) (*TokenResponse, error) {
+	endpoint, err := m.resolveTokenEndpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := m.signAssertion(clientID, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":            {"refresh_token"},
+		"refresh_token":         {refreshToken},
+		"client_id":             {clientID},
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {assertion},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tok TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return &tok, nil
+}
+
+// resolveTokenEndpoint returns the cached token_endpoint, discovering it
+// from the issuer's OIDC configuration the first time it's needed.
+func (m *Manager) resolveTokenEndpoint(ctx context.Context) (string, error) {
+	if m.tokenEndpoint != "" {
+		return m.tokenEndpoint, nil
+	}
+	doc, err := auth.DiscoverOIDCConfiguration(ctx, m.issuer)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover token endpoint: %w", err)
+	}
+	if doc.TokenEndpoint == "" {
+		return "", fmt.Errorf("token_endpoint not found in discovery document for issuer %q", m.issuer)
+	}
+	m.tokenEndpoint = doc.TokenEndpoint
+	return m.tokenEndpoint, nil
+}
+
+// signAssertion builds and signs the RS256 client assertion JWT: aud is the
+// token endpoint itself, exp is capped at clientAssertionTTL, and jti is
+// unique per call so the assertion can't be replayed against a second
+// request.
+func (m *Manager) signAssertion(clientID, tokenEndpoint string) (string, error) {
+	now := m.clock.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    clientID,
+		Subject:   clientID,
+		Audience:  jwt.ClaimStrings{tokenEndpoint},
+		ExpiresAt: jwt.NewNumericDate(now.Add(clientAssertionTTL)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        uuid.NewString(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if m.keyID != "" {
+		token.Header["kid"] = m.keyID
+	}
+	return token.SignedString(m.signingKey)
+}
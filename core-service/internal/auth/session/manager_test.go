@@ -0,0 +1,53 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testRSAKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der}))
+}
+
+func TestManager_SignAssertion_CapsExpiryAndSetsKid(t *testing.T) {
+	mgr, err := NewManager("https://issuer.example.com", testRSAKeyPEM(t), "key-1")
+	if err != nil {
+		t.Fatalf("failed to build manager: %v", err)
+	}
+	mock := clock.NewMock()
+	mock.Set(time.Unix(1_700_000_000, 0))
+	mgr.WithClock(mock)
+
+	assertion, err := mgr.signAssertion("client-1", "https://issuer.example.com/oauth/v2/token")
+	if err != nil {
+		t.Fatalf("failed to sign assertion: %v", err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(assertion, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("failed to parse signed assertion: %v", err)
+	}
+	if parsed.Header["kid"] != "key-1" {
+		t.Fatalf("expected kid header to be set, got %v", parsed.Header["kid"])
+	}
+
+	claims := parsed.Claims.(jwt.MapClaims)
+	exp := int64(claims["exp"].(float64))
+	iat := int64(claims["iat"].(float64))
+	if exp-iat > int64(clientAssertionTTL.Seconds()) {
+		t.Fatalf("expected exp-iat <= %v, got %ds", clientAssertionTTL, exp-iat)
+	}
+}
@@ -3,10 +3,13 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/makeasinger/api/internal/config"
@@ -14,16 +17,30 @@ import (
 
 // AudioProcessor defines the interface for audio processing operations
 type AudioProcessor interface {
-	Master(ctx context.Context, req *MasterRequest) (*MasterResponse, error)
-	Encode(ctx context.Context, req *EncodeRequest) (*EncodeResponse, error)
-	CreateZip(ctx context.Context, req *ZipRequest) (*ZipResponse, error)
+	Master(ctx context.Context, jobID string, req *MasterRequest) (*MasterResponse, error)
+	Encode(ctx context.Context, jobID string, req *EncodeRequest) (*EncodeResponse, error)
+	CreateZip(ctx context.Context, jobID string, req *ZipRequest) (*ZipResponse, error)
+	// StreamZip starts assembling req's archive the same way CreateZip does,
+	// but returns as soon as the audio service has a chunked, Range-capable
+	// URL ready to serve from rather than blocking until the archive and its
+	// R2 upload are fully done. Callers use this for an immediate download
+	// link alongside the eventual CreateZip-produced permanent FileURL.
+	StreamZip(ctx context.Context, jobID string, req *ZipRequest) (string, error)
 	HealthCheck(ctx context.Context) error
+	// CancelRemoteJob best-effort cancels the remote audio-service job
+	// previously associated with jobID via a tracked response RemoteJobID.
+	// It is a no-op if no remote job is known for jobID.
+	CancelRemoteJob(ctx context.Context, jobID string) error
 }
 
 // AudioClient implements AudioProcessor for the Python microservice
 type AudioClient struct {
 	httpClient *http.Client
 	baseURL    string
+	breaker    *circuitBreaker
+
+	mu         sync.Mutex
+	remoteJobs map[string]string // local jobID -> remote job id, for best-effort cancellation
 }
 
 // MixChannel represents volume settings for a single channel
@@ -53,28 +70,58 @@ type MasterRequest struct {
 
 // MasterResponse represents the response from mastering
 type MasterResponse struct {
-	OutputURL string  `json:"output_url"`
-	Duration  float64 `json:"duration"`
-	PeakDb    float64 `json:"peak_db"`
-	LUFS      float64 `json:"lufs"`
+	OutputURL   string  `json:"output_url"`
+	Duration    float64 `json:"duration"`
+	PeakDb      float64 `json:"peak_db"`
+	LUFS        float64 `json:"lufs"`
+	RemoteJobID string  `json:"remote_job_id,omitempty"`
 }
 
-// EncodeRequest represents the request for audio encoding
+// EncodeRequest represents the request for audio encoding. The
+// CompressionLevel/BedLayout/ObjectCount/Binaural/ObjectAutomationURLs
+// fields only apply to "flac" and "atmos" formats respectively — the audio
+// microservice ignores whichever don't match Format. CoverURL/CoverSize/
+// CoverFormat/AnimatedArtworkURL/EmbedCover apply to "mp3"/"alac"/"flac".
+// Bitrate/VBR/Application/ChannelLayout apply to "opus" and "ogg": see
+// model.ExportJobPayload's doc comment for how they interact. LyricsLRC/
+// LyricsLRCURL apply to "mp3" only, embedded as ID3v2 USLT+SYLT frames.
 type EncodeRequest struct {
-	InputURL   string            `json:"input_url"`
-	Format     string            `json:"format"`
-	Quality    int               `json:"quality,omitempty"`
-	SampleRate int               `json:"sample_rate,omitempty"`
-	BitDepth   int               `json:"bit_depth,omitempty"`
-	Metadata   map[string]string `json:"metadata,omitempty"`
-	OutputKey  string            `json:"output_key"`
+	InputURL             string            `json:"input_url"`
+	Format               string            `json:"format"`
+	Quality              int               `json:"quality,omitempty"`
+	SampleRate           int               `json:"sample_rate,omitempty"`
+	BitDepth             int               `json:"bit_depth,omitempty"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
+	OutputKey            string            `json:"output_key"`
+	CompressionLevel     int               `json:"compression_level,omitempty"`
+	BedLayout            string            `json:"bed_layout,omitempty"`
+	ObjectCount          int               `json:"object_count,omitempty"`
+	Binaural             bool              `json:"binaural,omitempty"`
+	ObjectAutomationURLs []string          `json:"object_automation_urls,omitempty"`
+	EmbedCover           bool              `json:"embed_cover,omitempty"`
+	CoverURL             string            `json:"cover_url,omitempty"`
+	CoverSize            string            `json:"cover_size,omitempty"`
+	CoverFormat          string            `json:"cover_format,omitempty"`
+	AnimatedArtworkURL   string            `json:"animated_artwork_url,omitempty"`
+	Bitrate              int               `json:"bitrate,omitempty"`
+	VBR                  bool              `json:"vbr,omitempty"`
+	Application          string            `json:"application,omitempty"`
+	ChannelLayout        string            `json:"channel_layout,omitempty"`
+	LyricsLRC            string            `json:"lyrics_lrc,omitempty"`
+	LyricsLRCURL         string            `json:"lyrics_lrc_url,omitempty"`
 }
 
-// EncodeResponse represents the response from encoding
+// EncodeResponse represents the response from encoding. CoverEmbedded and
+// AnimatedArtworkEmbedded report back what the microservice actually
+// managed to embed, since a cover/animated-artwork fetch can fail
+// independently of the encode itself.
 type EncodeResponse struct {
-	OutputURL string `json:"output_url"`
-	Format    string `json:"format"`
-	Size      int64  `json:"size"`
+	OutputURL               string `json:"output_url"`
+	Format                  string `json:"format"`
+	Size                    int64  `json:"size"`
+	RemoteJobID             string `json:"remote_job_id,omitempty"`
+	CoverEmbedded           bool   `json:"cover_embedded,omitempty"`
+	AnimatedArtworkEmbedded bool   `json:"animated_artwork_embedded,omitempty"`
 }
 
 // ZipRequest represents the request for creating a ZIP archive
@@ -83,58 +130,161 @@ type ZipRequest struct {
 	OutputKey string         `json:"output_key"`
 }
 
-// ZipFileEntry represents a file to include in the ZIP
+// ZipFileEntry represents a file to include in the ZIP. Exactly one of URL
+// or Content is set: URL for files fetched from object storage, Content for
+// small files assembled in-process (e.g. a derived lyrics.lrc companion)
+// that have no URL of their own.
 type ZipFileEntry struct {
-	URL      string `json:"url"`
+	URL      string `json:"url,omitempty"`
+	Content  string `json:"content,omitempty"`
 	Filename string `json:"filename"`
 }
 
 // ZipResponse represents the response from ZIP creation
 type ZipResponse struct {
-	OutputURL string `json:"output_url"`
-	Size      int64  `json:"size"`
-	FileCount int    `json:"file_count"`
+	OutputURL   string `json:"output_url"`
+	Size        int64  `json:"size"`
+	FileCount   int    `json:"file_count"`
+	RemoteJobID string `json:"remote_job_id,omitempty"`
+}
+
+// StreamZipResponse represents the response from StreamZip: just the URL
+// the audio service is already serving the in-progress archive from.
+type StreamZipResponse struct {
+	StreamURL string `json:"stream_url"`
+}
+
+// StructuredError is returned by the audio service when a batch request
+// (e.g. mastering several stems) only partially succeeds. It lets callers
+// report exactly which items failed and why, instead of aborting the whole
+// job on the first error.
+type StructuredError struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Items   []ItemFailure `json:"items,omitempty"`
+}
+
+// ItemFailure describes why a single item (a stem index, a zip entry, ...)
+// failed within an otherwise-successful batch request.
+type ItemFailure struct {
+	Index  int    `json:"index"`
+	Ref    string `json:"ref"`
+	Reason string `json:"reason"`
+}
+
+func (e *StructuredError) Error() string {
+	return fmt.Sprintf("audio service error (%s): %s", e.Code, e.Message)
 }
 
 // NewAudioClient creates a new audio processing client
 func NewAudioClient(cfg *config.AudioConfig) *AudioClient {
+	breaker := newCircuitBreaker(cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldownMS)*time.Millisecond, audioBreakerState)
+	transportCfg := retryTransportConfig{MaxRetries: cfg.MaxRetries, BaseDelayMS: cfg.BaseDelayMS, MaxDelayMS: cfg.MaxDelayMS}
+
 	return &AudioClient{
 		httpClient: &http.Client{
-			Timeout: time.Duration(cfg.Timeout) * time.Second,
+			Timeout:   time.Duration(cfg.Timeout) * time.Second,
+			Transport: newRetryTransport(transportCfg, breaker, ErrAudioServiceUnavailable, audioRetriesTotal),
 		},
-		baseURL: cfg.ServiceURL,
+		baseURL:    cfg.ServiceURL,
+		breaker:    breaker,
+		remoteJobs: make(map[string]string),
 	}
 }
 
 // Master sends audio to the mastering endpoint
-func (c *AudioClient) Master(ctx context.Context, req *MasterRequest) (*MasterResponse, error) {
+func (c *AudioClient) Master(ctx context.Context, jobID string, req *MasterRequest) (*MasterResponse, error) {
 	var result MasterResponse
-	if err := c.post(ctx, "/master", req, &result); err != nil {
+	if err := c.post(ctx, jobID, "/master", req, &result); err != nil {
 		return nil, err
 	}
+	c.trackRemoteJob(jobID, result.RemoteJobID)
 	return &result, nil
 }
 
 // Encode sends audio to the encoding endpoint
-func (c *AudioClient) Encode(ctx context.Context, req *EncodeRequest) (*EncodeResponse, error) {
+func (c *AudioClient) Encode(ctx context.Context, jobID string, req *EncodeRequest) (*EncodeResponse, error) {
 	var result EncodeResponse
-	if err := c.post(ctx, "/encode", req, &result); err != nil {
+	if err := c.post(ctx, jobID, "/encode", req, &result); err != nil {
 		return nil, err
 	}
+	c.trackRemoteJob(jobID, result.RemoteJobID)
 	return &result, nil
 }
 
 // CreateZip creates a ZIP archive from multiple files
-func (c *AudioClient) CreateZip(ctx context.Context, req *ZipRequest) (*ZipResponse, error) {
+func (c *AudioClient) CreateZip(ctx context.Context, jobID string, req *ZipRequest) (*ZipResponse, error) {
 	var result ZipResponse
-	if err := c.post(ctx, "/zip", req, &result); err != nil {
+	if err := c.post(ctx, jobID, "/zip", req, &result); err != nil {
 		return nil, err
 	}
+	c.trackRemoteJob(jobID, result.RemoteJobID)
 	return &result, nil
 }
 
-// HealthCheck checks if the audio service is available
+// StreamZip asks the audio service to start streaming req's archive and
+// returns the URL it's serving that stream from.
+func (c *AudioClient) StreamZip(ctx context.Context, jobID string, req *ZipRequest) (string, error) {
+	var result StreamZipResponse
+	if err := c.post(ctx, jobID, "/zip/stream", req, &result); err != nil {
+		return "", err
+	}
+	return result.StreamURL, nil
+}
+
+// CancelRemoteJob best-effort cancels the remote audio-service job tracked
+// for jobID. It is a no-op if the local job never received a remote job id
+// (e.g. it was canceled before the audio service accepted it).
+func (c *AudioClient) CancelRemoteJob(ctx context.Context, jobID string) error {
+	c.mu.Lock()
+	remoteID, ok := c.remoteJobs[jobID]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+"/jobs/"+remoteID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cancel request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to cancel remote job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// idempotencyKey derives a stable key for a request from the job it belongs
+// to, the endpoint being called, and a hash of the payload, so identical
+// retries always carry the same key.
+func idempotencyKey(jobID, endpoint string, payload []byte) string {
+	h := sha256.New()
+	h.Write([]byte(jobID))
+	h.Write([]byte(endpoint))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *AudioClient) trackRemoteJob(jobID, remoteID string) {
+	if remoteID == "" {
+		return
+	}
+	c.mu.Lock()
+	c.remoteJobs[jobID] = remoteID
+	c.mu.Unlock()
+}
+
+// HealthCheck checks if the audio service is available. It reports the
+// circuit breaker as unavailable without making a request, so the gateway
+// can drain traffic while the breaker is open.
 func (c *AudioClient) HealthCheck(ctx context.Context) error {
+	if c.breaker.isOpen() {
+		return ErrAudioServiceUnavailable
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -153,8 +303,10 @@ func (c *AudioClient) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// post sends a POST request with JSON body and parses the response
-func (c *AudioClient) post(ctx context.Context, endpoint string, body interface{}, result interface{}) error {
+// post sends a POST request with JSON body and parses the response. jobID is
+// mixed into the Idempotency-Key header so retries (ours or the audio
+// service's own) don't produce duplicate outputs.
+func (c *AudioClient) post(ctx context.Context, jobID, endpoint string, body interface{}, result interface{}) error {
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
@@ -166,6 +318,7 @@ func (c *AudioClient) post(ctx context.Context, endpoint string, body interface{
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey(jobID, endpoint, bodyBytes))
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -179,6 +332,10 @@ func (c *AudioClient) post(ctx context.Context, endpoint string, body interface{
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var structured StructuredError
+		if jsonErr := json.Unmarshal(respBody, &structured); jsonErr == nil && structured.Code != "" {
+			return &structured
+		}
 		return fmt.Errorf("audio service error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
@@ -193,3 +350,12 @@ func (c *AudioClient) post(ctx context.Context, endpoint string, body interface{
 func (c *AudioClient) IsConfigured() bool {
 	return c.baseURL != ""
 }
+
+// BreakerCooldownRemaining returns how much longer the audio microservice's
+// breaker has left before it allows a half-open probe, or 0 if it isn't
+// open. The master/export workers share this client and use it the same
+// way the render worker uses SunoClient.BreakerCooldownRemaining: to pace
+// their queues' retries to the breaker's own clock.
+func (c *AudioClient) BreakerCooldownRemaining() time.Duration {
+	return c.breaker.cooldownRemaining()
+}
@@ -0,0 +1,307 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/makeasinger/api/internal/config"
+)
+
+// FilesystemClient implements StorageClient against the local filesystem,
+// for self-hosters who want persistent object storage without standing up
+// an S3-compatible service. Objects are written under Root, keeping the
+// same key structure (vocals/<project>/<section>/<id>.wav, etc.) the S3
+// backends use. GetSignedURL/PresignPutURL mint HMAC-signed query strings
+// over /files/<key> instead of relying on a storage provider's own
+// signature scheme; middleware.VerifySignedFileURL checks them.
+type FilesystemClient struct {
+	root          string
+	publicURL     string
+	signingSecret string
+}
+
+// NewFilesystemClient creates a FilesystemClient rooted at cfg.LocalRoot,
+// creating it if it doesn't exist yet.
+func NewFilesystemClient(cfg *config.StorageConfig) (*FilesystemClient, error) {
+	if cfg.LocalRoot == "" {
+		return nil, fmt.Errorf("storage: fs provider requires local_root")
+	}
+	if cfg.SigningSecret == "" {
+		return nil, fmt.Errorf("storage: fs provider requires signing_secret")
+	}
+	if err := os.MkdirAll(cfg.LocalRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	return &FilesystemClient{
+		root:          cfg.LocalRoot,
+		publicURL:     strings.TrimSuffix(cfg.PublicURL, "/"),
+		signingSecret: cfg.SigningSecret,
+	}, nil
+}
+
+// pathFor resolves key to a path under Root, rejecting any key that would
+// escape it via "..".
+func (c *FilesystemClient) pathFor(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	full := filepath.Join(c.root, clean)
+	if !strings.HasPrefix(full, filepath.Clean(c.root)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("storage: key %q escapes storage root", key)
+	}
+	return full, nil
+}
+
+func (c *FilesystemClient) Upload(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	return c.PutObjectStream(ctx, key, body, contentType)
+}
+
+// PutObjectStream writes body to key. contentType is unused -- the
+// filesystem has no notion of it; the signed-URL middleware and the
+// eventual consumer infer it from the file extension the same way any
+// static file server would.
+func (c *FilesystemClient) PutObjectStream(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	path, err := c.pathFor(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create storage file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		return "", fmt.Errorf("failed to write storage file: %w", err)
+	}
+	return c.GetPublicURL(key), nil
+}
+
+// Exists reports whether key already exists under Root.
+func (c *FilesystemClient) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := c.pathFor(key)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat storage file: %w", err)
+	}
+	return true, nil
+}
+
+// StatObject returns key's size via os.Stat. ETag is always empty -- the
+// filesystem backend has no content-hash-on-write step to derive one from,
+// so callers confirming a direct upload against this backend can only
+// compare Size, not ETag.
+func (c *FilesystemClient) StatObject(ctx context.Context, key string) (ObjectInfo, error) {
+	path, err := c.pathFor(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ObjectInfo{}, ErrObjectNotFound
+		}
+		return ObjectInfo{}, fmt.Errorf("failed to stat storage file: %w", err)
+	}
+	return ObjectInfo{Size: info.Size()}, nil
+}
+
+func (c *FilesystemClient) Delete(ctx context.Context, key string) error {
+	path, err := c.pathFor(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete storage file: %w", err)
+	}
+	return nil
+}
+
+// GetSignedURL mints a short-lived HMAC-signed GET URL for key, verified by
+// middleware.VerifySignedFileURL in front of the /files static mount.
+func (c *FilesystemClient) GetSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return c.sign(key, "GET", expiry), nil
+}
+
+func (c *FilesystemClient) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return c.GetSignedURL(ctx, key, ttl)
+}
+
+// PresignPutURL mints a short-lived HMAC-signed PUT URL for key, verified
+// by the same middleware guarding the /files PUT route that writes the
+// request body to local disk.
+func (c *FilesystemClient) PresignPutURL(ctx context.Context, key, contentType string, ttl time.Duration) (string, error) {
+	return c.sign(key, "PUT", ttl), nil
+}
+
+func (c *FilesystemClient) sign(key, method string, ttl time.Duration) string {
+	exp := time.Now().Add(ttl).Unix()
+	sig := SignFileURL(c.signingSecret, method, key, exp)
+	return fmt.Sprintf("%s/files/%s?exp=%d&sig=%s", c.publicURL, key, exp, sig)
+}
+
+// SignFileURL computes the HMAC-SHA256 signature middleware.VerifySignedFileURL
+// checks, over method+key+exp. Exported so that package is the single
+// source of truth for both sides of the signature.
+func SignFileURL(secret, method, key string, exp int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + key + "\n" + strconv.FormatInt(exp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *FilesystemClient) GetPublicURL(key string) string {
+	return fmt.Sprintf("%s/files/%s", c.publicURL, key)
+}
+
+func (c *FilesystemClient) KeyFromURL(url string) string {
+	return strings.TrimPrefix(url, c.publicURL+"/files/")
+}
+
+// Copy performs a local copy. dstBucket is treated as a subdirectory under
+// Root rather than a separate bucket, since the filesystem backend only
+// ever has one root -- callers archiving into a "cold bucket" end up with
+// <root>/<dstBucket>/<dstKey> instead of a genuinely separate store.
+func (c *FilesystemClient) Copy(ctx context.Context, srcKey, dstBucket, dstKey string) error {
+	srcPath, err := c.pathFor(srcKey)
+	if err != nil {
+		return err
+	}
+	dstPath, err := c.pathFor(filepath.Join(dstBucket, dstKey))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy storage file: %w", err)
+	}
+	return nil
+}
+
+// multipartDir is where an in-progress multipart upload's parts are spooled
+// until CompleteMultipartUpload assembles them.
+func (c *FilesystemClient) multipartDir(uploadID string) (string, error) {
+	return c.pathFor(filepath.Join(".multipart", uploadID))
+}
+
+// CreateMultipartUpload starts a local multipart upload: uploadID is just a
+// directory name under .multipart, since there's no S3 API call to make.
+func (c *FilesystemClient) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	uploadID := hex.EncodeToString([]byte(key))[:16] + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	dir, err := c.multipartDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create multipart upload directory: %w", err)
+	}
+	return uploadID, nil
+}
+
+// UploadPart writes one part to its own file under the upload's spool
+// directory. The returned "ETag" is just the part number restated as a
+// string -- there's no content-addressed verification to do locally, and
+// CompleteMultipartUpload only needs PartNumber to order the parts anyway.
+func (c *FilesystemClient) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader) (string, error) {
+	dir, err := c.multipartDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+	partPath := filepath.Join(dir, fmt.Sprintf("%010d", partNumber))
+	f, err := os.Create(partPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create part file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		return "", fmt.Errorf("failed to write part file: %w", err)
+	}
+	return strconv.Itoa(int(partNumber)), nil
+}
+
+// CompleteMultipartUpload concatenates every spooled part (in PartNumber
+// order) into key and removes the spool directory.
+func (c *FilesystemClient) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) (string, error) {
+	dir, err := c.multipartDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	path, err := c.pathFor(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create storage file: %w", err)
+	}
+	defer out.Close()
+
+	for _, p := range parts {
+		partPath := filepath.Join(dir, fmt.Sprintf("%010d", p.PartNumber))
+		in, err := os.Open(partPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to open part %d: %w", p.PartNumber, err)
+		}
+		_, copyErr := io.Copy(out, in)
+		in.Close()
+		if copyErr != nil {
+			return "", fmt.Errorf("failed to assemble part %d: %w", p.PartNumber, copyErr)
+		}
+	}
+
+	return c.GetPublicURL(key), nil
+}
+
+// AbortMultipartUpload discards a local multipart upload's spooled parts.
+func (c *FilesystemClient) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	dir, err := c.multipartDir(uploadID)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove multipart upload directory: %w", err)
+	}
+	return nil
+}
+
+// IsConfigured returns true if the client has a usable root directory.
+func (c *FilesystemClient) IsConfigured() bool {
+	return c.root != ""
+}
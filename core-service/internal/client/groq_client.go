@@ -9,15 +9,31 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
 	"github.com/makeasinger/api/internal/config"
 )
 
+var (
+	groqRequestDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "groq_client_request_duration_seconds",
+		Help:    "Latency of Groq chat completion requests.",
+		Buckets: prometheus.DefBuckets,
+	})
+	groqTokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "groq_client_tokens_total",
+		Help: "Total Groq tokens consumed, by kind (prompt, completion) and model.",
+	}, []string{"kind", "model"})
+)
+
 // GroqClient handles communication with Groq API
 type GroqClient struct {
 	httpClient *http.Client
 	baseURL    string
 	apiKey     string
 	model      string
+	breaker    *circuitBreaker
 }
 
 // ChatMessage represents a message in the chat completion request
@@ -55,20 +71,32 @@ type ChatCompletionResponse struct {
 	} `json:"usage"`
 }
 
-// NewGroqClient creates a new Groq API client
+// NewGroqClient creates a new Groq API client. Requests retry with jittered
+// exponential backoff on 429/5xx and network errors (honoring Retry-After),
+// short-circuiting via a circuit breaker once Groq looks consistently down,
+// the same way AudioClient and SunoClient do against their own upstreams.
 func NewGroqClient(cfg *config.GroqConfig) *GroqClient {
+	breaker := newCircuitBreaker(cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldownMS)*time.Millisecond, groqBreakerState)
+	transportCfg := retryTransportConfig{MaxRetries: cfg.MaxRetries, BaseDelayMS: cfg.BaseDelayMS, MaxDelayMS: cfg.MaxDelayMS}
+
 	return &GroqClient{
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   time.Duration(cfg.Timeout) * time.Second,
+			Transport: newRetryTransport(transportCfg, breaker, ErrGroqUnavailable, groqRetriesTotal),
 		},
 		baseURL: cfg.BaseURL,
 		apiKey:  cfg.APIKey,
 		model:   cfg.Model,
+		breaker: breaker,
 	}
 }
 
-// ChatCompletion sends a chat completion request to Groq
+// ChatCompletion sends a chat completion request to Groq, recording its
+// latency and the prompt/completion token counts Groq reports back.
 func (c *GroqClient) ChatCompletion(ctx context.Context, system, user string) (string, error) {
+	start := time.Now()
+	defer func() { groqRequestDuration.Observe(time.Since(start).Seconds()) }()
+
 	messages := []ChatMessage{
 		{Role: "system", Content: system},
 		{Role: "user", Content: user},
@@ -118,6 +146,9 @@ func (c *GroqClient) ChatCompletion(ctx context.Context, system, user string) (s
 		return "", fmt.Errorf("no choices in response")
 	}
 
+	groqTokensTotal.WithLabelValues("prompt", c.model).Add(float64(chatResp.Usage.PromptTokens))
+	groqTokensTotal.WithLabelValues("completion", c.model).Add(float64(chatResp.Usage.CompletionTokens))
+
 	return chatResp.Choices[0].Message.Content, nil
 }
 
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// PollState is the tri-state outcome of a single poll attempt: the awaited
+// work is still running, has finished, or has failed terminally.
+type PollState int
+
+const (
+	PollPending PollState = iota
+	PollDone
+	PollFailed
+)
+
+// PollOptions tunes the backoff schedule a Poll call uses between attempts.
+type PollOptions struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64 // how much the interval grows after each attempt, e.g. 1.5
+	JitterRatio     float64 // fraction of the interval (0-1) randomized on top
+	MaxWait         time.Duration
+}
+
+// PollProgress is passed to Poll's optional onAttempt callback after each
+// attempt, so callers can surface poll health (e.g. onto a job's
+// CurrentStep) without Poll needing to know about their domain model.
+type PollProgress struct {
+	Attempt      int
+	NextInterval time.Duration
+}
+
+// Poll repeatedly calls fn until it reports PollDone or PollFailed, ctx is
+// canceled, or MaxWait elapses, backing off between attempts per opts. It
+// replaces the near-duplicate fixed-interval loops that used to live on
+// SunoClient (PollMusicStatus, PollStemSplitStatus), adding jitter so many
+// concurrent pollers don't all hit the upstream in lockstep.
+func Poll[T any](ctx context.Context, fn func(ctx context.Context) (T, PollState, error), opts PollOptions, onAttempt func(PollProgress)) (T, error) {
+	var zero T
+	deadline := time.Now().Add(opts.MaxWait)
+	interval := opts.InitialInterval
+	attempt := 0
+
+	for {
+		attempt++
+		result, state, err := fn(ctx)
+		if err != nil {
+			return zero, err
+		}
+
+		switch state {
+		case PollDone:
+			return result, nil
+		case PollFailed:
+			return zero, fmt.Errorf("poll attempt %d: operation failed", attempt)
+		}
+
+		if !time.Now().Before(deadline) {
+			return zero, fmt.Errorf("poll timed out after %v (%d attempts)", opts.MaxWait, attempt)
+		}
+
+		wait := withJitter(interval, opts.JitterRatio)
+		if onAttempt != nil {
+			onAttempt(PollProgress{Attempt: attempt, NextInterval: wait})
+		}
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		interval = time.Duration(math.Min(float64(opts.MaxInterval), float64(interval)*opts.Multiplier))
+	}
+}
+
+// withJitter adds up to ratio*d of additional random delay on top of d.
+func withJitter(d time.Duration, ratio float64) time.Duration {
+	if ratio <= 0 || d <= 0 {
+		return d
+	}
+	jitter := time.Duration(rand.Int63n(int64(float64(d) * ratio)))
+	return d + jitter
+}
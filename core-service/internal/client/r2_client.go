@@ -2,14 +2,20 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/makeasinger/api/internal/config"
 )
 
@@ -19,9 +25,83 @@ type StorageClient interface {
 	Delete(ctx context.Context, key string) error
 	GetSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
 	GetPublicURL(key string) string
+	// Copy server-side copies an object from srcKey in the default bucket to
+	// dstKey in dstBucket (e.g. moving a finished job's output to cold storage).
+	Copy(ctx context.Context, srcKey, dstBucket, dstKey string) error
+	// KeyFromURL recovers the object key from a URL previously returned by
+	// GetPublicURL, for callers that only persisted the public URL.
+	KeyFromURL(url string) string
+	// PutObjectStream streams body into key as a multipart upload, so large
+	// objects (stems, masters) don't need to be buffered in memory and a
+	// single part failure doesn't restart the whole transfer. Returns the
+	// public URL on success, the same as Upload.
+	PutObjectStream(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	// PresignGetURL is GetSignedURL under the name the rest of this chunk's
+	// callers (export/render) use; kept as a separate method rather than a
+	// rename so existing callers of GetSignedURL don't need to change.
+	PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// PresignPutURL generates a presigned PUT URL for key valid for ttl, so a
+	// client can upload directly to the bucket without routing the body
+	// through this process (and its Fiber body-size limit).
+	PresignPutURL(ctx context.Context, key, contentType string, ttl time.Duration) (string, error)
+	// CreateMultipartUpload starts an S3 multipart upload for key and returns
+	// the upload ID parts are appended against.
+	CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error)
+	// UploadPart uploads one part of an in-progress multipart upload and
+	// returns its ETag, which must be passed back to CompleteMultipartUpload.
+	// Every part but the last must be at least 5 MiB, an S3 constraint.
+	UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader) (string, error)
+	// CompleteMultipartUpload assembles the uploaded parts into the final
+	// object and returns its public URL.
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) (string, error)
+	// AbortMultipartUpload discards an in-progress multipart upload and its
+	// uploaded parts, for abandoned or failed resumable uploads.
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+	// Exists reports whether an object already lives at key, so a caller
+	// doing content-addressed storage can skip re-uploading bytes it
+	// already has a copy of.
+	Exists(ctx context.Context, key string) (bool, error)
+	// StatObject returns the size and ETag of the object at key, for a
+	// caller confirming a direct-to-storage upload actually landed (e.g.
+	// after handing out a presigned PUT URL) without transferring its body.
+	// Returns ErrObjectNotFound if key doesn't exist.
+	StatObject(ctx context.Context, key string) (ObjectInfo, error)
 }
 
-// R2Client implements StorageClient for Cloudflare R2
+// ObjectInfo is the subset of HeadObject's response callers confirming a
+// direct upload need.
+type ObjectInfo struct {
+	Size int64
+	ETag string
+}
+
+// ErrObjectNotFound is returned by StatObject when key doesn't exist.
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+// CompletedPart identifies one uploaded part for CompleteMultipartUpload,
+// mirroring the subset of s3.types.CompletedPart callers outside this
+// package need without importing the AWS SDK themselves.
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// partSize is the size of each multipart upload part. 8 MiB sits in the
+// 8-16 MiB range S3-compatible providers (R2, MinIO, AWS) all accept without
+// tuning, and is large enough that a typical stem/master upload needs only a
+// handful of parts.
+const partSize = 8 * 1024 * 1024
+
+// uploadConcurrency is how many parts PutObjectStream uploads in parallel.
+const uploadConcurrency = 4
+
+// maxPartRetries is how many times PutObjectStream retries the whole upload
+// (with exponential backoff) after a part fails before giving up.
+const maxPartRetries = 3
+
+// R2Client implements StorageClient against any S3-compatible object store
+// (Cloudflare R2, AWS S3, MinIO, GCS's S3 interop endpoint), selected via
+// config.StorageConfig.Provider at construction time.
 type R2Client struct {
 	s3Client   *s3.Client
 	presigner  *s3.PresignClient
@@ -29,34 +109,80 @@ type R2Client struct {
 	publicURL  string
 }
 
-// NewR2Client creates a new R2 storage client
-func NewR2Client(cfg *config.R2Config) (*R2Client, error) {
-	if cfg.AccountID == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
-		return nil, fmt.Errorf("R2 configuration incomplete")
+// storageEndpoint resolves cfg into the endpoint URL and region the AWS SDK
+// should target, and whether path-style addressing is required. "r2" is
+// special-cased because its endpoint is derived from AccountID rather than
+// being configured directly; every other provider ("s3", "minio", "gcs")
+// is just an S3-compatible host taken from cfg.Endpoint/Region/PathStyle,
+// which is what lets self-hosters point this at MinIO or GCS's S3
+// interoperability endpoint without any provider-specific code.
+func storageEndpoint(cfg *config.StorageConfig) (endpoint, region string, pathStyle bool, err error) {
+	switch cfg.Provider {
+	case "", "r2":
+		if cfg.AccountID == "" {
+			return "", "", false, fmt.Errorf("storage: r2 requires account_id")
+		}
+		return fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID), "auto", false, nil
+	case "s3":
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return cfg.Endpoint, region, cfg.PathStyle, nil
+	case "minio", "gcs":
+		if cfg.Endpoint == "" {
+			return "", "", false, fmt.Errorf("storage: provider %q requires endpoint", cfg.Provider)
+		}
+		scheme := "https"
+		if !cfg.UseSSL {
+			scheme = "http"
+		}
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return fmt.Sprintf("%s://%s", scheme, cfg.Endpoint), region, cfg.PathStyle, nil
+	default:
+		return "", "", false, fmt.Errorf("storage: unknown provider %q", cfg.Provider)
 	}
+}
 
-	endpoint := fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID)
+// NewR2Client creates an S3-compatible object storage client. Despite the
+// name (kept for the sake of the many existing call sites and the
+// StorageClient interface it satisfies), cfg.Provider selects the actual
+// backend -- Cloudflare R2, AWS S3, MinIO, or GCS's S3 interop endpoint.
+func NewR2Client(cfg *config.StorageConfig) (*R2Client, error) {
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("storage configuration incomplete")
+	}
 
-	r2Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+	endpoint, region, pathStyle, err := storageEndpoint(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
 		return aws.Endpoint{
 			URL: endpoint,
 		}, nil
 	})
 
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
-		awsconfig.WithEndpointResolverWithOptions(r2Resolver),
+		awsconfig.WithEndpointResolverWithOptions(resolver),
 		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AccessKeyID,
 			cfg.SecretAccessKey,
 			"",
 		)),
-		awsconfig.WithRegion("auto"),
+		awsconfig.WithRegion(region),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	s3Client := s3.NewFromConfig(awsCfg)
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = pathStyle
+	})
 	presigner := s3.NewPresignClient(s3Client)
 
 	return &R2Client{
@@ -84,6 +210,182 @@ func (c *R2Client) Upload(ctx context.Context, key string, body io.Reader, conte
 	return c.GetPublicURL(key), nil
 }
 
+// PutObjectStream uploads body to key via an S3-compatible multipart upload
+// (manager.Uploader splits it into partSize chunks and sends uploadConcurrency
+// of them in parallel), so a multi-hundred-MB stem or master doesn't need to
+// be buffered whole and a single part failure doesn't restart the transfer.
+// The assembled object's integrity is verified server-side with a SHA-256
+// checksum; the whole upload is retried with exponential backoff up to
+// maxPartRetries times if a part fails after the SDK's own per-part retries
+// are exhausted.
+func (c *R2Client) PutObjectStream(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	uploader := manager.NewUploader(c.s3Client, func(u *manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = uploadConcurrency
+	})
+
+	var lastErr error
+	for attempt := 0; attempt <= maxPartRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+			jitter := time.Duration(rand.Int63n(int64(time.Second)))
+			time.Sleep(backoff + jitter)
+		}
+
+		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket:            aws.String(c.bucketName),
+			Key:               aws.String(key),
+			Body:              body,
+			ContentType:       aws.String(contentType),
+			ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+		})
+		if err == nil {
+			return c.GetPublicURL(key), nil
+		}
+		lastErr = err
+
+		// body may have been partially consumed by the failed attempt; a
+		// non-seekable reader can't be retried safely, so give up rather
+		// than upload a truncated/corrupt object.
+		seeker, ok := body.(io.Seeker)
+		if !ok {
+			break
+		}
+		if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+			break
+		}
+	}
+
+	return "", fmt.Errorf("failed to upload %q to R2 after retries: %w", key, lastErr)
+}
+
+// PresignGetURL generates a presigned GET URL for key valid for ttl. It's
+// the same operation as GetSignedURL; export/render call sites use this
+// name to read as "presign a download" rather than "sign some URL".
+func (c *R2Client) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return c.GetSignedURL(ctx, key, ttl)
+}
+
+// PresignPutURL generates a presigned PUT URL for key valid for ttl. The
+// caller must send the request with the same Content-Type, or the signature
+// won't match and S3 will reject the upload.
+func (c *R2Client) PresignPutURL(ctx context.Context, key, contentType string, ttl time.Duration) (string, error) {
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}
+
+	presignedReq, err := c.presigner.PresignPutObject(ctx, input, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned upload URL: %w", err)
+	}
+
+	return presignedReq.URL, nil
+}
+
+// CreateMultipartUpload starts an S3 multipart upload for key and returns
+// the upload ID subsequent UploadPart/CompleteMultipartUpload calls need.
+func (c *R2Client) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	out, err := c.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(c.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// UploadPart uploads one part of an in-progress multipart upload.
+func (c *R2Client) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader) (string, error) {
+	out, err := c.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(c.bucketName),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(partNumber),
+		Body:       body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+// CompleteMultipartUpload assembles parts (which must be in ascending
+// PartNumber order) into the final object at key.
+func (c *R2Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) (string, error) {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{PartNumber: aws.Int32(p.PartNumber), ETag: aws.String(p.ETag)}
+	}
+
+	_, err := c.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(c.bucketName),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return c.GetPublicURL(key), nil
+}
+
+// AbortMultipartUpload discards an in-progress multipart upload and its
+// already-uploaded parts.
+func (c *R2Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.bucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether key already exists in the bucket via HeadObject,
+// without transferring its body.
+func (c *R2Client) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head object %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// StatObject returns key's size and ETag via HeadObject, without
+// transferring its body.
+func (c *R2Client) StatObject(ctx context.Context, key string) (ObjectInfo, error) {
+	out, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &notFound) || errors.As(err, &noSuchKey) {
+			return ObjectInfo{}, ErrObjectNotFound
+		}
+		return ObjectInfo{}, fmt.Errorf("failed to head object %q: %w", key, err)
+	}
+	return ObjectInfo{Size: aws.ToInt64(out.ContentLength), ETag: strings.Trim(aws.ToString(out.ETag), `"`)}, nil
+}
+
 // Delete removes a file from R2
 func (c *R2Client) Delete(ctx context.Context, key string) error {
 	input := &s3.DeleteObjectInput{
@@ -114,6 +416,24 @@ func (c *R2Client) GetSignedURL(ctx context.Context, key string, expiry time.Dur
 	return presignedReq.URL, nil
 }
 
+// Copy performs a server-side copy of an object into another bucket without
+// downloading it through this process, e.g. archiving a completed job's
+// output from the hot working bucket into a cold long-term bucket.
+func (c *R2Client) Copy(ctx context.Context, srcKey, dstBucket, dstKey string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(fmt.Sprintf("%s/%s", c.bucketName, srcKey)),
+	}
+
+	_, err := c.s3Client.CopyObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to copy object in R2: %w", err)
+	}
+
+	return nil
+}
+
 // GetPublicURL returns the public CDN URL for a key
 func (c *R2Client) GetPublicURL(key string) string {
 	if c.publicURL != "" {
@@ -122,6 +442,16 @@ func (c *R2Client) GetPublicURL(key string) string {
 	return fmt.Sprintf("https://%s.r2.cloudflarestorage.com/%s", c.bucketName, key)
 }
 
+// KeyFromURL strips whichever prefix GetPublicURL would have added, the
+// inverse of that method.
+func (c *R2Client) KeyFromURL(url string) string {
+	prefix := fmt.Sprintf("https://%s.r2.cloudflarestorage.com/", c.bucketName)
+	if c.publicURL != "" {
+		prefix = c.publicURL + "/"
+	}
+	return strings.TrimPrefix(url, prefix)
+}
+
 // IsConfigured returns true if the client has valid configuration
 func (c *R2Client) IsConfigured() bool {
 	return c.s3Client != nil && c.bucketName != ""
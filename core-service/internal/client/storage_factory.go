@@ -0,0 +1,30 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/makeasinger/api/internal/config"
+)
+
+// NewStorageClient selects and constructs the StorageClient backend named
+// by cfg.Provider, so callers depend on the interface instead of wiring
+// *R2Client/*FilesystemClient by hand. Returns (nil, nil) -- not an error --
+// when the chosen backend's required config is missing, matching the
+// existing "fall back to mock storage" convention callers already expect
+// from a nil StorageClient.
+func NewStorageClient(cfg *config.StorageConfig) (StorageClient, error) {
+	switch cfg.Provider {
+	case "fs":
+		if cfg.LocalRoot == "" || cfg.SigningSecret == "" {
+			return nil, nil
+		}
+		return NewFilesystemClient(cfg)
+	case "", "r2", "s3", "minio", "gcs":
+		if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, nil
+		}
+		return NewR2Client(cfg)
+	default:
+		return nil, fmt.Errorf("storage: unknown provider %q", cfg.Provider)
+	}
+}
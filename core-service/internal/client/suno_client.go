@@ -6,11 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/makeasinger/api/internal/config"
+	"github.com/makeasinger/api/internal/log"
 )
 
 // MusicGenerator defines the interface for music generation operations
@@ -26,6 +26,7 @@ type SunoClient struct {
 	httpClient *http.Client
 	baseURL    string
 	apiKey     string
+	breaker    *circuitBreaker
 }
 
 // GenerateMusicRequest represents the request for music generation
@@ -76,12 +77,17 @@ type Stem struct {
 
 // NewSunoClient creates a new Suno API client
 func NewSunoClient(cfg *config.SunoConfig) *SunoClient {
+	breaker := newCircuitBreaker(cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldownMS)*time.Millisecond, sunoBreakerState)
+	transportCfg := retryTransportConfig{MaxRetries: cfg.MaxRetries, BaseDelayMS: cfg.BaseDelayMS, MaxDelayMS: cfg.MaxDelayMS}
+
 	return &SunoClient{
 		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
+			Timeout:   120 * time.Second,
+			Transport: newRetryTransport(transportCfg, breaker, ErrSunoUnavailable, sunoRetriesTotal),
 		},
 		baseURL: cfg.BaseURL,
 		apiKey:  cfg.APIKey,
+		breaker: breaker,
 	}
 }
 
@@ -171,32 +177,34 @@ func (c *SunoClient) get(ctx context.Context, endpoint string, result interface{
 
 // doRequest executes an HTTP request and parses the response
 func (c *SunoClient) doRequest(req *http.Request, result interface{}) error {
+	ctx := req.Context()
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 
-	log.Printf("[Suno API] → %s %s", req.Method, req.URL.String())
+	log.Info(ctx, "suno request", "method", req.Method, "url", req.URL.String())
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Printf("[Suno API] ✗ %s %s — request failed: %v", req.Method, req.URL.String(), err)
+		log.Error(ctx, "suno request failed", "method", req.Method, "url", req.URL.String(), "err", err)
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("[Suno API] ✗ %s %s — failed to read response: %v", req.Method, req.URL.String(), err)
+		log.Error(ctx, "suno response read failed", "method", req.Method, "url", req.URL.String(), "err", err)
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	log.Printf("[Suno API] ← %d %s %s — %s", resp.StatusCode, req.Method, req.URL.String(), string(respBody))
+	log.Info(ctx, "suno response", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Error(ctx, "suno API error", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "body", string(respBody))
 		return fmt.Errorf("suno API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
 	if err := json.Unmarshal(respBody, result); err != nil {
-		log.Printf("[Suno API] ✗ unmarshal error for %s %s: %v (body: %s)", req.Method, req.URL.String(), err, string(respBody))
+		log.Error(ctx, "suno response unmarshal failed", "method", req.Method, "url", req.URL.String(), "err", err, "body", string(respBody))
 		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -208,70 +216,88 @@ func (c *SunoClient) IsConfigured() bool {
 	return c.apiKey != ""
 }
 
-// PollMusicStatus polls for music generation completion
-func (c *SunoClient) PollMusicStatus(ctx context.Context, taskID string, interval time.Duration, maxWait time.Duration) (*MusicResult, error) {
-	deadline := time.Now().Add(maxWait)
-	attempt := 0
+// BreakerCooldownRemaining returns how much longer the Suno breaker has left
+// before it allows a half-open probe, or 0 if it isn't open. The render
+// worker's queue uses this to pace its own retries to the breaker's clock
+// instead of asynq's default backoff, so a run of failures doesn't just
+// refill the queue with retries that are certain to trip the breaker again.
+func (c *SunoClient) BreakerCooldownRemaining() time.Duration {
+	return c.breaker.cooldownRemaining()
+}
 
-	for time.Now().Before(deadline) {
-		attempt++
-		result, err := c.GetMusicStatus(ctx, taskID)
-		if err != nil {
-			log.Printf("[Suno API] Poll music #%d (task=%s) — error: %v", attempt, taskID, err)
-			return nil, err
-		}
+// defaultPollOptions returns the backoff schedule shared by PollMusicStatus
+// and PollStemSplitStatus, parameterized only by maxWait since the two polls
+// differ solely in how long they're willing to wait.
+func defaultPollOptions(maxWait time.Duration) PollOptions {
+	return PollOptions{
+		InitialInterval: 3 * time.Second,
+		MaxInterval:     20 * time.Second,
+		Multiplier:      1.5,
+		JitterRatio:     0.2,
+		MaxWait:         maxWait,
+	}
+}
 
-		log.Printf("[Suno API] Poll music #%d (task=%s) — status: %s", attempt, taskID, result.Status)
+// pollStateForStatus adapts the Suno "completed/failed/pending" status
+// strings in MusicResult/StemSplitResult into the PollState the generic Poll
+// helper understands.
+func pollStateForStatus(status string) PollState {
+	switch status {
+	case "completed", "success":
+		return PollDone
+	case "failed", "error":
+		return PollFailed
+	default:
+		return PollPending
+	}
+}
 
-		switch result.Status {
-		case "completed", "success":
-			return result, nil
-		case "failed", "error":
-			return nil, fmt.Errorf("music generation failed: %s", result.Status)
+// onPollAttempt returns Poll's per-attempt callback: it logs each attempt
+// with the current breaker state and passes reportStep (when non-nil) a
+// human-readable step description (e.g. for RenderStatusResponse.CurrentStep)
+// so a UI can explain why a job is taking a while instead of looking stuck.
+func (c *SunoClient) onPollAttempt(ctx context.Context, op, taskID string, reportStep func(string)) func(PollProgress) {
+	return func(p PollProgress) {
+		state, failures := c.breaker.snapshot()
+		log.Info(ctx, "suno poll "+op, "taskId", taskID, "attempt", p.Attempt, "breakerState", state.String(), "consecutiveFailures", failures)
+		if reportStep != nil {
+			reportStep(fmt.Sprintf("waiting_for_suno (attempt %d, backoff %v)", p.Attempt, p.NextInterval.Round(time.Second)))
 		}
+	}
+}
 
-		select {
-		case <-ctx.Done():
-			log.Printf("[Suno API] Poll music (task=%s) — context cancelled", taskID)
-			return nil, ctx.Err()
-		case <-time.After(interval):
-			continue
+// PollMusicStatus polls for music generation completion. reportStep, if
+// non-nil, is called after each attempt with a CurrentStep-style description
+// of poll progress.
+func (c *SunoClient) PollMusicStatus(ctx context.Context, taskID string, maxWait time.Duration, reportStep func(string)) (*MusicResult, error) {
+	result, err := Poll(ctx, func(ctx context.Context) (*MusicResult, PollState, error) {
+		result, err := c.GetMusicStatus(ctx, taskID)
+		if err != nil {
+			return nil, PollFailed, err
 		}
+		return result, pollStateForStatus(result.Status), nil
+	}, defaultPollOptions(maxWait), c.onPollAttempt(ctx, "music", taskID, reportStep))
+	if err != nil {
+		log.Error(ctx, "suno poll music failed", "taskId", taskID, "err", err)
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("music generation timed out after %v", maxWait)
+	return result, nil
 }
 
-// PollStemSplitStatus polls for stem split completion
-func (c *SunoClient) PollStemSplitStatus(ctx context.Context, taskID string, interval time.Duration, maxWait time.Duration) (*StemSplitResult, error) {
-	deadline := time.Now().Add(maxWait)
-	attempt := 0
-
-	for time.Now().Before(deadline) {
-		attempt++
+// PollStemSplitStatus polls for stem split completion. reportStep, if
+// non-nil, is called after each attempt with a CurrentStep-style description
+// of poll progress.
+func (c *SunoClient) PollStemSplitStatus(ctx context.Context, taskID string, maxWait time.Duration, reportStep func(string)) (*StemSplitResult, error) {
+	result, err := Poll(ctx, func(ctx context.Context) (*StemSplitResult, PollState, error) {
 		result, err := c.GetStemSplitStatus(ctx, taskID)
 		if err != nil {
-			log.Printf("[Suno API] Poll stems #%d (task=%s) — error: %v", attempt, taskID, err)
-			return nil, err
-		}
-
-		log.Printf("[Suno API] Poll stems #%d (task=%s) — status: %s", attempt, taskID, result.Status)
-
-		switch result.Status {
-		case "completed", "success":
-			return result, nil
-		case "failed", "error":
-			return nil, fmt.Errorf("stem split failed: %s", result.Status)
-		}
-
-		select {
-		case <-ctx.Done():
-			log.Printf("[Suno API] Poll stems (task=%s) — context cancelled", taskID)
-			return nil, ctx.Err()
-		case <-time.After(interval):
-			continue
+			return nil, PollFailed, err
 		}
+		return result, pollStateForStatus(result.Status), nil
+	}, defaultPollOptions(maxWait), c.onPollAttempt(ctx, "stems", taskID, reportStep))
+	if err != nil {
+		log.Error(ctx, "suno poll stems failed", "taskId", taskID, "err", err)
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("stem split timed out after %v", maxWait)
+	return result, nil
 }
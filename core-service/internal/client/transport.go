@@ -0,0 +1,295 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrAudioServiceUnavailable is returned when the circuit breaker for the
+// audio microservice is open and a request is short-circuited without being
+// sent, or when HealthCheck is called while the breaker is open.
+var ErrAudioServiceUnavailable = errors.New("audio service unavailable: circuit breaker open")
+
+// ErrSunoUnavailable is the Suno-client equivalent of
+// ErrAudioServiceUnavailable, returned while that breaker is open.
+var ErrSunoUnavailable = errors.New("suno API unavailable: circuit breaker open")
+
+// ErrGroqUnavailable is the Groq-client equivalent of
+// ErrAudioServiceUnavailable, returned while that breaker is open.
+var ErrGroqUnavailable = errors.New("groq API unavailable: circuit breaker open")
+
+var (
+	audioRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "audio_client_retries_total",
+		Help: "Total number of retried requests to the audio microservice.",
+	})
+	audioBreakerState = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "audio_client_breaker_state",
+		Help: "Circuit breaker state for the audio microservice (0=closed, 1=open, 2=half-open).",
+	})
+	sunoRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "suno_client_retries_total",
+		Help: "Total number of retried requests to the Suno API.",
+	})
+	sunoBreakerState = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "suno_client_breaker_state",
+		Help: "Circuit breaker state for the Suno API (0=closed, 1=open, 2=half-open).",
+	})
+	groqRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "groq_client_retries_total",
+		Help: "Total number of retried requests to the Groq API.",
+	})
+	groqBreakerState = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "groq_client_breaker_state",
+		Help: "Circuit breaker state for the Groq API (0=closed, 1=open, 2=half-open).",
+	})
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker is a per-host breaker: it opens after consecutiveFailures
+// reaches threshold, then turns half-open once cooldown has elapsed. The next
+// recorded failure re-opens it; the next success closes it. Half-open does
+// not limit how many requests go through before that result is recorded.
+type circuitBreaker struct {
+	mu                  sync.Mutex
+	state               breakerState
+	consecutiveFailures int
+	threshold           int
+	cooldown            time.Duration
+	openedAt            time.Time
+	stateGauge          prometheus.Gauge
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, stateGauge prometheus.Gauge) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, stateGauge: stateGauge}
+}
+
+// allow reports whether a request may proceed, flipping Open to HalfOpen once
+// the cooldown has elapsed. Every call while HalfOpen is allowed through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerOpen {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.state = breakerHalfOpen
+	b.stateGauge.Set(2)
+	return true
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.state = breakerClosed
+	b.stateGauge.Set(0)
+}
+
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures++
+	if b.state == breakerHalfOpen || b.consecutiveFailures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		b.stateGauge.Set(1)
+	}
+}
+
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == breakerOpen
+}
+
+// snapshot returns the breaker's current state and consecutive-failure count
+// for callers that want to surface breaker health (e.g. in a poll's
+// CurrentStep) without reaching into its internals.
+func (b *circuitBreaker) snapshot() (breakerState, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.consecutiveFailures
+}
+
+// cooldownRemaining returns how much longer an Open breaker has left before
+// allow() lets a half-open probe through, or 0 if it isn't open. Callers
+// that retry work gated by this breaker (e.g. an Asynq RetryDelayFunc) use
+// this to pace retries to the breaker's own clock instead of guessing at an
+// independent backoff that might resolve before or long after the breaker
+// actually closes.
+func (b *circuitBreaker) cooldownRemaining() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != breakerOpen {
+		return 0
+	}
+	remaining := b.cooldown - time.Since(b.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// retryTransport wraps an http.RoundTripper with exponential backoff and
+// jitter on 429/5xx responses and connection errors, short-circuiting via a
+// circuit breaker once the upstream looks consistently down. It honors a
+// Retry-After header on 429/503 responses in preference to its own backoff.
+type retryTransport struct {
+	next           http.RoundTripper
+	breaker        *circuitBreaker
+	unavailableErr error
+	retriesTotal   prometheus.Counter
+	maxRetries     int
+	baseDelay      time.Duration
+	maxDelay       time.Duration
+}
+
+// retryTransportConfig carries the tunables for newRetryTransport, kept
+// independent of any single client's config struct so both AudioClient and
+// SunoClient can build one from their own config.
+type retryTransportConfig struct {
+	MaxRetries  int
+	BaseDelayMS int
+	MaxDelayMS  int
+}
+
+func newRetryTransport(cfg retryTransportConfig, breaker *circuitBreaker, unavailableErr error, retriesTotal prometheus.Counter) *retryTransport {
+	return &retryTransport{
+		next:           http.DefaultTransport,
+		breaker:        breaker,
+		unavailableErr: unavailableErr,
+		retriesTotal:   retriesTotal,
+		maxRetries:     cfg.MaxRetries,
+		baseDelay:      time.Duration(cfg.BaseDelayMS) * time.Millisecond,
+		maxDelay:       time.Duration(cfg.MaxDelayMS) * time.Millisecond,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.breaker.allow() {
+		return nil, t.unavailableErr
+	}
+
+	var lastErr error
+	var retryAfter time.Duration
+	for attempt := 0; attempt <= t.maxRetries; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			t.retriesTotal.Inc()
+
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(t.backoff(attempt, retryAfter)):
+			}
+			retryAfter = 0
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+				}
+				clone := req.Clone(req.Context())
+				clone.Body = body
+				attemptReq = clone
+			}
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			t.breaker.recordSuccess()
+			return resp, nil
+		}
+
+		if err != nil {
+			lastErr = err
+			if !isRetryableErr(err) {
+				t.breaker.recordFailure()
+				return nil, err
+			}
+		} else {
+			lastErr = fmt.Errorf("upstream returned status %d", resp.StatusCode)
+			retryAfter = retryAfterDelay(resp.Header.Get("Retry-After"))
+			resp.Body.Close()
+		}
+	}
+
+	t.breaker.recordFailure()
+	return nil, lastErr
+}
+
+// backoff returns the delay before the given retry attempt (1-indexed),
+// doubling each time up to maxDelay with up to 50% jitter. retryAfter, when
+// set from the previous response's Retry-After header, takes priority over
+// the computed delay.
+func (t *retryTransport) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	d := t.baseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
+	if d > t.maxDelay {
+		d = t.maxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// retryAfterDelay parses a Retry-After header given as either a number of
+// seconds or an HTTP date, returning 0 if it's absent or unparseable.
+func retryAfterDelay(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func isRetryableErr(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,16 @@
+package client
+
+import "github.com/google/wire"
+
+// ProviderSet wires every external client constructor that needs nothing
+// but its own config sub-struct. R2Client is deliberately excluded: it's
+// optional and its constructor returns an error, so callers that want to
+// treat a failed/absent R2 as "fall back to mock storage" still build it
+// by hand in internal/app.
+var ProviderSet = wire.NewSet(
+	NewGroqClient,
+	NewSunoClient,
+	NewAudioClient,
+	wire.Bind(new(MusicGenerator), new(*SunoClient)),
+	wire.Bind(new(AudioProcessor), new(*AudioClient)),
+)
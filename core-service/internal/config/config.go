@@ -1,12 +1,20 @@
 package config
 
 import (
+	"encoding/json"
+	"log"
 	"os"
 	"strings"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
+// ratelimitPlans lists the billing plans a rate-limit table can be keyed
+// by. "free" must always be present -- it's the fallback RateLimiter.Limit
+// uses when a caller's plan claim is missing or unrecognized.
+var ratelimitPlans = []string{"free", "pro", "enterprise"}
+
 // readSecret reads a Docker secret from a file path specified by an env var
 // with _FILE suffix. If FOO is already set directly, the file is skipped.
 // If FOO_FILE is set, reads the file content and sets FOO.
@@ -27,24 +35,58 @@ func readSecret(envKey string) {
 	os.Setenv(envKey, val)
 }
 
+// parseExtraIssuers decodes ZITADEL_EXTRA_ISSUERS, a JSON array of
+// {"issuer":"...","audience":"...","roles":["..."]} objects describing
+// additional trusted IdPs. An empty or malformed value yields no extra
+// issuers rather than failing config load.
+func parseExtraIssuers(raw string) []OIDCIssuer {
+	if raw == "" {
+		return nil
+	}
+	var issuers []OIDCIssuer
+	if err := json.Unmarshal([]byte(raw), &issuers); err != nil {
+		log.Printf("Warning: failed to parse ZITADEL_EXTRA_ISSUERS: %v", err)
+		return nil
+	}
+	return issuers
+}
+
 type Config struct {
 	Server    ServerConfig
 	Redis     RedisConfig
 	JWT       JWTConfig
-	RateLimit RateLimitConfig
+	RateLimit map[string]RateLimitConfig // keyed by plan: "free", "pro", "enterprise"
 	Groq      GroqConfig
-	R2        R2Config
+	Storage   StorageConfig
 	Zitadel   ZitadelConfig
 	Suno      SunoConfig
 	Audio     AudioConfig
 	Gateway   GatewayConfig
+	Runner    RunnerConfig
+	Lyrics    LyricsConfig
 }
 
 type ServerConfig struct {
 	Port      string
 	Env       string
 	LogLevel  string
+	LogFormat string
 	ApiDomain string
+
+	// LogSampling, if > 0, keeps only 1 in every N Info/Debug lines across
+	// the whole process; Warn/Error always pass through uncounted. 0
+	// disables sampling. See log.Init.
+	LogSampling int
+	// AuditEnabled turns on writing security/job-lifecycle events (auth,
+	// job submission, cancellation) to the Redis stream audit.StreamKey for
+	// later export, on top of the normal structured request logs.
+	AuditEnabled bool
+
+	// AllowedOrigins is a comma-separated CORS allowlist passed straight
+	// into cors.Config.AllowOrigins, which already rejects (by omitting
+	// the Access-Control-Allow-Origin header) any Origin not on the list.
+	// Empty means no browser origin is trusted.
+	AllowedOrigins string
 }
 
 type RedisConfig struct {
@@ -66,41 +108,194 @@ type RateLimitConfig struct {
 	UploadPerHour int
 }
 
+// defaultRateLimitConfig returns the built-in bucket sizes for plan, used
+// as viper defaults and as RateLimiter's last-resort fallback if the live
+// config's RateLimit map is missing both the caller's plan and "free".
+func defaultRateLimitConfig(plan string) RateLimitConfig {
+	switch plan {
+	case "pro":
+		return RateLimitConfig{LyricsPerMin: 60, RenderPerHour: 20, MasterPerHour: 30, ExportPerHour: 60, UploadPerHour: 150}
+	case "enterprise":
+		return RateLimitConfig{LyricsPerMin: 300, RenderPerHour: 100, MasterPerHour: 150, ExportPerHour: 300, UploadPerHour: 750}
+	default:
+		return RateLimitConfig{LyricsPerMin: 30, RenderPerHour: 5, MasterPerHour: 10, ExportPerHour: 20, UploadPerHour: 50}
+	}
+}
+
 type GroqConfig struct {
 	APIKey  string
 	BaseURL string
 	Model   string
+
+	Timeout           int // seconds
+	MaxRetries        int
+	BaseDelayMS       int // base delay for exponential backoff, in milliseconds
+	MaxDelayMS        int // cap on backoff delay, in milliseconds
+	BreakerThreshold  int // consecutive failures before the circuit breaker opens
+	BreakerCooldownMS int // time before a half-open probe is allowed, in milliseconds
 }
 
-type R2Config struct {
-	AccountID       string
+// StorageConfig configures the object storage backend. Provider selects how
+// the rest of this struct is interpreted:
+//   - "r2" (default): AccountID builds the endpoint automatically
+//     (https://<account_id>.r2.cloudflarestorage.com); Endpoint/Region/
+//     UseSSL/PathStyle are ignored.
+//   - "s3": Endpoint is optional (empty means real AWS S3, using Region);
+//     set it to point at an S3-compatible host.
+//   - "minio", "gcs": Endpoint is required. PathStyle should be true for a
+//     typical MinIO deployment, since it usually isn't configured with
+//     per-bucket virtual-host DNS.
+//   - "fs": no S3-compatible store at all. Objects are written under
+//     LocalRoot and served from this process at PublicURL (mounted as
+//     /files); AccessKeyID/SecretAccessKey/BucketName/Endpoint/Region/
+//     UseSSL/PathStyle are all ignored, and SigningSecret takes over the
+//     role AWS SigV4 plays for GetSignedURL/PresignPutURL.
+//
+// This lets self-hosters run entirely against MinIO (or any other
+// S3-compatible store), or skip object storage altogether, without
+// touching Cloudflare.
+type StorageConfig struct {
+	Provider        string // "r2" (default), "s3", "minio", "gcs", "fs"
+	AccountID       string // r2 only
 	AccessKeyID     string
 	SecretAccessKey string
 	BucketName      string
 	PublicURL       string
+	ColdBucketName  string // long-term archive bucket; archival is a no-op if unset
+	Endpoint        string // custom endpoint URL; required for minio/gcs, optional for s3
+	Region          string
+	UseSSL          bool
+	PathStyle       bool // force path-style addressing (bucket as URL path segment, not subdomain)
+
+	// LocalRoot is the directory FilesystemClient ("fs" provider) writes
+	// objects under, mirroring the key structure (e.g. vocals/<project>/...)
+	// that would otherwise be an S3 key.
+	LocalRoot string
+	// SigningSecret is the HMAC key FilesystemClient signs GetSignedURL/
+	// PresignPutURL query strings with, and middleware.VerifySignedFileURL
+	// checks them against. Required for the "fs" provider.
+	SigningSecret string
 }
 
 type ZitadelConfig struct {
-	Domain   string
-	ClientID string
+	Domain       string
+	ClientID     string
+	Issuer       string
+	ExtraIssuers []OIDCIssuer // additional trusted IdPs (staff IdP, partner tenants, ...)
+
+	// ClientAssertionKey/ClientAssertionKeyID back the private_key_jwt
+	// client assertion POST /api/auth/refresh signs for the token endpoint.
+	// ClientAssertionKey is required for the refresh endpoint to work:
+	// session.NewManager returns an error at construction time if it doesn't
+	// parse as an RSA key. ClientAssertionKeyID is optional and only sets the
+	// assertion's kid header.
+	ClientAssertionKey   string // PEM-encoded RSA private key
+	ClientAssertionKeyID string // kid advertised in the assertion's JWT header
+}
+
+// OIDCIssuer describes one additional trusted token issuer beyond the
+// primary Zitadel tenant: its own audience, and optionally the set of
+// roles it's allowed to assert (empty means any role is accepted).
+type OIDCIssuer struct {
 	Issuer   string
+	Audience string
+	Roles    []string
 }
 
 type SunoConfig struct {
-	APIKey  string
-	BaseURL string
+	APIKey            string
+	BaseURL           string
+	MaxRetries        int
+	BaseDelayMS       int // base delay for exponential backoff, in milliseconds
+	MaxDelayMS        int // cap on backoff delay, in milliseconds
+	BreakerThreshold  int // consecutive failures before the circuit breaker opens
+	BreakerCooldownMS int // time before a half-open probe is allowed, in milliseconds
 }
 
 type AudioConfig struct {
-	ServiceURL string
-	Timeout    int // seconds
+	ServiceURL        string
+	Timeout           int // seconds
+	MaxRetries        int
+	BaseDelayMS       int // base delay for exponential backoff, in milliseconds
+	MaxDelayMS        int // cap on backoff delay, in milliseconds
+	BreakerThreshold  int // consecutive failures before the circuit breaker opens
+	BreakerCooldownMS int // time before a half-open probe is allowed, in milliseconds
 }
 
 type GatewayConfig struct {
 	Enabled bool
 }
 
-func Load() (*Config, error) {
+// RunnerConfig holds the settings for the internal RPC contract between the
+// API process and the standalone job runner (see internal/runnerapi).
+type RunnerConfig struct {
+	SharedSecret string // presented by the runner on every call via the X-Runner-Secret header
+	APIBaseURL   string // base URL the runner uses to call back into the API
+
+	// LeaseTimeoutSeconds bounds how long a running job may go without a
+	// progress report before RenderService.GetStatus treats its runner as
+	// dead and fails it, rather than leaving it "running" forever if the
+	// runner process crashed or its pod was evicted mid-job.
+	LeaseTimeoutSeconds int
+
+	// MetricsPort serves /metrics for the runner's own process (task
+	// duration/failure histograms from worker.MetricsMiddleware). The API
+	// process exposes /metrics on its main Fiber app instead, but the
+	// runner has no HTTP server of its own otherwise.
+	MetricsPort string
+}
+
+// LyricsConfig configures service.LyricsService's agent chain (cache,
+// filesystem, LRCLIB, Groq) and the service.LyricsCache in front of it.
+type LyricsConfig struct {
+	CacheTTL          int    // seconds; how long a cached lyrics:v1:<hash> entry lives
+	CacheEnabled      bool   // false drops "cache" from EffectiveAgents regardless of Agents/OfflineMode
+	Agents            string // comma-separated agent names, tried in order; see EffectiveAgents
+	OfflineMode       bool   // forces the chain to cache+filesystem only, skipping network calls
+	FilesystemPath    string // root dir for the filesystem agent; empty disables it
+	LRCLIBBaseURL     string // empty uses LRCLIB's public API
+	BreakerThreshold  int    // consecutive failures before a network-backed agent's breaker opens
+	BreakerCooldownMS int    // time before a half-open probe is allowed, in milliseconds
+}
+
+// EffectiveAgents returns the ordered agent names LyricsService should
+// chain. OfflineMode overrides Agents entirely rather than filtering it, so
+// a deployment can flip one flag to go fully offline without having to also
+// edit its LYRICS_AGENTS list. CacheEnabled is then applied as a filter on
+// top of either source, so it works the same way whether or not the
+// deployment is offline.
+func (c LyricsConfig) EffectiveAgents() []string {
+	var agents []string
+	if c.OfflineMode {
+		agents = []string{"cache", "filesystem"}
+	} else {
+		for _, name := range strings.Split(c.Agents, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				agents = append(agents, name)
+			}
+		}
+	}
+
+	if c.CacheEnabled {
+		return agents
+	}
+	filtered := agents[:0:0]
+	for _, name := range agents {
+		if name != "cache" {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+// Load reads config once from the environment/config file and returns it
+// wrapped in a *Live that keeps itself current: viper.WatchConfig triggers a
+// rebuild on every change to the backing file, so subscribers such as the
+// per-plan rate limiter see new values without a restart. Sub-structs wired
+// once at process start (Redis, Groq, Suno, Audio, Zitadel, Runner) are
+// still only read at startup -- only fields routed through a *Live, like
+// RateLimit, are actually hot-reloaded today.
+func Load() (*Live, error) {
 	// Read Docker Swarm secrets from _FILE env vars before Viper binds
 	readSecret("REDIS_PASSWORD")
 	readSecret("GROQ_API_KEY")
@@ -109,6 +304,7 @@ func Load() (*Config, error) {
 	readSecret("R2_ACCESS_KEY_ID")
 	readSecret("R2_SECRET_ACCESS_KEY")
 	readSecret("ZITADEL_CLIENT_ID")
+	readSecret("ZITADEL_CLIENT_ASSERTION_KEY")
 
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -122,6 +318,9 @@ func Load() (*Config, error) {
 	_ = viper.BindEnv("server.port", "SERVER_PORT")
 	_ = viper.BindEnv("server.env", "SERVER_ENV")
 	_ = viper.BindEnv("server.log_level", "LOG_LEVEL")
+	_ = viper.BindEnv("server.log_format", "LOG_FORMAT")
+	_ = viper.BindEnv("server.log_sampling", "LOG_SAMPLING")
+	_ = viper.BindEnv("server.audit_enabled", "AUDIT_ENABLED")
 	_ = viper.BindEnv("redis.addr", "REDIS_ADDR")
 	_ = viper.BindEnv("redis.password", "REDIS_PASSWORD")
 	_ = viper.BindEnv("redis.db", "REDIS_DB")
@@ -130,20 +329,62 @@ func Load() (*Config, error) {
 	_ = viper.BindEnv("groq.api_key", "GROQ_API_KEY")
 	_ = viper.BindEnv("groq.base_url", "GROQ_BASE_URL")
 	_ = viper.BindEnv("groq.model", "GROQ_MODEL")
-	_ = viper.BindEnv("r2.account_id", "R2_ACCOUNT_ID")
-	_ = viper.BindEnv("r2.access_key_id", "R2_ACCESS_KEY_ID")
-	_ = viper.BindEnv("r2.secret_access_key", "R2_SECRET_ACCESS_KEY")
-	_ = viper.BindEnv("r2.bucket_name", "R2_BUCKET_NAME")
-	_ = viper.BindEnv("r2.public_url", "R2_PUBLIC_URL")
+	_ = viper.BindEnv("groq.timeout", "GROQ_TIMEOUT")
+	_ = viper.BindEnv("groq.max_retries", "GROQ_MAX_RETRIES")
+	_ = viper.BindEnv("groq.base_delay_ms", "GROQ_BASE_DELAY_MS")
+	_ = viper.BindEnv("groq.max_delay_ms", "GROQ_MAX_DELAY_MS")
+	_ = viper.BindEnv("groq.breaker_threshold", "GROQ_BREAKER_THRESHOLD")
+	_ = viper.BindEnv("groq.breaker_cooldown_ms", "GROQ_BREAKER_COOLDOWN_MS")
+	_ = viper.BindEnv("storage.provider", "STORAGE_PROVIDER")
+	_ = viper.BindEnv("storage.account_id", "R2_ACCOUNT_ID")
+	_ = viper.BindEnv("storage.access_key_id", "STORAGE_ACCESS_KEY_ID")
+	_ = viper.BindEnv("storage.secret_access_key", "STORAGE_SECRET_ACCESS_KEY")
+	_ = viper.BindEnv("storage.bucket_name", "STORAGE_BUCKET_NAME")
+	_ = viper.BindEnv("storage.public_url", "STORAGE_PUBLIC_URL")
+	_ = viper.BindEnv("storage.cold_bucket_name", "STORAGE_COLD_BUCKET_NAME")
+	_ = viper.BindEnv("storage.endpoint", "STORAGE_ENDPOINT")
+	_ = viper.BindEnv("storage.region", "STORAGE_REGION")
+	_ = viper.BindEnv("storage.use_ssl", "STORAGE_USE_SSL")
+	_ = viper.BindEnv("storage.path_style", "STORAGE_PATH_STYLE")
+	_ = viper.BindEnv("storage.local_root", "STORAGE_LOCAL_ROOT")
+	_ = viper.BindEnv("storage.signing_secret", "STORAGE_SIGNING_SECRET")
+	viper.SetDefault("storage.provider", "r2")
+	viper.SetDefault("storage.use_ssl", true)
+	viper.SetDefault("storage.local_root", "./data/storage")
 	_ = viper.BindEnv("zitadel.domain", "ZITADEL_DOMAIN")
 	_ = viper.BindEnv("zitadel.client_id", "ZITADEL_CLIENT_ID")
 	_ = viper.BindEnv("zitadel.issuer", "ZITADEL_ISSUER")
+	_ = viper.BindEnv("zitadel.extra_issuers", "ZITADEL_EXTRA_ISSUERS")
+	_ = viper.BindEnv("zitadel.client_assertion_key", "ZITADEL_CLIENT_ASSERTION_KEY")
+	_ = viper.BindEnv("zitadel.client_assertion_key_id", "ZITADEL_CLIENT_ASSERTION_KEY_ID")
 	_ = viper.BindEnv("suno.api_key", "SUNO_API_KEY")
 	_ = viper.BindEnv("suno.base_url", "SUNO_BASE_URL")
+	_ = viper.BindEnv("suno.max_retries", "SUNO_MAX_RETRIES")
+	_ = viper.BindEnv("suno.base_delay_ms", "SUNO_BASE_DELAY_MS")
+	_ = viper.BindEnv("suno.max_delay_ms", "SUNO_MAX_DELAY_MS")
+	_ = viper.BindEnv("suno.breaker_threshold", "SUNO_BREAKER_THRESHOLD")
+	_ = viper.BindEnv("suno.breaker_cooldown_ms", "SUNO_BREAKER_COOLDOWN_MS")
 	_ = viper.BindEnv("audio.service_url", "AUDIO_SERVICE_URL")
 	_ = viper.BindEnv("audio.timeout", "AUDIO_SERVICE_TIMEOUT")
+	_ = viper.BindEnv("audio.max_retries", "AUDIO_MAX_RETRIES")
+	_ = viper.BindEnv("audio.base_delay_ms", "AUDIO_BASE_DELAY_MS")
+	_ = viper.BindEnv("audio.max_delay_ms", "AUDIO_MAX_DELAY_MS")
+	_ = viper.BindEnv("audio.breaker_threshold", "AUDIO_BREAKER_THRESHOLD")
+	_ = viper.BindEnv("audio.breaker_cooldown_ms", "AUDIO_BREAKER_COOLDOWN_MS")
 	_ = viper.BindEnv("server.api_domain", "API_DOMAIN")
+	_ = viper.BindEnv("server.allowed_origins", "ALLOWED_ORIGINS")
 	_ = viper.BindEnv("gateway.enabled", "GATEWAY_ENABLED")
+	_ = viper.BindEnv("runner.shared_secret", "RUNNER_SHARED_SECRET")
+	_ = viper.BindEnv("runner.api_base_url", "RUNNER_API_BASE_URL")
+	_ = viper.BindEnv("runner.lease_timeout_seconds", "RUNNER_LEASE_TIMEOUT_SECONDS")
+	_ = viper.BindEnv("lyrics.cache_ttl", "LYRICS_CACHE_TTL")
+	_ = viper.BindEnv("lyrics.cache_enabled", "LYRICS_CACHE_ENABLED")
+	_ = viper.BindEnv("lyrics.agents", "LYRICS_AGENTS")
+	_ = viper.BindEnv("lyrics.offline_mode", "LYRICS_OFFLINE_MODE")
+	_ = viper.BindEnv("lyrics.filesystem_path", "LYRICS_FILESYSTEM_PATH")
+	_ = viper.BindEnv("lyrics.lrclib_base_url", "LYRICS_LRCLIB_BASE_URL")
+	_ = viper.BindEnv("lyrics.breaker_threshold", "LYRICS_BREAKER_THRESHOLD")
+	_ = viper.BindEnv("lyrics.breaker_cooldown_ms", "LYRICS_BREAKER_COOLDOWN_MS")
 
 	// Defaults
 	viper.SetDefault("server.port", "8000")
@@ -154,35 +395,109 @@ func Load() (*Config, error) {
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("jwt.secret", "change-me-in-production")
 	viper.SetDefault("jwt.expiration", 24)
-	viper.SetDefault("ratelimit.lyrics_per_min", 30)
-	viper.SetDefault("ratelimit.render_per_hour", 5)
-	viper.SetDefault("ratelimit.master_per_hour", 10)
-	viper.SetDefault("ratelimit.export_per_hour", 20)
-	viper.SetDefault("ratelimit.upload_per_hour", 50)
+	for _, plan := range ratelimitPlans {
+		d := defaultRateLimitConfig(plan)
+		viper.SetDefault("ratelimit."+plan+".lyrics_per_min", d.LyricsPerMin)
+		viper.SetDefault("ratelimit."+plan+".render_per_hour", d.RenderPerHour)
+		viper.SetDefault("ratelimit."+plan+".master_per_hour", d.MasterPerHour)
+		viper.SetDefault("ratelimit."+plan+".export_per_hour", d.ExportPerHour)
+		viper.SetDefault("ratelimit."+plan+".upload_per_hour", d.UploadPerHour)
+	}
+	// RATELIMIT_* env vars (no plan segment) override the "free" plan only,
+	// preserving the pre-multi-plan env var names.
+	_ = viper.BindEnv("ratelimit.free.lyrics_per_min", "RATELIMIT_LYRICS_PER_MIN")
+	_ = viper.BindEnv("ratelimit.free.render_per_hour", "RATELIMIT_RENDER_PER_HOUR")
+	_ = viper.BindEnv("ratelimit.free.master_per_hour", "RATELIMIT_MASTER_PER_HOUR")
+	_ = viper.BindEnv("ratelimit.free.export_per_hour", "RATELIMIT_EXPORT_PER_HOUR")
+	_ = viper.BindEnv("ratelimit.free.upload_per_hour", "RATELIMIT_UPLOAD_PER_HOUR")
 
 	// Groq defaults
 	viper.SetDefault("groq.base_url", "https://api.groq.com/openai/v1")
 	viper.SetDefault("groq.model", "llama-3.3-70b-versatile")
+	viper.SetDefault("groq.timeout", 60)
+	viper.SetDefault("groq.max_retries", 3)
+	viper.SetDefault("groq.base_delay_ms", 200)
+	viper.SetDefault("groq.max_delay_ms", 5000)
+	viper.SetDefault("groq.breaker_threshold", 5)
+	viper.SetDefault("groq.breaker_cooldown_ms", 30000)
 
 	// Suno defaults
 	viper.SetDefault("suno.base_url", "https://api.sunoapi.org")
+	viper.SetDefault("suno.max_retries", 3)
+	viper.SetDefault("suno.base_delay_ms", 200)
+	viper.SetDefault("suno.max_delay_ms", 5000)
+	viper.SetDefault("suno.breaker_threshold", 5)
+	viper.SetDefault("suno.breaker_cooldown_ms", 30000)
 
 	// Audio service defaults
 	viper.SetDefault("audio.service_url", "http://localhost:8084")
 	viper.SetDefault("audio.timeout", 120)
+	viper.SetDefault("audio.max_retries", 3)
+	viper.SetDefault("audio.base_delay_ms", 200)
+	viper.SetDefault("audio.max_delay_ms", 5000)
+	viper.SetDefault("audio.breaker_threshold", 5)
+	viper.SetDefault("audio.breaker_cooldown_ms", 30000)
 
 	// Gateway defaults
 	viper.SetDefault("gateway.enabled", false)
 
+	// Runner defaults
+	viper.SetDefault("runner.shared_secret", "change-me-in-production")
+	viper.SetDefault("runner.api_base_url", "http://localhost:8000")
+	viper.SetDefault("runner.lease_timeout_seconds", 120)
+	viper.SetDefault("runner.metrics_port", "9100")
+
+	// Lyrics cache/agent defaults
+	viper.SetDefault("lyrics.cache_ttl", 24*60*60)
+	viper.SetDefault("lyrics.cache_enabled", true)
+	viper.SetDefault("lyrics.agents", "cache,filesystem,lrclib,groq")
+	viper.SetDefault("lyrics.offline_mode", false)
+	viper.SetDefault("lyrics.filesystem_path", "")
+	viper.SetDefault("lyrics.lrclib_base_url", "")
+	viper.SetDefault("lyrics.breaker_threshold", 5)
+	viper.SetDefault("lyrics.breaker_cooldown_ms", 30000)
+
 	// Try to read config file (optional)
 	_ = viper.ReadInConfig()
 
-	cfg := &Config{
+	cfg := build()
+	live := NewLive(cfg)
+
+	// Watch the config file (if one was found) and rebuild + fan out to
+	// subscribers on every change, so rate limits (and anything else read
+	// through the *Live) can be updated without restarting the process.
+	viper.OnConfigChange(func(fsnotify.Event) {
+		live.set(build())
+	})
+	viper.WatchConfig()
+
+	return live, nil
+}
+
+// build reads the current viper state into a Config. It's called once by
+// Load for the initial snapshot and again on every OnConfigChange reload.
+func build() *Config {
+	rateLimit := make(map[string]RateLimitConfig, len(ratelimitPlans))
+	for _, plan := range ratelimitPlans {
+		rateLimit[plan] = RateLimitConfig{
+			LyricsPerMin:  viper.GetInt("ratelimit." + plan + ".lyrics_per_min"),
+			RenderPerHour: viper.GetInt("ratelimit." + plan + ".render_per_hour"),
+			MasterPerHour: viper.GetInt("ratelimit." + plan + ".master_per_hour"),
+			ExportPerHour: viper.GetInt("ratelimit." + plan + ".export_per_hour"),
+			UploadPerHour: viper.GetInt("ratelimit." + plan + ".upload_per_hour"),
+		}
+	}
+
+	return &Config{
 		Server: ServerConfig{
-			Port:      viper.GetString("server.port"),
-			Env:       viper.GetString("server.env"),
-			LogLevel:  viper.GetString("server.log_level"),
-			ApiDomain: viper.GetString("server.api_domain"),
+			Port:           viper.GetString("server.port"),
+			Env:            viper.GetString("server.env"),
+			LogLevel:       viper.GetString("server.log_level"),
+			LogFormat:      viper.GetString("server.log_format"),
+			ApiDomain:      viper.GetString("server.api_domain"),
+			LogSampling:    viper.GetInt("server.log_sampling"),
+			AuditEnabled:   viper.GetBool("server.audit_enabled"),
+			AllowedOrigins: viper.GetString("server.allowed_origins"),
 		},
 		Redis: RedisConfig{
 			Addr:     viper.GetString("redis.addr"),
@@ -193,42 +508,77 @@ func Load() (*Config, error) {
 			Secret:     viper.GetString("jwt.secret"),
 			Expiration: viper.GetInt("jwt.expiration"),
 		},
-		RateLimit: RateLimitConfig{
-			LyricsPerMin:  viper.GetInt("ratelimit.lyrics_per_min"),
-			RenderPerHour: viper.GetInt("ratelimit.render_per_hour"),
-			MasterPerHour: viper.GetInt("ratelimit.master_per_hour"),
-			ExportPerHour: viper.GetInt("ratelimit.export_per_hour"),
-			UploadPerHour: viper.GetInt("ratelimit.upload_per_hour"),
-		},
+		RateLimit: rateLimit,
 		Groq: GroqConfig{
-			APIKey:  viper.GetString("groq.api_key"),
-			BaseURL: viper.GetString("groq.base_url"),
-			Model:   viper.GetString("groq.model"),
+			APIKey:            viper.GetString("groq.api_key"),
+			BaseURL:           viper.GetString("groq.base_url"),
+			Model:             viper.GetString("groq.model"),
+			Timeout:           viper.GetInt("groq.timeout"),
+			MaxRetries:        viper.GetInt("groq.max_retries"),
+			BaseDelayMS:       viper.GetInt("groq.base_delay_ms"),
+			MaxDelayMS:        viper.GetInt("groq.max_delay_ms"),
+			BreakerThreshold:  viper.GetInt("groq.breaker_threshold"),
+			BreakerCooldownMS: viper.GetInt("groq.breaker_cooldown_ms"),
 		},
-		R2: R2Config{
-			AccountID:       viper.GetString("r2.account_id"),
-			AccessKeyID:     viper.GetString("r2.access_key_id"),
-			SecretAccessKey: viper.GetString("r2.secret_access_key"),
-			BucketName:      viper.GetString("r2.bucket_name"),
-			PublicURL:       viper.GetString("r2.public_url"),
+		Storage: StorageConfig{
+			Provider:        viper.GetString("storage.provider"),
+			AccountID:       viper.GetString("storage.account_id"),
+			AccessKeyID:     viper.GetString("storage.access_key_id"),
+			SecretAccessKey: viper.GetString("storage.secret_access_key"),
+			BucketName:      viper.GetString("storage.bucket_name"),
+			PublicURL:       viper.GetString("storage.public_url"),
+			ColdBucketName:  viper.GetString("storage.cold_bucket_name"),
+			Endpoint:        viper.GetString("storage.endpoint"),
+			Region:          viper.GetString("storage.region"),
+			UseSSL:          viper.GetBool("storage.use_ssl"),
+			PathStyle:       viper.GetBool("storage.path_style"),
+			LocalRoot:       viper.GetString("storage.local_root"),
+			SigningSecret:   viper.GetString("storage.signing_secret"),
 		},
 		Zitadel: ZitadelConfig{
-			Domain:   viper.GetString("zitadel.domain"),
-			ClientID: viper.GetString("zitadel.client_id"),
-			Issuer:   viper.GetString("zitadel.issuer"),
+			Domain:               viper.GetString("zitadel.domain"),
+			ClientID:             viper.GetString("zitadel.client_id"),
+			Issuer:               viper.GetString("zitadel.issuer"),
+			ExtraIssuers:         parseExtraIssuers(viper.GetString("zitadel.extra_issuers")),
+			ClientAssertionKey:   viper.GetString("zitadel.client_assertion_key"),
+			ClientAssertionKeyID: viper.GetString("zitadel.client_assertion_key_id"),
 		},
 		Suno: SunoConfig{
-			APIKey:  viper.GetString("suno.api_key"),
-			BaseURL: viper.GetString("suno.base_url"),
+			APIKey:            viper.GetString("suno.api_key"),
+			BaseURL:           viper.GetString("suno.base_url"),
+			MaxRetries:        viper.GetInt("suno.max_retries"),
+			BaseDelayMS:       viper.GetInt("suno.base_delay_ms"),
+			MaxDelayMS:        viper.GetInt("suno.max_delay_ms"),
+			BreakerThreshold:  viper.GetInt("suno.breaker_threshold"),
+			BreakerCooldownMS: viper.GetInt("suno.breaker_cooldown_ms"),
 		},
 		Audio: AudioConfig{
-			ServiceURL: viper.GetString("audio.service_url"),
-			Timeout:    viper.GetInt("audio.timeout"),
+			ServiceURL:        viper.GetString("audio.service_url"),
+			Timeout:           viper.GetInt("audio.timeout"),
+			MaxRetries:        viper.GetInt("audio.max_retries"),
+			BaseDelayMS:       viper.GetInt("audio.base_delay_ms"),
+			MaxDelayMS:        viper.GetInt("audio.max_delay_ms"),
+			BreakerThreshold:  viper.GetInt("audio.breaker_threshold"),
+			BreakerCooldownMS: viper.GetInt("audio.breaker_cooldown_ms"),
 		},
 		Gateway: GatewayConfig{
 			Enabled: viper.GetBool("gateway.enabled"),
 		},
+		Runner: RunnerConfig{
+			SharedSecret:        viper.GetString("runner.shared_secret"),
+			APIBaseURL:          viper.GetString("runner.api_base_url"),
+			LeaseTimeoutSeconds: viper.GetInt("runner.lease_timeout_seconds"),
+			MetricsPort:         viper.GetString("runner.metrics_port"),
+		},
+		Lyrics: LyricsConfig{
+			CacheTTL:          viper.GetInt("lyrics.cache_ttl"),
+			CacheEnabled:      viper.GetBool("lyrics.cache_enabled"),
+			Agents:            viper.GetString("lyrics.agents"),
+			OfflineMode:       viper.GetBool("lyrics.offline_mode"),
+			FilesystemPath:    viper.GetString("lyrics.filesystem_path"),
+			LRCLIBBaseURL:     viper.GetString("lyrics.lrclib_base_url"),
+			BreakerThreshold:  viper.GetInt("lyrics.breaker_threshold"),
+			BreakerCooldownMS: viper.GetInt("lyrics.breaker_cooldown_ms"),
+		},
 	}
-
-	return cfg, nil
 }
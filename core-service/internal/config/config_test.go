@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// writeConfigYAML (re)writes a minimal config.yaml in dir with the given
+// free-plan render limit, exercising the same ratelimit.<plan>.* keys Load
+// reads.
+func writeConfigYAML(t *testing.T, dir string, renderPerHour int) {
+	t.Helper()
+	content := fmt.Sprintf("ratelimit:\n  free:\n    render_per_hour: %d\n", renderPerHour)
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config.yaml: %v", err)
+	}
+}
+
+// TestLoad_ReloadsRateLimitOnFileChange confirms Load's viper.WatchConfig
+// wiring picks up an edited config file and fans it out through Live within
+// a second, without restarting the process.
+func TestLoad_ReloadsRateLimitOnFileChange(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigYAML(t, dir, 5)
+
+	// Load is process-global (it drives the package-level viper instance),
+	// so point it at our temp dir instead of the cwd.
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.AddConfigPath(dir)
+
+	live, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := live.Current().RateLimit["free"].RenderPerHour; got != 5 {
+		t.Fatalf("expected initial RenderPerHour 5, got %d", got)
+	}
+
+	reloaded := make(chan *Config, 1)
+	live.Subscribe(func(cfg *Config) { reloaded <- cfg })
+
+	writeConfigYAML(t, dir, 42)
+
+	select {
+	case cfg := <-reloaded:
+		if got := cfg.RateLimit["free"].RenderPerHour; got != 42 {
+			t.Fatalf("expected reloaded RenderPerHour 42, got %d", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("config reload was not observed within one second")
+	}
+
+	if got := live.Current().RateLimit["free"].RenderPerHour; got != 42 {
+		t.Fatalf("expected Current() to reflect reload, got RenderPerHour %d", got)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Live holds a hot-reloadable Config behind an atomic pointer and fans out
+// each reload to subscribers registered via Subscribe. Load wires one up
+// with viper.WatchConfig, so callers that hold a *Live instead of a bare
+// *Config -- like the per-plan rate limiter -- see every config file
+// change without a restart.
+type Live struct {
+	current atomic.Pointer[Config]
+
+	mu   sync.Mutex
+	subs []func(*Config)
+}
+
+// NewLive wraps cfg as a Live's initial snapshot. Exported for tests that
+// need a *Live without going through Load's viper/file wiring.
+func NewLive(cfg *Config) *Live {
+	l := &Live{}
+	l.current.Store(cfg)
+	return l
+}
+
+// Current returns the most recently loaded Config. The returned value must
+// be treated as read-only -- callers that need to react to later reloads
+// should use Subscribe instead of caching this pointer.
+func (l *Live) Current() *Config {
+	return l.current.Load()
+}
+
+// Subscribe registers fn to run with the new Config every time the backing
+// file is reloaded. fn runs synchronously on viper's watcher goroutine, so
+// it should return quickly and must not mutate the Config it's given.
+func (l *Live) Subscribe(fn func(*Config)) {
+	l.mu.Lock()
+	l.subs = append(l.subs, fn)
+	l.mu.Unlock()
+}
+
+// set stores cfg as the new current snapshot and notifies every subscriber.
+func (l *Live) set(cfg *Config) {
+	l.current.Store(cfg)
+
+	l.mu.Lock()
+	subs := append([]func(*Config){}, l.subs...)
+	l.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(cfg)
+	}
+}
@@ -0,0 +1,123 @@
+// Package errs gives services a typed error carrying a stable machine
+// code and HTTP status, so handlers can stop matching on err.Error()
+// strings (e.g. the `err.Error() == "job not found"` check repeated
+// across the render/master/export/jobs handlers) and use errors.Is/As
+// instead.
+package errs
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Error is a service-layer error with enough information for a handler
+// to build a pkg/response envelope without knowing anything about the
+// failure beyond what's here. Upstream names the external dependency
+// that produced the failure (e.g. "suno", "groq", "r2"), when there is
+// one -- callers surface it in logs/metrics, not in Message.
+type Error struct {
+	Code     string
+	Message  string
+	Status   int
+	Upstream string
+	Details  map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches by Code rather than pointer identity, so a sentinel like
+// ErrJobNotFound can be compared against a freshly built *Error that
+// carries extra Details via errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// AppError is what a handler can type-assert a service error against to
+// get a stable machine-readable slug, the HTTP status it maps to, and any
+// structured parameters, instead of reaching into *Error's fields directly
+// or string-matching err.Error(). *Error satisfies it already; it's pulled
+// out as an interface so a service-layer error type that isn't *Error
+// could be handled the same way without pkg/response depending on this
+// package's concrete type.
+type AppError interface {
+	error
+	Slug() string
+	HTTPStatus() int
+	Params() map[string]string
+}
+
+var _ AppError = (*Error)(nil)
+
+// Slug lowercases Code into the stable identifier clients key off of for
+// i18n/UI lookups (e.g. "JOB_NOT_FOUND" -> "job_not_found") instead of
+// parsing Message, which is meant for logs/humans and can change wording
+// without notice.
+func (e *Error) Slug() string {
+	return strings.ToLower(e.Code)
+}
+
+func (e *Error) HTTPStatus() int {
+	return e.Status
+}
+
+func (e *Error) Params() map[string]string {
+	return e.Details
+}
+
+// Sentinels for the failure modes that recur across services today.
+// Compare against these with errors.Is rather than err.Error() ==.
+var (
+	ErrJobNotFound     = &Error{Code: "JOB_NOT_FOUND", Message: "job not found", Status: http.StatusNotFound}
+	ErrJobNotCompleted = &Error{Code: "JOB_NOT_COMPLETED", Message: "job not completed", Status: http.StatusBadRequest}
+	ErrJobHasNoResult  = &Error{Code: "JOB_HAS_NO_RESULT", Message: "job has no result to archive", Status: http.StatusBadRequest}
+	ErrRateLimited     = &Error{Code: "RATE_LIMITED", Message: "rate limit exceeded", Status: http.StatusTooManyRequests}
+
+	// ErrWebhookNotConfigured is returned by RenderService.ReplayWebhook
+	// when the job has no CallbackURL to redeliver to.
+	ErrWebhookNotConfigured = &Error{Code: "WEBHOOK_NOT_CONFIGURED", Message: "job has no callback configured", Status: http.StatusBadRequest}
+
+	// ErrAudioServiceUnavailable is returned by MasterService.Preview when
+	// no audio microservice is configured -- unlike a final job, a preview
+	// has no worker fallback to queue onto instead.
+	ErrAudioServiceUnavailable = &Error{Code: "AUDIO_SERVICE_UNAVAILABLE", Message: "audio processing service is not configured", Status: http.StatusServiceUnavailable}
+)
+
+// Validation reports a request that failed validation before any
+// service work started.
+func Validation(message string, details map[string]string) *Error {
+	return &Error{Code: "VALIDATION_ERROR", Message: message, Status: http.StatusBadRequest, Details: details}
+}
+
+// UpstreamQuota reports that an upstream (Suno, Groq, ...) rejected a
+// call for having exhausted a quota or credit balance.
+func UpstreamQuota(upstream, message string) *Error {
+	return &Error{Code: "UPSTREAM_QUOTA", Message: message, Status: http.StatusBadGateway, Upstream: upstream}
+}
+
+// UpstreamTimeout reports that an upstream call exceeded its deadline.
+func UpstreamTimeout(upstream, message string) *Error {
+	return &Error{Code: "UPSTREAM_TIMEOUT", Message: message, Status: http.StatusGatewayTimeout, Upstream: upstream}
+}
+
+// Storage reports a failure talking to object storage (R2/S3/local fs).
+func Storage(message string) *Error {
+	return &Error{Code: "STORAGE_ERROR", Message: message, Status: http.StatusBadGateway}
+}
+
+// IdempotencyKeyConflict reports that an Idempotency-Key header was reused
+// against a request body that doesn't match the one it was first saved
+// against, so the caller knows to generate a new key rather than having
+// the mismatched retry silently replayed or silently re-run.
+func IdempotencyKeyConflict(message string) *Error {
+	return &Error{Code: "IDEMPOTENCY_KEY_CONFLICT", Message: message, Status: http.StatusConflict}
+}
+
+// IdempotencyKeyInFlight reports that another request carrying the same
+// Idempotency-Key is still running, so this one is rejected instead of
+// running the handler a second time concurrently with it.
+func IdempotencyKeyInFlight(message string) *Error {
+	return &Error{Code: "IDEMPOTENCY_KEY_IN_FLIGHT", Message: message, Status: http.StatusConflict}
+}
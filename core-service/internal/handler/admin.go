@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/hibiken/asynq"
+
+	"github.com/makeasinger/api/internal/service"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// AdminHandler exposes operational visibility into the Asynq queues the
+// runner consumes from (render/master/export). Mounted under
+// /api/admin, gated by middleware.RequireRole("admin").
+type AdminHandler struct {
+	queues *service.AdminQueueService
+}
+
+func NewAdminHandler(queues *service.AdminQueueService) *AdminHandler {
+	return &AdminHandler{queues: queues}
+}
+
+// Queues handles GET /api/admin/queues, returning per-queue
+// pending/active/retry/archived counts.
+func (h *AdminHandler) Queues(c *fiber.Ctx) error {
+	names, err := h.queues.Queues()
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	infos := make([]*asynq.QueueInfo, 0, len(names))
+	for _, name := range names {
+		info, err := h.queues.QueueInfo(name)
+		if err != nil {
+			return response.ServiceError(c, err.Error())
+		}
+		infos = append(infos, info)
+	}
+	return response.OK(c, infos)
+}
+
+// Tasks handles GET /api/admin/queues/:name/tasks?state=retry
+func (h *AdminHandler) Tasks(c *fiber.Ctx) error {
+	tasks, err := h.queues.ListTasks(c.Params("name"), c.Query("state"))
+	if err != nil {
+		return response.ValidationError(c, err.Error(), nil)
+	}
+	return response.OK(c, tasks)
+}
+
+// RunTask handles POST /api/admin/queues/:name/tasks/:id/run
+func (h *AdminHandler) RunTask(c *fiber.Ctx) error {
+	if err := h.queues.RunTask(c.Params("name"), c.Params("id")); err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+	return response.OK(c, fiber.Map{"status": "ok"})
+}
+
+// ArchiveTask handles POST /api/admin/queues/:name/tasks/:id/archive
+func (h *AdminHandler) ArchiveTask(c *fiber.Ctx) error {
+	if err := h.queues.ArchiveTask(c.Params("name"), c.Params("id")); err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+	return response.OK(c, fiber.Map{"status": "ok"})
+}
+
+// DeleteTask handles DELETE /api/admin/queues/:name/tasks/:id
+func (h *AdminHandler) DeleteTask(c *fiber.Ctx) error {
+	if err := h.queues.DeleteTask(c.Params("name"), c.Params("id")); err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+	return response.NoContent(c)
+}
@@ -2,22 +2,37 @@ package handler
 
 import (
 	"strings"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/makeasinger/api/internal/audit"
 	"github.com/makeasinger/api/internal/auth"
+	"github.com/makeasinger/api/internal/auth/session"
+	"github.com/makeasinger/api/internal/middleware"
+	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/pkg/response"
 )
 
 // AuthHandler handles ForwardAuth verification for the API gateway
 type AuthHandler struct {
-	verifier  auth.TokenVerifier
-	jwtSecret string
+	verifier   auth.TokenVerifier
+	jwtSecret  string
+	revocation auth.RevocationStore // may be nil, in which case Revoke is unavailable
+	sessions   *session.Manager     // may be nil, in which case Refresh is unavailable
+	validator  *validator.Validate
+	audit      *audit.Recorder
 }
 
 // NewAuthHandler creates a new auth handler for ForwardAuth verification
-func NewAuthHandler(verifier auth.TokenVerifier, jwtSecret string) *AuthHandler {
+func NewAuthHandler(verifier auth.TokenVerifier, jwtSecret string, revocation auth.RevocationStore, sessions *session.Manager, v *validator.Validate, auditRecorder *audit.Recorder) *AuthHandler {
 	return &AuthHandler{
-		verifier:  verifier,
-		jwtSecret: jwtSecret,
+		verifier:   verifier,
+		jwtSecret:  jwtSecret,
+		revocation: revocation,
+		sessions:   sessions,
+		validator:  v,
+		audit:      auditRecorder,
 	}
 }
 
@@ -38,7 +53,7 @@ func (h *AuthHandler) Verify(c *fiber.Ctx) error {
 
 	// Try Zitadel JWKS verification first
 	if h.verifier != nil {
-		claims, err := h.verifier.Validate(tokenString)
+		claims, err := h.verifier.Validate(c.Context(), tokenString)
 		if err == nil {
 			c.Set("X-User-Id", claims.UserID)
 			c.Set("X-User-Email", claims.Email)
@@ -54,6 +69,12 @@ func (h *AuthHandler) Verify(c *fiber.Ctx) error {
 	if h.jwtSecret != "" {
 		claims, err := auth.ValidateLegacyToken(tokenString, h.jwtSecret)
 		if err == nil {
+			if h.revocation != nil && claims.ID != "" {
+				revoked, err := h.revocation.IsRevoked(c.Context(), claims.ID)
+				if err != nil || revoked {
+					return c.SendStatus(fiber.StatusUnauthorized)
+				}
+			}
 			c.Set("X-User-Id", claims.UserID)
 			c.Set("X-User-Email", claims.Email)
 			return c.SendStatus(fiber.StatusOK)
@@ -62,3 +83,113 @@ func (h *AuthHandler) Verify(c *fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusUnauthorized)
 }
+
+// Revoke handles POST /api/auth/revoke. The caller must be authenticated
+// (see providers.go route registration) and supplies either the token to
+// revoke or its jti+exp directly, e.g. for revoking a token the caller no
+// longer holds. Revocation is keyed by jti with a TTL equal to the token's
+// own remaining lifetime, so the store never grows unbounded.
+// @Summary      Revoke a token
+// @Description  Marks a token's jti as revoked until its expiry
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.RevokeRequest true "Revoke request"
+// @Success      204
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      503 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/auth/revoke [post]
+func (h *AuthHandler) Revoke(c *fiber.Ctx) error {
+	if h.revocation == nil {
+		return response.ServiceError(c, "Token revocation is not configured")
+	}
+
+	var req model.RevokeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	jti, exp := req.JTI, time.Unix(req.Exp, 0)
+
+	if req.Token != "" {
+		id, tokenExp, err := h.parseTokenForRevocation(c, req.Token)
+		if err != nil {
+			return response.ValidationError(c, "Invalid token", nil)
+		}
+		if id == "" {
+			return response.ValidationError(c, "Token has no jti to revoke", nil)
+		}
+		jti, exp = id, tokenExp
+	} else if jti == "" || req.Exp == 0 {
+		return response.ValidationError(c, "Either token, or jti and exp, are required", nil)
+	}
+
+	if err := h.revocation.Revoke(c.Context(), jti, exp); err != nil {
+		return response.ServiceError(c, "Failed to revoke token")
+	}
+
+	h.audit.Record(c.Context(), audit.Event{
+		Type:   "auth.revoke",
+		UserID: middleware.GetUserID(c),
+		Fields: map[string]string{"jti": jti},
+	})
+
+	return response.NoContent(c)
+}
+
+// parseTokenForRevocation extracts the jti and expiry from a token using
+// whichever verification path (JWKS, then legacy HMAC) would have accepted
+// it, mirroring the fallback order Verify and AuthMiddleware use.
+func (h *AuthHandler) parseTokenForRevocation(c *fiber.Ctx, tokenString string) (string, time.Time, error) {
+	if h.verifier != nil {
+		claims, err := h.verifier.Validate(c.Context(), tokenString)
+		if err == nil && claims.ExpiresAt != nil {
+			return claims.ID, claims.ExpiresAt.Time, nil
+		}
+	}
+	if h.jwtSecret != "" {
+		claims, err := auth.ValidateLegacyToken(tokenString, h.jwtSecret)
+		if err == nil && claims.ExpiresAt != nil {
+			return claims.ID, claims.ExpiresAt.Time, nil
+		}
+	}
+	return "", time.Time{}, fiber.ErrUnauthorized
+}
+
+// Refresh handles POST /api/auth/refresh. It exchanges the caller's refresh
+// token for a new access/refresh token pair by presenting a private_key_jwt
+// client assertion to the IdP's token endpoint, so the API never needs to
+// hold the client's own secret.
+// @Summary      Refresh a session
+// @Description  Exchanges a refresh token for a new access token via client-assertion
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.RefreshRequest true "Refresh request"
+// @Success      200 {object} session.TokenResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      502 {object} response.ErrorResponse
+// @Failure      503 {object} response.ErrorResponse
+// @Router       /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	if h.sessions == nil {
+		return response.ServiceError(c, "Token refresh is not configured")
+	}
+
+	var req model.RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	tok, err := h.sessions.Refresh(c.Context(), req.RefreshToken, req.ClientID)
+	if err != nil {
+		return response.ServiceError(c, "Failed to refresh session")
+	}
+
+	return response.OK(c, tok)
+}
@@ -22,12 +22,12 @@ func NewExportHandler(svc *service.ExportService, v *validator.Validate) *Export
 
 // MP3 handles POST /api/export/mp3
 // @Summary      Export as MP3
-// @Description  Export the mastered track as an MP3 file with optional metadata
+// @Description  Queue an asynchronous export of the mastered track as an MP3 file with optional metadata
 // @Tags         Export
 // @Accept       json
 // @Produce      json
 // @Param        request body model.ExportMP3Request true "MP3 export request"
-// @Success      200 {object} model.ExportMP3Response
+// @Success      202 {object} model.ExportJobResponse
 // @Failure      400 {object} response.ErrorResponse
 // @Failure      401 {object} response.ErrorResponse
 // @Failure      429 {object} response.ErrorResponse
@@ -44,22 +44,22 @@ func (h *ExportHandler) MP3(c *fiber.Ctx) error {
 		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
 	}
 
-	result, err := h.service.ExportMP3(c.Context(), &req)
+	result, err := h.service.StartMP3(c.Context(), &req)
 	if err != nil {
 		return response.ServiceError(c, err.Error())
 	}
 
-	return response.OK(c, result)
+	return response.Accepted(c, result)
 }
 
 // WAV handles POST /api/export/wav
 // @Summary      Export as WAV
-// @Description  Export the mastered track as a WAV file with configurable bit depth and sample rate
+// @Description  Queue an asynchronous export of the mastered track as a WAV file with configurable bit depth and sample rate
 // @Tags         Export
 // @Accept       json
 // @Produce      json
 // @Param        request body model.ExportWAVRequest true "WAV export request"
-// @Success      200 {object} model.ExportWAVResponse
+// @Success      202 {object} model.ExportJobResponse
 // @Failure      400 {object} response.ErrorResponse
 // @Failure      401 {object} response.ErrorResponse
 // @Failure      429 {object} response.ErrorResponse
@@ -76,22 +76,182 @@ func (h *ExportHandler) WAV(c *fiber.Ctx) error {
 		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
 	}
 
-	result, err := h.service.ExportWAV(c.Context(), &req)
+	result, err := h.service.StartWAV(c.Context(), &req)
 	if err != nil {
 		return response.ServiceError(c, err.Error())
 	}
 
-	return response.OK(c, result)
+	return response.Accepted(c, result)
+}
+
+// ALAC handles POST /api/export/alac
+// @Summary      Export as ALAC
+// @Description  Queue an asynchronous export of the mastered track as an Apple Lossless (ALAC) file
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ExportALACRequest true "ALAC export request"
+// @Success      202 {object} model.ExportJobResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      429 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/alac [post]
+func (h *ExportHandler) ALAC(c *fiber.Ctx) error {
+	var req model.ExportALACRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.service.StartALAC(c.Context(), &req)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Accepted(c, result)
+}
+
+// FLAC handles POST /api/export/flac
+// @Summary      Export as FLAC
+// @Description  Queue an asynchronous export of the mastered track as a FLAC file with configurable bit depth, sample rate, and compression level
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ExportFLACRequest true "FLAC export request"
+// @Success      202 {object} model.ExportJobResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      429 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/flac [post]
+func (h *ExportHandler) FLAC(c *fiber.Ctx) error {
+	var req model.ExportFLACRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.service.StartFLAC(c.Context(), &req)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Accepted(c, result)
+}
+
+// Opus handles POST /api/export/opus
+// @Summary      Export as Opus
+// @Description  Queue an asynchronous export of the mastered track as an Opus file with configurable bitrate, VBR mode, and application tuning
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ExportOpusRequest true "Opus export request"
+// @Success      202 {object} model.ExportJobResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      429 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/opus [post]
+func (h *ExportHandler) Opus(c *fiber.Ctx) error {
+	var req model.ExportOpusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.service.StartOpus(c.Context(), &req)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Accepted(c, result)
+}
+
+// Ogg handles POST /api/export/ogg
+// @Summary      Export as Ogg Vorbis
+// @Description  Queue an asynchronous export of the mastered track as an Ogg Vorbis file with configurable quality, bitrate, and VBR mode
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ExportOggRequest true "Ogg Vorbis export request"
+// @Success      202 {object} model.ExportJobResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      429 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/ogg [post]
+func (h *ExportHandler) Ogg(c *fiber.Ctx) error {
+	var req model.ExportOggRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.service.StartOgg(c.Context(), &req)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Accepted(c, result)
+}
+
+// Atmos handles POST /api/export/atmos
+// @Summary      Export as Dolby Atmos
+// @Description  Queue an asynchronous spatial-audio export (bed channels plus positioned objects) as an EC-3 JOC bitstream, optionally with a binaural render
+// @Tags         Export
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ExportAtmosRequest true "Atmos export request"
+// @Success      202 {object} model.ExportJobResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      429 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/atmos [post]
+func (h *ExportHandler) Atmos(c *fiber.Ctx) error {
+	var req model.ExportAtmosRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.service.StartAtmos(c.Context(), &req)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Accepted(c, result)
 }
 
 // Stems handles POST /api/export/stems
 // @Summary      Export stems
-// @Description  Export individual stems as a bundled archive
+// @Description  Queue an asynchronous export of individual stems as a bundled ZIP archive
 // @Tags         Export
 // @Accept       json
 // @Produce      json
 // @Param        request body model.ExportStemsRequest true "Stems export request"
-// @Success      200 {object} model.ExportStemsResponse
+// @Success      202 {object} model.ExportJobResponse
 // @Failure      400 {object} response.ErrorResponse
 // @Failure      401 {object} response.ErrorResponse
 // @Failure      429 {object} response.ErrorResponse
@@ -108,10 +268,64 @@ func (h *ExportHandler) Stems(c *fiber.Ctx) error {
 		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
 	}
 
-	result, err := h.service.ExportStems(c.Context(), &req)
+	result, err := h.service.StartStems(c.Context(), &req)
 	if err != nil {
 		return response.ServiceError(c, err.Error())
 	}
 
+	return response.Accepted(c, result)
+}
+
+// Status handles GET /api/export/status/:jobId
+// @Summary      Get export job status
+// @Description  Get the current status and progress of an export job
+// @Tags         Export
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {object} model.RenderStatusResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/status/{jobId} [get]
+func (h *ExportHandler) Status(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	result, err := h.service.GetStatus(c.Context(), jobID)
+	if err != nil {
+		return response.FromError(c, err)
+	}
+
+	return response.OK(c, result)
+}
+
+// Result handles GET /api/export/result/:jobId
+// @Summary      Get export job result
+// @Description  Get the result of a completed export job
+// @Tags         Export
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {object} model.ExportResultResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/export/result/{jobId} [get]
+func (h *ExportHandler) Result(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	result, err := h.service.GetResult(c.Context(), jobID)
+	if err != nil {
+		return response.FromError(c, err)
+	}
+
 	return response.OK(c, result)
 }
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/runnerapi"
+	"github.com/makeasinger/api/internal/service"
+	"github.com/makeasinger/api/internal/websocket"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// InternalJobsHandler serves the runner RPC contract described in
+// internal/runnerapi (progress/logs/complete/fail), plus the same log
+// callback used by the Python audio microservice. These routes are not
+// exposed through the API gateway and are protected by
+// middleware.RunnerAuthMiddleware.
+type InternalJobsHandler struct {
+	hub           *websocket.Hub
+	redis         *redis.Client
+	renderService *service.RenderService
+	archiver      *service.Archiver
+}
+
+func NewInternalJobsHandler(hub *websocket.Hub, redisClient *redis.Client, renderService *service.RenderService, archiver *service.Archiver) *InternalJobsHandler {
+	return &InternalJobsHandler{
+		hub:           hub,
+		redis:         redisClient,
+		renderService: renderService,
+		archiver:      archiver,
+	}
+}
+
+// Logs handles POST /internal/jobs/:jobId/logs
+// Accepts a batch of log lines and relays them to subscribed WebSocket
+// clients via the Hub, persisting the tail in Redis.
+func (h *InternalJobsHandler) Logs(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	var req runnerapi.LogsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+	if len(req.Lines) == 0 {
+		return response.ValidationError(c, "lines is required", nil)
+	}
+
+	stream := req.Stream
+	if stream == "" {
+		stream = "stdout"
+	}
+
+	h.hub.BroadcastLog(jobID, stream, req.Lines)
+	if h.redis != nil {
+		websocket.AppendLogTail(c.Context(), h.redis, jobID, req.Lines)
+	}
+
+	return response.NoContent(c)
+}
+
+// Progress handles POST /internal/jobs/:jobId/progress, the runner's
+// heartbeat while a job is in flight.
+func (h *InternalJobsHandler) Progress(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	var req runnerapi.ProgressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.renderService.UpdateJobProgress(c.Context(), jobID, req.Progress, req.Step); err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	h.hub.BroadcastProgress(jobID, req.Progress, model.JobStatusRunning, req.Step)
+	return response.NoContent(c)
+}
+
+// Complete handles POST /internal/jobs/:jobId/complete, the runner's report
+// of a finished job. If ArchiveKeys is set, archival to cold storage is
+// kicked off in the background once the result is saved.
+func (h *InternalJobsHandler) Complete(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	var req runnerapi.CompleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.renderService.CompleteJob(c.Context(), jobID, req.Result); err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	if h.archiver != nil && len(req.ArchiveKeys) > 0 {
+		h.archiver.Archive(jobID, req.ArchiveKeys)
+	}
+
+	h.hub.BroadcastComplete(jobID, req.Result)
+	return response.NoContent(c)
+}
+
+// Fail handles POST /internal/jobs/:jobId/fail, the runner's report that a
+// job could not complete. A StructuredError takes precedence so status
+// responses can surface which items failed within an otherwise-successful
+// batch.
+func (h *InternalJobsHandler) Fail(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	var req runnerapi.FailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if req.StructuredError != nil {
+		if err := h.renderService.FailJobStructured(c.Context(), jobID, req.StructuredError); err != nil {
+			return response.ServiceError(c, err.Error())
+		}
+		h.hub.BroadcastStructuredError(jobID, req.StructuredError)
+		return response.NoContent(c)
+	}
+
+	if err := h.renderService.FailJob(c.Context(), jobID, req.Error); err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+	h.hub.BroadcastError(jobID, "JOB_FAILED", req.Error)
+	return response.NoContent(c)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/service"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+const (
+	defaultJobWaitTimeout = 30 * time.Second
+	maxJobWaitTimeout     = 2 * time.Minute
+)
+
+// JobsHandler handles cross-job-type admin operations that aren't specific
+// to render or master jobs.
+type JobsHandler struct {
+	archiver      *service.Archiver
+	renderService *service.RenderService
+}
+
+func NewJobsHandler(archiver *service.Archiver, renderService *service.RenderService) *JobsHandler {
+	return &JobsHandler{archiver: archiver, renderService: renderService}
+}
+
+// Archive handles POST /api/jobs/:jobId/archive
+// @Summary      Re-trigger job archival
+// @Description  Re-triggers the background copy of a completed job's output to cold storage
+// @Tags         Jobs
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      202 {object} map[string]interface{}
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/jobs/{jobId}/archive [post]
+func (h *JobsHandler) Archive(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	if err := h.archiver.ArchiveJob(c.Context(), jobID); err != nil {
+		return response.FromError(c, err)
+	}
+
+	return response.Accepted(c, fiber.Map{"jobId": jobID, "archiveStatus": "pending"})
+}
+
+// Status handles GET /api/jobs/:jobId
+// @Summary      Get job status
+// @Description  Gets the current status and progress of a job, whatever its type (render, master, export) -- the one-stop read for a caller that only has a job ID and doesn't know or care which endpoint started it
+// @Tags         Jobs
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {object} model.RenderStatusResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/jobs/{jobId} [get]
+func (h *JobsHandler) Status(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	result, err := h.renderService.GetStatus(c.Context(), jobID)
+	if err != nil {
+		return response.FromError(c, err)
+	}
+
+	return response.OK(c, result)
+}
+
+// Wait handles GET /api/jobs/:jobId/wait
+// @Summary      Wait for job completion
+// @Description  Long-polls a job until it reaches a terminal status or the timeout elapses, then returns its status either way -- check the status field to tell "finished" from "timed out" apart. Saves a polling client from repeatedly hitting GET /api/jobs/:jobId itself.
+// @Tags         Jobs
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Param        timeout query int false "Max seconds to wait (default 30, capped at 120)"
+// @Success      200 {object} model.RenderStatusResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/jobs/{jobId}/wait [get]
+func (h *JobsHandler) Wait(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	timeout := defaultJobWaitTimeout
+	if raw := c.QueryInt("timeout", 0); raw > 0 {
+		timeout = time.Duration(raw) * time.Second
+		if timeout > maxJobWaitTimeout {
+			timeout = maxJobWaitTimeout
+		}
+	}
+
+	result, err := h.renderService.WaitForTerminal(c.Context(), jobID, timeout)
+	if err != nil {
+		return response.FromError(c, err)
+	}
+
+	return response.OK(c, result)
+}
+
+// Cancel handles DELETE /api/jobs/:jobId
+// @Summary      Cancel job
+// @Description  Cancels a running or queued job, whatever its type (render, master, export)
+// @Tags         Jobs
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {object} model.RenderCancelResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      409 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/jobs/{jobId} [delete]
+func (h *JobsHandler) Cancel(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	result, err := h.renderService.CancelRender(c.Context(), jobID)
+	if err != nil {
+		var statusBehind *service.ErrJobStatusBehind
+		if errors.As(err, &statusBehind) {
+			return response.Error(c, fiber.StatusConflict, "JOB_STATUS_BEHIND",
+				fmt.Sprintf("Job already %s", statusBehind.CurrentStatus),
+				fiber.Map{"status": statusBehind.CurrentStatus})
+		}
+		return response.FromError(c, err)
+	}
+
+	return response.OK(c, result)
+}
@@ -9,13 +9,20 @@ import (
 )
 
 type LyricsHandler struct {
-	service   *service.LyricsService
+	service   service.LyricsGenerator
+	cache     service.LyricsCachePurger // nil if svc doesn't also satisfy it
 	validator *validator.Validate
 }
 
-func NewLyricsHandler(svc *service.LyricsService, v *validator.Validate) *LyricsHandler {
+// NewLyricsHandler creates a new lyrics handler. svc is typically a
+// *service.LyricsService, which delegates LyricsCachePurger to its
+// underlying cache and so makes purge available via PurgeCache; a svc with
+// no cache behind it (or none configured) leaves purge a no-op.
+func NewLyricsHandler(svc service.LyricsGenerator, v *validator.Validate) *LyricsHandler {
+	cache, _ := svc.(service.LyricsCachePurger)
 	return &LyricsHandler{
 		service:   svc,
+		cache:     cache,
 		validator: v,
 	}
 }
@@ -44,10 +51,14 @@ func (h *LyricsHandler) Generate(c *fiber.Ctx) error {
 		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
 	}
 
-	result, err := h.service.Generate(c.Context(), &req)
+	var cacheHit bool
+	ctx := service.WithCacheHitRecorder(c.Context(), &cacheHit)
+	result, err := h.service.Generate(ctx, &req)
 	if err != nil {
 		return response.AIError(c, err.Error())
 	}
+	c.Set("X-Cache", cacheHeaderValue(cacheHit))
+	result.Cached = cacheHit
 
 	return response.OK(c, result)
 }
@@ -76,14 +87,81 @@ func (h *LyricsHandler) Rewrite(c *fiber.Ctx) error {
 		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
 	}
 
-	result, err := h.service.Rewrite(c.Context(), &req)
+	var cacheHit bool
+	ctx := service.WithCacheHitRecorder(c.Context(), &cacheHit)
+	result, err := h.service.Rewrite(ctx, &req)
 	if err != nil {
 		return response.AIError(c, err.Error())
 	}
+	c.Set("X-Cache", cacheHeaderValue(cacheHit))
+	result.Cached = cacheHit
 
 	return response.OK(c, result)
 }
 
+// Export handles POST /api/lyrics/export
+// @Summary      Export time-synced lyrics
+// @Description  Build a time-synced lyrics file (LRC, enhanced LRC, SRT, or VTT) from a set of lyrics lines already anchored to render-pipeline section timing
+// @Tags         Lyrics
+// @Accept       json
+// @Produce      json
+// @Param        request body model.LyricsExportRequest true "Export request"
+// @Success      200 {object} model.LyricsExportResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/lyrics/export [post]
+func (h *LyricsHandler) Export(c *fiber.Ctx) error {
+	var req model.LyricsExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := service.BuildLyricsExport(&req)
+	if err != nil {
+		return response.ValidationError(c, err.Error(), nil)
+	}
+
+	return response.OK(c, result)
+}
+
+// PurgeCache handles DELETE /api/lyrics/cache
+// @Summary      Purge cached lyrics
+// @Description  Evict cached Groq lyrics generate/rewrite responses, optionally restricted to a key prefix. Admin-only.
+// @Tags         Lyrics
+// @Produce      json
+// @Param        prefix query string false "Only purge entries under lyrics:v1:<prefix> (default: purge everything)"
+// @Success      200 {object} model.LyricsCachePurgeResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      403 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/lyrics/cache [delete]
+func (h *LyricsHandler) PurgeCache(c *fiber.Ctx) error {
+	if h.cache == nil {
+		return response.OK(c, &model.LyricsCachePurgeResponse{PurgedCount: 0})
+	}
+
+	purged, err := h.cache.Purge(c.Context(), c.Query("prefix"))
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.OK(c, &model.LyricsCachePurgeResponse{PurgedCount: purged})
+}
+
+// cacheHeaderValue renders a cache hit/miss bool as the X-Cache header value.
+func cacheHeaderValue(hit bool) string {
+	if hit {
+		return "HIT"
+	}
+	return "MISS"
+}
+
 // formatValidationErrors formats validator errors for response
 func formatValidationErrors(err error) interface{} {
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
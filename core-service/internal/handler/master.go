@@ -3,20 +3,26 @@ package handler
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/makeasinger/api/internal/model"
 	"github.com/makeasinger/api/internal/service"
+	ws "github.com/makeasinger/api/internal/websocket"
 	"github.com/makeasinger/api/pkg/response"
 )
 
 type MasterHandler struct {
-	service   *service.MasterService
-	validator *validator.Validate
+	service       *service.MasterService
+	validator     *validator.Validate
+	hub           *ws.Hub
+	renderService *service.RenderService
 }
 
-func NewMasterHandler(svc *service.MasterService, v *validator.Validate) *MasterHandler {
+func NewMasterHandler(svc *service.MasterService, v *validator.Validate, hub *ws.Hub, renderService *service.RenderService) *MasterHandler {
 	return &MasterHandler{
-		service:   svc,
-		validator: v,
+		service:       svc,
+		validator:     v,
+		hub:           hub,
+		renderService: renderService,
 	}
 }
 
@@ -84,6 +90,81 @@ func (h *MasterHandler) Final(c *fiber.Ctx) error {
 	return response.Accepted(c, result)
 }
 
+// Batch handles POST /api/master/batch
+// @Summary      Start a batch of final mastering jobs
+// @Description  Submits multiple final mastering jobs sharing one group ID. Each item is validated independently: a bad entry is reported at its index (errors[].path prefixed with ["items", index]) instead of aborting the whole batch, and every valid item still starts its own job.
+// @Tags         Master
+// @Accept       json
+// @Produce      json
+// @Param        request body model.MasterBatchRequest true "Master batch request"
+// @Success      202 {object} model.MasterBatchResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/master/batch [post]
+func (h *MasterHandler) Batch(c *fiber.Ctx) error {
+	var req model.MasterBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+	if len(req.Items) == 0 {
+		return response.ValidationError(c, "At least one item is required", nil)
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	groupID := uuid.New().String()
+	items := make([]model.MasterBatchItem, len(req.Items))
+	validIdx := make([]int, 0, len(req.Items))
+
+	for i := range req.Items {
+		items[i] = model.MasterBatchItem{Index: i}
+
+		if err := h.validator.Struct(&req.Items[i]); err != nil {
+			verrs, ok := err.(validator.ValidationErrors)
+			if !ok {
+				return response.ValidationError(c, "Validation failed", nil)
+			}
+			items[i].Errors = batchItemErrors(i, response.ViolationsFrom(verrs))
+			continue
+		}
+		validIdx = append(validIdx, i)
+	}
+
+	if len(validIdx) > 0 {
+		valid := make([]model.MasterFinalRequest, len(validIdx))
+		for j, idx := range validIdx {
+			valid[j] = req.Items[idx]
+		}
+
+		started, err := h.service.StartBatch(c.Context(), groupID, valid)
+		if err != nil {
+			return response.FromError(c, err)
+		}
+		for j, idx := range validIdx {
+			items[idx].JobID = started[j].JobID
+			items[idx].Status = started[j].Status
+		}
+	}
+
+	return response.Accepted(c, &model.MasterBatchResponse{GroupID: groupID, Items: items})
+}
+
+// batchItemErrors converts one batch item's validation violations into
+// model.MasterBatchItemError, prefixing each violation's Path with this
+// item's location ("items", index) so a client can walk straight from the
+// batch envelope to the offending field in its original request body.
+func batchItemErrors(index int, violations []response.Violation) []model.MasterBatchItemError {
+	errs := make([]model.MasterBatchItemError, len(violations))
+	for i, v := range violations {
+		path := append([]interface{}{"items", index}, v.Path...)
+		errs[i] = model.MasterBatchItemError{Slug: v.Slug, Message: v.Message, Path: path}
+	}
+	return errs
+}
+
 // Status handles GET /api/master/status/:jobId
 // @Summary      Get master job status
 // @Description  Get the current status and progress of a mastering job
@@ -105,15 +186,56 @@ func (h *MasterHandler) Status(c *fiber.Ctx) error {
 
 	result, err := h.service.GetStatus(c.Context(), jobID)
 	if err != nil {
-		if err.Error() == "job not found" {
-			return response.NotFound(c, "Job not found")
-		}
-		return response.ServiceError(c, err.Error())
+		return response.FromError(c, err)
 	}
 
 	return response.OK(c, result)
 }
 
+// Events handles GET /api/master/events/:jobId (also bound at
+// GET /api/master/status/:jobId/stream, for clients that expect the
+// stream to live alongside Status rather than as its own resource).
+// @Summary      Stream master job progress
+// @Description  Server-Sent Events fallback for clients that can't hold a WebSocket open. Streams the same progress/status/done frames; send Last-Event-ID (or ?since=) to resume after a dropped connection.
+// @Tags         Master
+// @Produce      text/event-stream
+// @Param        jobId path string true "Job ID"
+// @Success      200 {string} string "text/event-stream"
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/master/events/{jobId} [get]
+// @Router       /api/master/status/{jobId}/stream [get]
+func (h *MasterHandler) Events(c *fiber.Ctx) error {
+	return streamJobEvents(c, h.hub, c.Params("jobId"))
+}
+
+// ReplayWebhook handles POST /api/master/:jobId/webhook/replay
+// @Summary      Replay a master job's webhook callback
+// @Description  Re-fires the CallbackURL callback for a terminal master job on demand -- useful when the receiver's endpoint was down for the original delivery window and its retries were already exhausted and archived. Admin/owner only.
+// @Tags         Master
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      204
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      403 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/master/{jobId}/webhook/replay [post]
+func (h *MasterHandler) ReplayWebhook(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return response.ValidationError(c, "Job ID is required", nil)
+	}
+
+	if err := h.renderService.ReplayWebhook(c.Context(), jobID); err != nil {
+		return response.FromError(c, err)
+	}
+
+	return response.NoContent(c)
+}
+
 // Result handles GET /api/master/result/:jobId
 // @Summary      Get master job result
 // @Description  Get the result of a completed mastering job
@@ -135,13 +257,7 @@ func (h *MasterHandler) Result(c *fiber.Ctx) error {
 
 	result, err := h.service.GetResult(c.Context(), jobID)
 	if err != nil {
-		if err.Error() == "job not found" {
-			return response.NotFound(c, "Job not found")
-		}
-		if err.Error() == "job not completed" {
-			return response.ValidationError(c, "Job not completed yet", nil)
-		}
-		return response.ServiceError(c, err.Error())
+		return response.FromError(c, err)
 	}
 
 	return response.OK(c, result)
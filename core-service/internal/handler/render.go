@@ -1,22 +1,32 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/makeasinger/api/internal/audit"
+	"github.com/makeasinger/api/internal/middleware"
 	"github.com/makeasinger/api/internal/model"
 	"github.com/makeasinger/api/internal/service"
+	ws "github.com/makeasinger/api/internal/websocket"
 	"github.com/makeasinger/api/pkg/response"
 )
 
 type RenderHandler struct {
 	service   *service.RenderService
 	validator *validator.Validate
+	audit     *audit.Recorder
+	hub       *ws.Hub
 }
 
-func NewRenderHandler(svc *service.RenderService, v *validator.Validate) *RenderHandler {
+func NewRenderHandler(svc *service.RenderService, v *validator.Validate, auditRecorder *audit.Recorder, hub *ws.Hub) *RenderHandler {
 	return &RenderHandler{
 		service:   svc,
 		validator: v,
+		audit:     auditRecorder,
+		hub:       hub,
 	}
 }
 
@@ -49,6 +59,12 @@ func (h *RenderHandler) Start(c *fiber.Ctx) error {
 		return response.ServiceError(c, err.Error())
 	}
 
+	h.audit.Record(c.Context(), audit.Event{
+		Type:   "render.submit",
+		UserID: middleware.GetUserID(c),
+		Fields: map[string]string{"jobId": result.JobID, "projectId": req.ProjectID},
+	})
+
 	return response.Accepted(c, result)
 }
 
@@ -73,10 +89,7 @@ func (h *RenderHandler) Status(c *fiber.Ctx) error {
 
 	result, err := h.service.GetStatus(c.Context(), jobID)
 	if err != nil {
-		if err.Error() == "job not found" {
-			return response.NotFound(c, "Job not found")
-		}
-		return response.ServiceError(c, err.Error())
+		return response.FromError(c, err)
 	}
 
 	return response.OK(c, result)
@@ -103,18 +116,27 @@ func (h *RenderHandler) Result(c *fiber.Ctx) error {
 
 	result, err := h.service.GetResult(c.Context(), jobID)
 	if err != nil {
-		if err.Error() == "job not found" {
-			return response.NotFound(c, "Job not found")
-		}
-		if err.Error() == "job not completed" {
-			return response.ValidationError(c, "Job not completed yet", nil)
-		}
-		return response.ServiceError(c, err.Error())
+		return response.FromError(c, err)
 	}
 
 	return response.OK(c, result)
 }
 
+// Events handles GET /api/render/events/:jobId
+// @Summary      Stream render job progress
+// @Description  Server-Sent Events fallback for clients that can't hold a WebSocket open to /ws/jobs/:jobId. Streams the same progress/status/done frames; send Last-Event-ID (or ?since=) to resume after a dropped connection.
+// @Tags         Render
+// @Produce      text/event-stream
+// @Param        jobId path string true "Job ID"
+// @Success      200 {string} string "text/event-stream"
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/render/events/{jobId} [get]
+func (h *RenderHandler) Events(c *fiber.Ctx) error {
+	return streamJobEvents(c, h.hub, c.Params("jobId"))
+}
+
 // Cancel handles POST /api/render/cancel/:jobId
 // @Summary      Cancel render job
 // @Description  Cancel a running or queued render job
@@ -125,6 +147,7 @@ func (h *RenderHandler) Result(c *fiber.Ctx) error {
 // @Failure      400 {object} response.ErrorResponse
 // @Failure      401 {object} response.ErrorResponse
 // @Failure      404 {object} response.ErrorResponse
+// @Failure      409 {object} response.ErrorResponse
 // @Failure      500 {object} response.ErrorResponse
 // @Security     BearerAuth
 // @Router       /api/render/cancel/{jobId} [post]
@@ -136,14 +159,20 @@ func (h *RenderHandler) Cancel(c *fiber.Ctx) error {
 
 	result, err := h.service.CancelRender(c.Context(), jobID)
 	if err != nil {
-		if err.Error() == "job not found" {
-			return response.NotFound(c, "Job not found")
-		}
-		if err.Error() == "job already completed" {
-			return response.ValidationError(c, "Job already completed", nil)
+		var statusBehind *service.ErrJobStatusBehind
+		if errors.As(err, &statusBehind) {
+			return response.Error(c, fiber.StatusConflict, "JOB_STATUS_BEHIND",
+				fmt.Sprintf("Job already %s", statusBehind.CurrentStatus),
+				fiber.Map{"status": statusBehind.CurrentStatus})
 		}
-		return response.ServiceError(c, err.Error())
+		return response.FromError(c, err)
 	}
 
+	h.audit.Record(c.Context(), audit.Event{
+		Type:   "render.cancel",
+		UserID: middleware.GetUserID(c),
+		Fields: map[string]string{"jobId": jobID},
+	})
+
 	return response.OK(c, result)
 }
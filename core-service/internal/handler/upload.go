@@ -1,22 +1,36 @@
 package handler
 
 import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/makeasinger/api/internal/audio/probe"
+	"github.com/makeasinger/api/internal/model"
 	"github.com/makeasinger/api/internal/service"
 	"github.com/makeasinger/api/pkg/response"
 )
 
 const maxUploadSize = 50 * 1024 * 1024 // 50MB
 
+// maxBatchUploadSize bounds the ZIP archive itself, not any single entry
+// inside it, since a batch can contain many takes at up to maxUploadSize each.
+const maxBatchUploadSize = 500 * 1024 * 1024 // 500MB
+
 type UploadHandler struct {
 	service   *service.UploadService
+	chunked   *service.ChunkedUploadService
 	validator *validator.Validate
 }
 
-func NewUploadHandler(svc *service.UploadService, v *validator.Validate) *UploadHandler {
+func NewUploadHandler(svc *service.UploadService, chunked *service.ChunkedUploadService, v *validator.Validate) *UploadHandler {
 	return &UploadHandler{
 		service:   svc,
+		chunked:   chunked,
 		validator: v,
 	}
 }
@@ -66,42 +80,278 @@ func (h *UploadHandler) Vocal(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate file type
-	contentType := file.Header.Get("Content-Type")
-	validTypes := map[string]bool{
-		"audio/wav":      true,
-		"audio/x-wav":    true,
-		"audio/wave":     true,
-		"audio/mpeg":     true,
-		"audio/mp3":      true,
-		"audio/mp4":      true,
-		"audio/x-m4a":    true,
-		"audio/aac":      true,
-		"audio/x-aac":    true,
+	// Open file
+	f, err := file.Open()
+	if err != nil {
+		return response.ServiceError(c, "Failed to open file")
+	}
+	defer f.Close()
+
+	// Sniff the real container/codec instead of trusting the client-supplied
+	// Content-Type, which is easily spoofed and can't distinguish ALAC from
+	// AAC inside an M4A, or catch a truncated upload.
+	probed, err := probe.Probe(f, file.Size)
+	if err != nil {
+		return validationErrorForProbeErr(c, err)
+	}
+
+	// Upload
+	result, err := h.service.UploadVocal(c.Context(), projectID, sectionID, takeName, f, file.Size, probed)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Created(c, result)
+}
+
+// validationErrorForProbeErr maps a probe error to a 400 response with a
+// message a client can act on, instead of a generic "invalid file" bounce.
+func validationErrorForProbeErr(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, probe.ErrUnsupportedCodec):
+		return response.ValidationError(c, "Unsupported audio codec. Supported: WAV (PCM), MP3, M4A (AAC/ALAC)", nil)
+	case errors.Is(err, probe.ErrSampleRateOutOfRange):
+		return response.ValidationError(c, "Sample rate must be between 16kHz and 96kHz", nil)
+	case errors.Is(err, probe.ErrTruncated), errors.Is(err, probe.ErrSizeMismatch):
+		return response.ValidationError(c, "File is truncated or corrupt", nil)
+	default:
+		return response.ValidationError(c, "Unrecognized audio file", nil)
+	}
+}
+
+// VocalsBatch handles POST /api/upload/vocals/batch
+// @Summary      Batch-upload vocal takes
+// @Description  Upload a ZIP archive containing multiple vocal takes plus a manifest.json mapping each entry to a project/section/take name. Per-file failures are reported per entry rather than failing the whole request.
+// @Tags         Upload
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file formData file true "ZIP archive (manifest.json + audio files; max 500MB)"
+// @Success      201 {object} model.UploadBatchResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      429 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocals/batch [post]
+func (h *UploadHandler) VocalsBatch(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return response.ValidationError(c, "File is required", nil)
 	}
 
-	if !validTypes[contentType] {
-		return response.ValidationError(c, "Invalid file type. Supported: WAV, M4A, MP3, AAC", map[string]interface{}{
-			"contentType": contentType,
+	if file.Size > maxBatchUploadSize {
+		return response.ValidationError(c, "Archive size exceeds 500MB limit", map[string]interface{}{
+			"maxSize":  maxBatchUploadSize,
+			"fileSize": file.Size,
 		})
 	}
 
-	// Open file
 	f, err := file.Open()
 	if err != nil {
 		return response.ServiceError(c, "Failed to open file")
 	}
 	defer f.Close()
 
-	// Upload
-	result, err := h.service.UploadVocal(c.Context(), projectID, sectionID, takeName, f, file.Size)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return response.ServiceError(c, "Failed to read file")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return response.ValidationError(c, "File is not a valid ZIP archive", nil)
+	}
+
+	result, err := h.service.UploadVocalsBatch(c.Context(), zr)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+
+	return response.Created(c, result)
+}
+
+// PresignVocal handles POST /api/upload/vocal/presign
+// @Summary      Presign a direct vocal upload
+// @Description  Returns a presigned URL the client can PUT a vocal take to directly, bypassing this API's request body limit. Call POST /api/upload/vocal/complete/{id} once the PUT finishes.
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        request body model.PresignVocalUploadRequest true "Presign request"
+// @Success      201 {object} model.PresignVocalUploadResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocal/presign [post]
+func (h *UploadHandler) PresignVocal(c *fiber.Ctx) error {
+	var req model.PresignVocalUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.service.PresignVocalUpload(c.Context(), &req)
 	if err != nil {
+		return presignUploadError(c, err)
+	}
+
+	return response.Created(c, result)
+}
+
+// CompletePresignedVocal handles POST /api/upload/vocal/complete/:id
+// @Summary      Confirm a presigned direct upload
+// @Description  Confirms the object a prior /presign call reserved actually landed in storage with the declared size before the take is considered uploaded.
+// @Tags         Upload
+// @Produce      json
+// @Param        id path string true "Take ID returned by /presign"
+// @Success      201 {object} model.UploadVocalResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      409 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocal/complete/{id} [post]
+func (h *UploadHandler) CompletePresignedVocal(c *fiber.Ctx) error {
+	result, err := h.service.CompletePresignedVocalUpload(c.Context(), c.Params("id"))
+	if err != nil {
+		return presignUploadError(c, err)
+	}
+	return response.Created(c, result)
+}
+
+// presignUploadError maps UploadService's presigned-upload sentinel errors
+// to the HTTP status a direct-upload client needs to distinguish a bad
+// request from an upload that simply hasn't landed yet.
+func presignUploadError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPresignContentTypeUnsupported):
+		return response.ValidationError(c, "Unsupported content type for direct upload", nil)
+	case errors.Is(err, service.ErrPresignUploadTooLarge):
+		return response.ValidationError(c, "Content length exceeds the direct-upload size limit", nil)
+	case errors.Is(err, service.ErrPresignSessionNotFound):
+		return response.NotFound(c, "Presigned upload not found")
+	case errors.Is(err, service.ErrPresignUploadIncomplete):
+		return response.Conflict(c, "Upload has not landed in storage yet")
+	case errors.Is(err, service.ErrPresignSizeMismatch):
+		return response.ValidationError(c, "Uploaded object size does not match the declared content length", nil)
+	default:
 		return response.ServiceError(c, err.Error())
 	}
+}
+
+// InitChunkedVocal handles POST /api/upload/vocal/init
+// @Summary      Start a resumable vocal upload
+// @Description  Starts a tus.io-style resumable upload backed by an S3 multipart upload. The client PATCHes chunks of at least MinChunkSize to /api/upload/vocal/:uploadId, then calls .../complete. If contentSha256 is supplied and an object with that digest already exists, the response is deduplicated=true with no uploadId and the caller skips straight to using fileUrl.
+// @Tags         Upload
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ChunkedUploadInitRequest true "Init request"
+// @Success      201 {object} model.ChunkedUploadInitResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      401 {object} response.ErrorResponse
+// @Failure      500 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocal/init [post]
+func (h *UploadHandler) InitChunkedVocal(c *fiber.Ctx) error {
+	var req model.ChunkedUploadInitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationError(c, "Invalid request body", nil)
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.ValidationError(c, "Validation failed", formatValidationErrors(err))
+	}
+
+	result, err := h.chunked.Init(c.Context(), &req)
+	if err != nil {
+		return response.ServiceError(c, err.Error())
+	}
+	return response.Created(c, result)
+}
+
+// HeadChunkedVocal handles HEAD /api/upload/vocal/:uploadId
+// @Summary      Get a resumable upload's committed offset
+// @Description  Returns how many bytes of the upload have been received, so a client can resume a dropped upload without re-sending them.
+// @Tags         Upload
+// @Produce      json
+// @Param        uploadId path string true "Upload ID"
+// @Success      200 {object} model.ChunkedUploadOffsetResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocal/{uploadId} [head]
+func (h *UploadHandler) HeadChunkedVocal(c *fiber.Ctx) error {
+	result, err := h.chunked.Offset(c.Context(), c.Params("uploadId"))
+	if err != nil {
+		return chunkedUploadError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(result.Offset, 10))
+	return response.OK(c, result)
+}
+
+// PatchChunkedVocal handles PATCH /api/upload/vocal/:uploadId
+// @Summary      Append a chunk to a resumable upload
+// @Description  Appends the request body, starting at Upload-Offset, as one S3 multipart-upload part. Every part but the final one must be at least MinChunkSize bytes (an S3 constraint).
+// @Tags         Upload
+// @Accept       application/offset+octet-stream
+// @Produce      json
+// @Param        uploadId path string true "Upload ID"
+// @Param        Upload-Offset header int true "Byte offset this chunk starts at"
+// @Success      200 {object} model.ChunkedUploadOffsetResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Failure      409 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocal/{uploadId} [patch]
+func (h *UploadHandler) PatchChunkedVocal(c *fiber.Ctx) error {
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return response.ValidationError(c, "Upload-Offset header is required", nil)
+	}
 
+	result, err := h.chunked.Patch(c.Context(), c.Params("uploadId"), offset, c.Body())
+	if err != nil {
+		return chunkedUploadError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(result.Offset, 10))
+	return response.OK(c, result)
+}
+
+// CompleteChunkedVocal handles POST /api/upload/vocal/:uploadId/complete
+// @Summary      Finalize a resumable upload
+// @Description  Assembles the uploaded parts into the final object once the full size has been received.
+// @Tags         Upload
+// @Produce      json
+// @Param        uploadId path string true "Upload ID"
+// @Success      201 {object} model.UploadVocalResponse
+// @Failure      400 {object} response.ErrorResponse
+// @Failure      404 {object} response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/upload/vocal/{uploadId}/complete [post]
+func (h *UploadHandler) CompleteChunkedVocal(c *fiber.Ctx) error {
+	result, err := h.chunked.Complete(c.Context(), c.Params("uploadId"))
+	if err != nil {
+		return chunkedUploadError(c, err)
+	}
 	return response.Created(c, result)
 }
 
+// chunkedUploadError maps ChunkedUploadService's sentinel errors to the HTTP
+// status a resumable-upload client needs to distinguish "doesn't exist"
+// from "we're out of sync" from any other failure.
+func chunkedUploadError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrChunkedUploadNotFound):
+		return response.NotFound(c, "Upload not found")
+	case errors.Is(err, service.ErrChunkedUploadOffsetMismatch):
+		return response.Conflict(c, "Upload offset out of sync; HEAD the upload to resync")
+	case errors.Is(err, service.ErrChunkedUploadDigestMismatch):
+		return response.ValidationError(c, "Uploaded content does not match the declared contentSha256", nil)
+	default:
+		return response.ServiceError(c, err.Error())
+	}
+}
+
 // DeleteVocal handles DELETE /api/upload/vocal/:takeId
 // @Summary      Delete vocal take
 // @Description  Delete a previously uploaded vocal take
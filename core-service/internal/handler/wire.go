@@ -0,0 +1,17 @@
+package handler
+
+import "github.com/google/wire"
+
+// ProviderSet wires the handlers that only depend on other wired
+// components.
+var ProviderSet = wire.NewSet(
+	NewLyricsHandler,
+	NewRenderHandler,
+	NewMasterHandler,
+	NewExportHandler,
+	NewUploadHandler,
+	NewJobsHandler,
+	NewAdminHandler,
+	NewAuthHandler,
+	NewInternalJobsHandler,
+)
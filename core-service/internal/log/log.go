@@ -0,0 +1,141 @@
+// Package log provides a structured, context-propagating logging helper
+// built on slog. Middleware and job handlers attach request-scoped fields
+// (requestId, jobId, projectId, userId, ...) to a context.Context; every
+// call site then logs through FromContext/Info/Error instead of the stdlib
+// log package, so every line tied to a request or job carries the same
+// correlation fields automatically.
+package log
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/makeasinger/api/internal/config"
+)
+
+type ctxKey struct{}
+
+var base = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// Init configures the package-level base logger from server config, leveled
+// by cfg.LogLevel. Output format is cfg.LogFormat ("json" for log
+// aggregators, "console" for human-readable text) when set; otherwise it
+// falls back to JSON in production and console everywhere else, so
+// deployments that only set SERVER_ENV keep their current behavior.
+// cfg.LogSampling, if > 0, keeps only 1 in every N Info/Debug lines across
+// the whole process; Warn/Error always pass through uncounted.
+// cmd/server and cmd/runner call this once at startup, before anything else
+// logs.
+func Init(cfg config.ServerConfig) {
+	opts := &slog.HandlerOptions{Level: parseLevel(cfg.LogLevel)}
+
+	useJSON := strings.EqualFold(cfg.Env, "production")
+	switch strings.ToLower(cfg.LogFormat) {
+	case "json":
+		useJSON = true
+	case "console":
+		useJSON = false
+	}
+
+	var handler slog.Handler
+	if useJSON {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	if cfg.LogSampling > 0 {
+		handler = &samplingHandler{Handler: handler, every: uint64(cfg.LogSampling), counter: new(atomic.Uint64)}
+	}
+	base = slog.New(handler)
+}
+
+// samplingHandler drops all but 1 in every `every` Info/Debug records, so a
+// hot path logging on every request doesn't flood the log aggregator; Warn
+// and Error always pass through, since those are rare and worth seeing in
+// full.
+type samplingHandler struct {
+	slog.Handler
+	every   uint64
+	counter *atomic.Uint64 // shared with every handler derived via WithAttrs/WithGroup
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		return h.Handler.Handle(ctx, r)
+	}
+	if h.counter.Add(1)%h.every != 0 {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), every: h.every, counter: h.counter}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), every: h.every, counter: h.counter}
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithFields returns a context carrying a logger with kv (alternating
+// key/value pairs, as accepted by slog) attached on top of whatever fields
+// ctx already carries. Subsequent FromContext/Info/Error calls against the
+// returned context include all of them.
+func WithFields(ctx context.Context, kv ...any) context.Context {
+	return context.WithValue(ctx, ctxKey{}, FromContext(ctx).With(kv...))
+}
+
+// FromContext returns the logger carrying ctx's correlation fields, or the
+// package-level base logger if none have been attached.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return base
+}
+
+// Debug logs msg at debug level through the logger attached to ctx.
+func Debug(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Debug(msg, kv...)
+}
+
+// Info logs msg at info level through the logger attached to ctx.
+func Info(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Info(msg, kv...)
+}
+
+// Warn logs msg at warn level through the logger attached to ctx.
+func Warn(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Warn(msg, kv...)
+}
+
+// Error logs msg at error level through the logger attached to ctx. Callers
+// should pass the error as the "err" field, e.g. Error(ctx, "...", "err", err).
+func Error(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Error(msg, kv...)
+}
+
+// Fatal logs msg at error level through the logger attached to ctx, then
+// exits the process with status 1. Reserved for startup failures a service
+// can't recover from (matching the stdlib log.Fatalf calls it replaces in
+// cmd/server and cmd/runner).
+func Fatal(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Error(msg, kv...)
+	os.Exit(1)
+}
@@ -0,0 +1,27 @@
+// Package lyrics holds the individual sources service.LyricsService can
+// draw lyrics from -- Groq generation, an LRCLIB seed-track lookup, and a
+// per-project filesystem override -- so the service can fall back across
+// them instead of being hardwired to a single provider.
+package lyrics
+
+import (
+	"context"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// Agent is one source in service.LyricsService's fallback chain. Returning
+// (nil, nil) means the agent had nothing to contribute for this request
+// (e.g. no seed track, no file on disk) -- not an error -- and the chain
+// should move on to the next agent; a non-nil error means something went
+// wrong trying to find out, which the chain logs and also treats as a
+// reason to move on, unless it's the last agent.
+type Agent interface {
+	// Name identifies the agent in config (Config.Lyrics.Agents) and logs.
+	Name() string
+	// Priority is this agent's default position in the chain (lower runs
+	// earlier) when config doesn't explicitly order it.
+	Priority() int
+	Generate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error)
+	Rewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error)
+}
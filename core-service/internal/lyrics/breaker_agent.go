@@ -0,0 +1,116 @@
+package lyrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+var agentBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "lyrics_agent_breaker_state",
+	Help: "Circuit breaker state per lyrics agent (0=closed, 1=open, 2=half-open).",
+}, []string{"agent"})
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// BreakerAgent wraps a network-backed Agent (Groq, LRCLIB) with a circuit
+// breaker: after threshold consecutive failures it stops calling inner
+// entirely, returning (nil, nil) -- "no contribution" -- until cooldown has
+// elapsed, rather than an error. That lets the chain degrade straight to the
+// next agent (ultimately Groq's own mock fallback) instead of retrying a
+// backend that's down on every request.
+type BreakerAgent struct {
+	inner     Agent
+	threshold int
+	cooldown  time.Duration
+
+	mu                  sync.Mutex
+	state               breakerState
+	consecutiveFailures int
+	openedAt            time.Time
+	stateGauge          prometheus.Gauge
+}
+
+// NewBreakerAgent wraps inner with a circuit breaker: threshold consecutive
+// failures opens it, cooldown is how long before a half-open probe is
+// allowed again.
+func NewBreakerAgent(inner Agent, threshold int, cooldown time.Duration) *BreakerAgent {
+	return &BreakerAgent{
+		inner:      inner,
+		threshold:  threshold,
+		cooldown:   cooldown,
+		stateGauge: agentBreakerState.WithLabelValues(inner.Name()),
+	}
+}
+
+func (a *BreakerAgent) Name() string  { return a.inner.Name() }
+func (a *BreakerAgent) Priority() int { return a.inner.Priority() }
+
+func (a *BreakerAgent) Generate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error) {
+	if !a.allow() {
+		return nil, nil
+	}
+	resp, err := a.inner.Generate(ctx, req)
+	a.record(err)
+	return resp, err
+}
+
+func (a *BreakerAgent) Rewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error) {
+	if !a.allow() {
+		return nil, nil
+	}
+	resp, err := a.inner.Rewrite(ctx, req)
+	a.record(err)
+	return resp, err
+}
+
+// allow reports whether a call may reach inner, flipping Open to HalfOpen
+// once cooldown has elapsed so exactly one probe request gets through.
+func (a *BreakerAgent) allow() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.state == breakerClosed {
+		return true
+	}
+	if a.state == breakerHalfOpen {
+		// The probe is still in flight; record will close or reopen.
+		return false
+	}
+	if time.Since(a.openedAt) < a.cooldown {
+		return false
+	}
+	a.state = breakerHalfOpen
+	a.stateGauge.Set(2)
+	return true
+}
+
+func (a *BreakerAgent) record(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err == nil {
+		a.consecutiveFailures = 0
+		a.state = breakerClosed
+		a.stateGauge.Set(0)
+		return
+	}
+
+	a.consecutiveFailures++
+	if a.state == breakerHalfOpen || a.consecutiveFailures >= a.threshold {
+		a.state = breakerOpen
+		a.openedAt = time.Now()
+		a.stateGauge.Set(1)
+	}
+}
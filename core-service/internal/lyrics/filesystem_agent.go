@@ -0,0 +1,66 @@
+package lyrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// filesystemExtensions is the order FilesystemAgent checks for a project's
+// stored lyrics file.
+var filesystemExtensions = []string{".lrc", ".txt"}
+
+// FilesystemAgent serves lyrics already written to disk for a project --
+// `<root>/<projectId>.lrc` or `<root>/<projectId>.txt`, in that order --
+// rather than generating or rewriting anything. It's meant for projects
+// whose lyrics came from the band rather than an AI provider, and for
+// offline/dev mode where there's no provider to call at all.
+type FilesystemAgent struct {
+	root string
+}
+
+// NewFilesystemAgent creates a filesystem-backed lyrics agent rooted at
+// root. An empty root disables the agent (every lookup returns (nil, nil)).
+func NewFilesystemAgent(root string) *FilesystemAgent {
+	return &FilesystemAgent{root: root}
+}
+
+func (a *FilesystemAgent) Name() string  { return "filesystem" }
+func (a *FilesystemAgent) Priority() int { return 10 }
+
+func (a *FilesystemAgent) Generate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error) {
+	text, err := a.read(req.ProjectID)
+	if err != nil || text == "" {
+		return nil, err
+	}
+	return &model.LyricsGenerateResponse{Drafts: [][]string{splitLines(text)}}, nil
+}
+
+func (a *FilesystemAgent) Rewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error) {
+	text, err := a.read(req.ProjectID)
+	if err != nil || text == "" {
+		return nil, err
+	}
+	return &model.LyricsRewriteResponse{Lines: splitLines(text)}, nil
+}
+
+// read returns the stored lyrics text for projectID, or "" if the agent
+// isn't configured, no projectID was given, or no file exists for it.
+func (a *FilesystemAgent) read(projectID string) (string, error) {
+	if a.root == "" || projectID == "" {
+		return "", nil
+	}
+
+	for _, ext := range filesystemExtensions {
+		data, err := os.ReadFile(filepath.Join(a.root, projectID+ext))
+		if err == nil {
+			return stripLRCTimestamps(string(data)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", nil
+}
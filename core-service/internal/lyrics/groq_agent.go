@@ -0,0 +1,218 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/model"
+)
+
+// GroqAgent generates/rewrites lyrics with Groq AI. It's meant to sit last
+// in the chain: unlike the other agents it always produces something (a
+// mock draft when groqClient isn't configured), so it never defers to a
+// later agent.
+type GroqAgent struct {
+	groqClient *client.GroqClient
+}
+
+// NewGroqAgent creates a new Groq-backed lyrics agent.
+func NewGroqAgent(groqClient *client.GroqClient) *GroqAgent {
+	return &GroqAgent{groqClient: groqClient}
+}
+
+func (a *GroqAgent) Name() string  { return "groq" }
+func (a *GroqAgent) Priority() int { return 100 }
+
+// Generate creates new lyrics based on the given parameters
+func (a *GroqAgent) Generate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error) {
+	language := req.Language
+	if language == "" {
+		language = model.LanguageEN
+	}
+
+	// Use mock response if client is not configured
+	if a.groqClient == nil || !a.groqClient.IsConfigured() {
+		return a.generateMock(req)
+	}
+
+	systemPrompt := a.buildSystemPrompt(language)
+	userPrompt := a.buildGeneratePrompt(req, language)
+
+	response, err := a.groqClient.ChatCompletion(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("AI generation failed: %w", err)
+	}
+
+	drafts, err := a.parseGenerateResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+
+	return &model.LyricsGenerateResponse{
+		Drafts: drafts,
+	}, nil
+}
+
+// Rewrite rewrites existing lyrics based on the given parameters
+func (a *GroqAgent) Rewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error) {
+	// Use mock response if client is not configured
+	if a.groqClient == nil || !a.groqClient.IsConfigured() {
+		return a.rewriteMock(req)
+	}
+
+	systemPrompt := a.buildSystemPrompt(model.LanguageEN)
+	userPrompt := a.buildRewritePrompt(req)
+
+	response, err := a.groqClient.ChatCompletion(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("AI rewrite failed: %w", err)
+	}
+
+	lines, err := a.parseRewriteResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+
+	return &model.LyricsRewriteResponse{
+		Lines: lines,
+	}, nil
+}
+
+func (a *GroqAgent) buildSystemPrompt(language model.Language) string {
+	langName := "English"
+	switch language {
+	case model.LanguageTR:
+		langName = "Turkish"
+	case model.LanguageFR:
+		langName = "French"
+	}
+
+	return fmt.Sprintf(`You are a professional %s songwriter with expertise in various music genres.
+Your task is to write compelling, emotionally resonant lyrics that match the requested style and mood.
+Always output your response as valid JSON in the exact format requested.
+Do not include any text outside the JSON structure.`, langName)
+}
+
+func (a *GroqAgent) buildGeneratePrompt(req *model.LyricsGenerateRequest, language model.Language) string {
+	vibes := strings.Join(req.Vibes, ", ")
+
+	return fmt.Sprintf(`Generate lyrics for a %s song's %s section.
+Vibes/mood: %s
+Language: %s
+
+Create 2 different draft versions. Each draft should have 4-8 lines that fit the section type.
+For a verse: tell a story or set the scene.
+For a chorus: create a memorable, singable hook.
+For a bridge: provide contrast or a new perspective.
+For other sections: follow conventions of that section type.
+
+Output as JSON: {"drafts": [["line1","line2","line3","line4"], ["line1","line2","line3","line4"]]}`,
+		req.Genre, req.SectionType, vibes, language)
+}
+
+func (a *GroqAgent) buildRewritePrompt(req *model.LyricsRewriteRequest) string {
+	vibes := strings.Join(req.Vibes, ", ")
+
+	instructions := ""
+	if req.Instructions != "" {
+		instructions = fmt.Sprintf("\nSpecific instructions: %s", req.Instructions)
+	}
+
+	return fmt.Sprintf(`Rewrite the following lyrics for a %s song's %s section.
+Current vibes/mood: %s%s
+
+Current lyrics:
+%s
+
+Keep the general meaning but improve the flow, rhyming, and emotional impact.
+Maintain the same number of lines.
+
+Output as JSON: {"lines": ["line1","line2","line3","line4"]}`,
+		req.Genre, req.SectionType, vibes, instructions, req.CurrentLyrics)
+}
+
+func (a *GroqAgent) parseGenerateResponse(response string) ([][]string, error) {
+	response = extractJSON(response)
+
+	var result struct {
+		Drafts [][]string `json:"drafts"`
+	}
+
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+
+	if len(result.Drafts) == 0 {
+		return nil, fmt.Errorf("no drafts in response")
+	}
+
+	return result.Drafts, nil
+}
+
+func (a *GroqAgent) parseRewriteResponse(response string) ([]string, error) {
+	response = extractJSON(response)
+
+	var result struct {
+		Lines []string `json:"lines"`
+	}
+
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+
+	if len(result.Lines) == 0 {
+		return nil, fmt.Errorf("no lines in response")
+	}
+
+	return result.Lines, nil
+}
+
+// extractJSON attempts to extract JSON from a response that may contain extra text
+func extractJSON(s string) string {
+	// Find the first { and last }
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+
+	if start != -1 && end != -1 && end > start {
+		return s[start : end+1]
+	}
+	return s
+}
+
+// Mock implementations for development/testing
+func (a *GroqAgent) generateMock(req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error) {
+	drafts := [][]string{
+		{
+			"Walking through the city lights",
+			"Feeling like we own the night",
+			"Nothing's gonna bring us down",
+			"We're the kings without a crown",
+		},
+		{
+			"Stars are shining up above",
+			"This is what we're dreaming of",
+			"Every moment feels so right",
+			"Dancing till the morning light",
+		},
+	}
+
+	return &model.LyricsGenerateResponse{
+		Drafts: drafts,
+	}, nil
+}
+
+func (a *GroqAgent) rewriteMock(req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error) {
+	lines := []string{
+		"Wandering through the silent night",
+		"Memories fading from my sight",
+		"Tears falling like the rain",
+		"Searching for what will remain",
+	}
+
+	return &model.LyricsRewriteResponse{
+		Lines: lines,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// lrclibDefaultBaseURL is LRCLIB's public API.
+const lrclibDefaultBaseURL = "https://lrclib.net/api"
+
+// LRCLIBAgent looks up existing lyrics for a seed artist/title from LRCLIB
+// instead of generating new ones -- useful when the user already has a
+// reference track in mind. It only contributes when the request names a
+// seed track; Rewrite has no LRCLIB equivalent (rewriting starts from
+// lyrics text the caller already has, not a seed track) so it always
+// defers to the next agent.
+type LRCLIBAgent struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewLRCLIBAgent creates an LRCLIB-backed lyrics agent. An empty baseURL
+// falls back to LRCLIB's public API.
+func NewLRCLIBAgent(baseURL string) *LRCLIBAgent {
+	if baseURL == "" {
+		baseURL = lrclibDefaultBaseURL
+	}
+	return &LRCLIBAgent{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    baseURL,
+	}
+}
+
+func (a *LRCLIBAgent) Name() string  { return "lrclib" }
+func (a *LRCLIBAgent) Priority() int { return 20 }
+
+// Generate looks up req.SeedArtist/SeedTitle on LRCLIB and returns its
+// lyrics as a single draft, one line per slice element. Returns (nil, nil)
+// when no seed track was given or LRCLIB has nothing for it.
+func (a *LRCLIBAgent) Generate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error) {
+	if req.SeedArtist == "" || req.SeedTitle == "" {
+		return nil, nil
+	}
+
+	text, err := a.fetchLyrics(ctx, req.SeedArtist, req.SeedTitle)
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	return &model.LyricsGenerateResponse{Drafts: [][]string{splitLines(text)}}, nil
+}
+
+// Rewrite always defers -- see the type doc comment.
+func (a *LRCLIBAgent) Rewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error) {
+	return nil, nil
+}
+
+func (a *LRCLIBAgent) fetchLyrics(ctx context.Context, artist, title string) (string, error) {
+	endpoint := fmt.Sprintf("%s/get?artist_name=%s&track_name=%s", a.baseURL, url.QueryEscape(artist), url.QueryEscape(title))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("lrclib request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("lrclib returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		PlainLyrics  string `json:"plainLyrics"`
+		SyncedLyrics string `json:"syncedLyrics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode lrclib response: %w", err)
+	}
+
+	if result.PlainLyrics != "" {
+		return result.PlainLyrics, nil
+	}
+	return stripLRCTimestamps(result.SyncedLyrics), nil
+}
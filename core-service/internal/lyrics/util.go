@@ -0,0 +1,27 @@
+package lyrics
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lrcTimestampPattern matches LRC's [mm:ss.xx] (or [mm:ss]) line tags, for
+// agents that serve a stored .lrc file as plain drafts/lines.
+var lrcTimestampPattern = regexp.MustCompile(`\[\d{2}:\d{2}(?:\.\d{1,3})?\]`)
+
+// stripLRCTimestamps removes LRC timestamp tags, leaving plain lyric text.
+func stripLRCTimestamps(lrc string) string {
+	return strings.TrimSpace(lrcTimestampPattern.ReplaceAllString(lrc, ""))
+}
+
+// splitLines splits text into its non-empty, trimmed lines.
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -1,21 +1,53 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
 	"github.com/makeasinger/api/internal/auth"
+	"github.com/makeasinger/api/internal/log"
 	"github.com/makeasinger/api/pkg/response"
 )
 
+// authFailuresTotal counts rejected Authenticate() calls, by reason, so a
+// spike in invalid/expired/revoked tokens shows up on a dashboard instead of
+// only in request logs.
+var authFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_middleware_failures_total",
+	Help: "Total requests rejected by AuthMiddleware.Authenticate, by reason.",
+}, []string{"reason"})
+
 // UserClaims is an alias for auth.LegacyClaims for backwards compatibility
 type UserClaims = auth.LegacyClaims
 
 // AuthMiddleware handles JWT authentication
 type AuthMiddleware struct {
-	verifier  auth.TokenVerifier
-	jwtSecret string // fallback for legacy tokens
+	verifier   auth.TokenVerifier
+	jwtSecret  string               // fallback for legacy tokens
+	revocation auth.RevocationStore // may be nil, in which case no revocation check is performed
+	clock      auth.Clock           // nil means auth.ValidateLegacyToken's default wall clock
+}
+
+// WithClock swaps the Clock used to evaluate the legacy HMAC path's
+// exp/nbf, for tests that need to advance past a token's expiry without
+// sleeping in real time. Returns m for chaining at the construction site.
+func (m *AuthMiddleware) WithClock(clk auth.Clock) *AuthMiddleware {
+	m.clock = clk
+	return m
+}
+
+// WithRevocationStore attaches a RevocationStore so the legacy HMAC path
+// rejects a token whose jti has been revoked via POST /api/auth/revoke. The
+// JWKS path does not consult this store; it relies on the verifier's own
+// revocation check. Returns m for chaining at the construction site.
+func (m *AuthMiddleware) WithRevocationStore(store auth.RevocationStore) *AuthMiddleware {
+	m.revocation = store
+	return m
 }
 
 // NewAuthMiddleware creates a new auth middleware with Zitadel JWKS verification
@@ -45,11 +77,13 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
+			authFailuresTotal.WithLabelValues("missing_header").Inc()
 			return response.Unauthorized(c, "Missing authorization header")
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			authFailuresTotal.WithLabelValues("malformed_header").Inc()
 			return response.Unauthorized(c, "Invalid authorization header format")
 		}
 
@@ -57,16 +91,18 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 
 		// Try Zitadel JWKS verification first
 		if m.verifier != nil {
-			claims, err := m.verifier.Validate(tokenString)
+			claims, err := m.verifier.Validate(c.UserContext(), tokenString)
 			if err == nil {
 				c.Locals("userId", claims.UserID)
 				c.Locals("email", claims.Email)
 				c.Locals("name", claims.Name)
 				c.Locals("claims", claims)
+				c.SetUserContext(log.WithFields(c.UserContext(), "userId", claims.UserID))
 				return c.Next()
 			}
 			// If JWKS verification fails and no fallback, return error
 			if m.jwtSecret == "" {
+				authFailuresTotal.WithLabelValues("invalid_token").Inc()
 				return response.Unauthorized(c, "Invalid or expired token")
 			}
 		}
@@ -75,21 +111,44 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 		if m.jwtSecret != "" {
 			claims, err := m.validateLegacyToken(tokenString)
 			if err != nil {
+				authFailuresTotal.WithLabelValues("invalid_token").Inc()
 				return response.Unauthorized(c, "Invalid or expired token")
 			}
 
+			if revoked, err := m.isRevoked(c.UserContext(), claims.ID); err != nil {
+				return response.ServiceError(c, "Failed to check token revocation")
+			} else if revoked {
+				authFailuresTotal.WithLabelValues("revoked").Inc()
+				return response.Unauthorized(c, "Token has been revoked")
+			}
+
 			c.Locals("userId", claims.UserID)
 			c.Locals("email", claims.Email)
 			c.Locals("claims", claims)
+			c.SetUserContext(log.WithFields(c.UserContext(), "userId", claims.UserID))
 			return c.Next()
 		}
 
+		authFailuresTotal.WithLabelValues("not_configured").Inc()
 		return response.Unauthorized(c, "Authentication not configured")
 	}
 }
 
+// isRevoked consults the revocation store for jti, treating an unset store
+// or an empty jti (legacy tokens minted before revocation support may lack
+// one) as not revoked.
+func (m *AuthMiddleware) isRevoked(ctx context.Context, jti string) (bool, error) {
+	if m.revocation == nil || jti == "" {
+		return false, nil
+	}
+	return m.revocation.IsRevoked(ctx, jti)
+}
+
 // validateLegacyToken validates a token using HMAC signing
 func (m *AuthMiddleware) validateLegacyToken(tokenString string) (*UserClaims, error) {
+	if m.clock != nil {
+		return auth.ValidateLegacyTokenWithClock(tokenString, m.jwtSecret, m.clock)
+	}
 	return auth.ValidateLegacyToken(tokenString, m.jwtSecret)
 }
 
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// RoleScoper is implemented by both auth.Claims (JWKS/Federated) and
+// auth.LegacyClaims (HMAC fallback) so RequireRole/RequireScope can enforce
+// authorization regardless of which path authenticated the request.
+type RoleScoper interface {
+	GetRoles() []string
+	GetScopes() []string
+}
+
+// RequireRole returns middleware that 403s unless the authenticated
+// request's claims carry at least one of roles. Must run after
+// AuthMiddleware.Authenticate, which populates c.Locals("claims").
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(RoleScoper)
+		if !ok {
+			return response.Forbidden(c, "Missing or unrecognized authentication claims")
+		}
+		if !containsAny(claims.GetRoles(), roles) {
+			return response.Forbidden(c, "Insufficient role")
+		}
+		return c.Next()
+	}
+}
+
+// RequireScope returns middleware that 403s unless the authenticated
+// request's claims carry at least one of scopes. Must run after
+// AuthMiddleware.Authenticate, which populates c.Locals("claims").
+func RequireScope(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(RoleScoper)
+		if !ok {
+			return response.Forbidden(c, "Missing or unrecognized authentication claims")
+		}
+		if !containsAny(claims.GetScopes(), scopes) {
+			return response.Forbidden(c, "Insufficient scope")
+		}
+		return c.Next()
+	}
+}
+
+// containsAny reports whether any element of a is also in b.
+func containsAny(a, b []string) bool {
+	for _, item := range a {
+		for _, want := range b {
+			if item == want {
+				return true
+			}
+		}
+	}
+	return false
+}
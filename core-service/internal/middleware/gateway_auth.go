@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/makeasinger/api/internal/log"
 	"github.com/makeasinger/api/pkg/response"
 )
 
@@ -17,6 +18,7 @@ func GatewayAuthMiddleware() fiber.Handler {
 		c.Locals("userId", userID)
 		c.Locals("email", c.Get("X-User-Email"))
 		c.Locals("name", c.Get("X-User-Name"))
+		c.SetUserContext(log.WithFields(c.UserContext(), "userId", userID))
 
 		return c.Next()
 	}
@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/errs"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// idempotencyTTL bounds how long a replayed response (or an in-flight
+// reservation that's stuck because its handler never completed) stays
+// around for a given Idempotency-Key. A day covers the retry windows
+// clients actually hit (dropped connections, timeouts on the first
+// attempt) without keeping every key forever.
+const idempotencyTTL = 24 * time.Hour
+
+// idempotencyState tracks whether a key's record is still waiting on its
+// first request to finish or already holds that request's response.
+type idempotencyState string
+
+const (
+	idempotencyInFlight idempotencyState = "in-flight"
+	idempotencyDone     idempotencyState = "done"
+)
+
+// idempotencyRecord is what IdempotencyStore persists per key. While State
+// is idempotencyInFlight, Status/Body are unset -- only BodyHash is known
+// yet, since the handler hasn't run. Once State is idempotencyDone, Status/
+// Body are the exact response to hand back on replay, rather than just a
+// job ID -- that way Idempotency covers any handler it's attached to, not
+// only ones that return a jobId.
+type idempotencyRecord struct {
+	State    idempotencyState `json:"state"`
+	BodyHash string           `json:"bodyHash"`
+	Status   int              `json:"status,omitempty"`
+	Body     []byte           `json:"body,omitempty"`
+}
+
+// IdempotencyStore persists one idempotencyRecord per (owner, key) pair.
+type IdempotencyStore interface {
+	// Reserve atomically claims key for bodyHash if nothing is stored under
+	// it yet (first writer wins), writing an idempotencyInFlight record --
+	// this must happen before the handler runs, not after, so two
+	// concurrent requests carrying the same key can't both pass a Load-miss
+	// check and both run the handler. It reports which record key now
+	// holds: the in-flight one just written if this call won the race, or
+	// whatever a concurrent Reserve/Complete already wrote if it didn't.
+	Reserve(ctx context.Context, key, bodyHash string) (stored idempotencyRecord, won bool, err error)
+	// Complete overwrites key's in-flight record with its final response.
+	// Unlike Reserve it isn't conditional -- the caller already won the
+	// Reserve race for this key.
+	Complete(ctx context.Context, key string, record idempotencyRecord) error
+	// Release clears key's reservation without recording a response, for a
+	// request that reserved the key but then failed before producing a
+	// replayable response -- otherwise that key would stay stuck in-flight
+	// for idempotencyTTL, and a legitimate retry would be rejected rather
+	// than allowed to actually run the handler.
+	Release(ctx context.Context, key string) error
+}
+
+// RedisIdempotencyStore is the production IdempotencyStore: records live in
+// Redis under idempotency:<key> so any API replica can serve the replay,
+// not just the one that handled the original request.
+type RedisIdempotencyStore struct {
+	redis *redis.Client
+}
+
+func NewRedisIdempotencyStore(redisClient *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{redis: redisClient}
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key, bodyHash string) (idempotencyRecord, bool, error) {
+	reservation := idempotencyRecord{State: idempotencyInFlight, BodyHash: bodyHash}
+	raw, err := json.Marshal(reservation)
+	if err != nil {
+		return idempotencyRecord{}, false, err
+	}
+
+	won, err := s.redis.SetNX(ctx, idempotencyRedisKey(key), raw, idempotencyTTL).Result()
+	if err != nil {
+		return idempotencyRecord{}, false, err
+	}
+	if won {
+		return reservation, true, nil
+	}
+
+	stored, ok, err := s.load(ctx, key)
+	if err != nil {
+		return idempotencyRecord{}, false, err
+	}
+	if !ok {
+		// Lost the race to a reservation whose key has since expired or
+		// been deleted; treat it the same as winning outright rather than
+		// erroring the request.
+		return reservation, true, nil
+	}
+	return stored, false, nil
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, record idempotencyRecord) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(ctx, idempotencyRedisKey(key), raw, idempotencyTTL).Err()
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.redis.Del(ctx, idempotencyRedisKey(key)).Err()
+}
+
+func (s *RedisIdempotencyStore) load(ctx context.Context, key string) (idempotencyRecord, bool, error) {
+	raw, err := s.redis.Get(ctx, idempotencyRedisKey(key)).Bytes()
+	if err == redis.Nil {
+		return idempotencyRecord{}, false, nil
+	}
+	if err != nil {
+		return idempotencyRecord{}, false, err
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return idempotencyRecord{}, false, err
+	}
+	return record, true, nil
+}
+
+func idempotencyRedisKey(key string) string {
+	return "idempotency:" + key
+}
+
+// InMemoryIdempotencyStore is a process-local IdempotencyStore for tests
+// and single-instance deployments that don't want a Redis dependency just
+// for this. Records are never swept on a timer -- ttl is only honored on
+// read, mirroring Redis's own lazy expiry close enough for these purposes.
+type InMemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]inMemoryIdempotencyEntry
+}
+
+type inMemoryIdempotencyEntry struct {
+	record    idempotencyRecord
+	expiresAt time.Time
+}
+
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{records: make(map[string]inMemoryIdempotencyEntry)}
+}
+
+func (s *InMemoryIdempotencyStore) Reserve(_ context.Context, key, bodyHash string) (idempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.records[key]; ok && time.Now().Before(entry.expiresAt) {
+		return entry.record, false, nil
+	}
+
+	reservation := idempotencyRecord{State: idempotencyInFlight, BodyHash: bodyHash}
+	s.records[key] = inMemoryIdempotencyEntry{record: reservation, expiresAt: time.Now().Add(idempotencyTTL)}
+	return reservation, true, nil
+}
+
+func (s *InMemoryIdempotencyStore) Complete(_ context.Context, key string, record idempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = inMemoryIdempotencyEntry{record: record, expiresAt: time.Now().Add(idempotencyTTL)}
+	return nil
+}
+
+func (s *InMemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// IdempotencyKeyHeader replays a cached response for any request that
+// repeats an Idempotency-Key header with the same body, rejects reuse of a
+// key against a different body with a typed conflict, and rejects a second
+// request that arrives while the first is still running instead of letting
+// both reach the handler. Requests without the header are untouched --
+// idempotency here is opt-in per client, not forced.
+//
+// This is deliberately a route-level middleware rather than something
+// wired into a specific service: MasterHandler.Final is the first caller
+// (see internal/app/providers.go's "/master" group), but nothing here
+// assumes a jobId-shaped response, so it works the same way in front of
+// any handler that takes a body and does something not safe to repeat.
+func IdempotencyKeyHeader(store IdempotencyStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("Idempotency-Key")
+		if key == "" {
+			return c.Next()
+		}
+
+		scopedKey := GetUserID(c) + ":" + key
+		bodyHash := hashBody(c.Body())
+
+		reserved, won, err := store.Reserve(c.Context(), scopedKey, bodyHash)
+		if err != nil {
+			// Treat a store outage as "no reservation" rather than blocking
+			// the request -- a spurious duplicate is recoverable, a
+			// wrongly blocked request isn't.
+			return c.Next()
+		}
+
+		if !won {
+			switch {
+			case reserved.State == idempotencyInFlight:
+				// Another request with this key is still running. Don't
+				// run the handler a second time alongside it -- the caller
+				// is expected to retry once the first request's response
+				// is available to replay.
+				return response.FromError(c, errs.IdempotencyKeyInFlight(
+					"a request with this Idempotency-Key is already in progress",
+				))
+			case reserved.BodyHash != bodyHash:
+				return response.FromError(c, errs.IdempotencyKeyConflict(
+					"Idempotency-Key was already used with a different request body",
+				))
+			default:
+				c.Status(reserved.Status)
+				c.Response().Header.SetContentType(fiber.MIMEApplicationJSON)
+				return c.Send(reserved.Body)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			_ = store.Release(c.Context(), scopedKey)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= 200 && status < 300 {
+			// Best-effort: a Complete failure here just means a retried
+			// request won't replay and instead runs the handler again,
+			// which is the same behavior as not having this middleware.
+			_ = store.Complete(c.Context(), scopedKey, idempotencyRecord{
+				State:    idempotencyDone,
+				BodyHash: bodyHash,
+				Status:   status,
+				Body:     append([]byte(nil), c.Response().Body()...),
+			})
+		} else {
+			// The handler failed: release the reservation rather than
+			// leaving it in-flight for idempotencyTTL, which would reject
+			// every retry with IdempotencyKeyInFlight instead of letting
+			// one actually run.
+			_ = store.Release(c.Context(), scopedKey)
+		}
+		return nil
+	}
+}
+
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
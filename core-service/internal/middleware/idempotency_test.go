@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestInMemoryIdempotencyStore_ReserveIsFirstWriterWins asserts that when
+// many goroutines race to Reserve the same key, exactly one wins -- the
+// property IdempotencyKeyHeader relies on to guarantee only one of two
+// concurrent requests carrying the same key ever reaches the handler.
+func TestInMemoryIdempotencyStore_ReserveIsFirstWriterWins(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+
+	const n = 50
+	var wg sync.WaitGroup
+	var wins int64
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			_, won, err := store.Reserve(context.Background(), "key", "hash")
+			if err != nil {
+				t.Errorf("reserve failed: %v", err)
+				return
+			}
+			if won {
+				atomic.AddInt64(&wins, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if wins != 1 {
+		t.Errorf("expected exactly 1 winner out of %d concurrent Reserve calls, got %d", n, wins)
+	}
+}
+
+// TestInMemoryIdempotencyStore_LoserSeesInFlight asserts a losing Reserve
+// sees the in-flight reservation, not a completed record -- this is what
+// lets IdempotencyKeyHeader tell "still running" apart from "already
+// replayable" without a second round trip.
+func TestInMemoryIdempotencyStore_LoserSeesInFlight(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	ctx := context.Background()
+
+	if _, won, err := store.Reserve(ctx, "key", "hash"); err != nil || !won {
+		t.Fatalf("expected first reserve to win, got won=%v err=%v", won, err)
+	}
+
+	stored, won, err := store.Reserve(ctx, "key", "hash")
+	if err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	if won {
+		t.Fatal("expected second reserve to lose while the first is still in-flight")
+	}
+	if stored.State != idempotencyInFlight {
+		t.Errorf("expected the loser to see state %q, got %q", idempotencyInFlight, stored.State)
+	}
+}
+
+// TestInMemoryIdempotencyStore_CompleteThenReplay asserts that once the
+// reservation holder calls Complete, a subsequent Reserve for the same key
+// sees the finished record instead of the in-flight placeholder.
+func TestInMemoryIdempotencyStore_CompleteThenReplay(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	ctx := context.Background()
+
+	if _, won, err := store.Reserve(ctx, "key", "hash"); err != nil || !won {
+		t.Fatalf("expected reserve to win, got won=%v err=%v", won, err)
+	}
+	if err := store.Complete(ctx, "key", idempotencyRecord{State: idempotencyDone, BodyHash: "hash", Status: 202, Body: []byte("{}")}); err != nil {
+		t.Fatalf("complete failed: %v", err)
+	}
+
+	stored, won, err := store.Reserve(ctx, "key", "hash")
+	if err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	if won {
+		t.Fatal("expected reserve to lose against a completed record")
+	}
+	if stored.State != idempotencyDone || stored.Status != 202 {
+		t.Errorf("expected the completed record back, got %+v", stored)
+	}
+}
+
+// TestInMemoryIdempotencyStore_ReleaseUnblocksRetry asserts Release clears
+// a reservation so a later Reserve for the same key can win again -- the
+// path IdempotencyKeyHeader takes when the handler itself fails.
+func TestInMemoryIdempotencyStore_ReleaseUnblocksRetry(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	ctx := context.Background()
+
+	if _, won, err := store.Reserve(ctx, "key", "hash"); err != nil || !won {
+		t.Fatalf("expected reserve to win, got won=%v err=%v", won, err)
+	}
+	if err := store.Release(ctx, "key"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+
+	if _, won, err := store.Reserve(ctx, "key", "hash"); err != nil || !won {
+		t.Errorf("expected reserve to win again after release, got won=%v err=%v", won, err)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// httpRequestsTotal/httpRequestDuration are labeled by c.Route().Path rather
+// than c.Path(): the route pattern ("/api/jobs/:jobId") is bounded
+// cardinality, while the raw path carries every distinct jobId/projectId a
+// caller ever sends. httpRequestsInFlight has no labels since it's one
+// process-wide gauge of concurrent requests, not a per-route breakdown.
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests handled, by route, method, and status.",
+	}, []string{"route", "method", "status"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	httpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being handled.",
+	})
+)
+
+// Metrics records per-route request counts, latency, and in-flight
+// concurrency for every request that reaches it. Register it ahead of
+// route groups (after RequestContext) so it also covers rejected/erroring
+// requests, not just successful ones.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		start := time.Now()
+		err := c.Next()
+		elapsed := time.Since(start).Seconds()
+
+		route := c.Route().Path
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Method()
+
+		httpRequestDuration.WithLabelValues(route, method).Observe(elapsed)
+		httpRequestsTotal.WithLabelValues(route, method, strconv.Itoa(c.Response().StatusCode())).Inc()
+
+		return err
+	}
+}
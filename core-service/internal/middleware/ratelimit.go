@@ -6,77 +6,182 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/config"
 	"github.com/makeasinger/api/pkg/response"
 )
 
+var (
+	ratelimitAllowedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ratelimit_allowed_total",
+		Help: "Total requests admitted by RateLimiter.Limit, by key prefix.",
+	}, []string{"key_prefix"})
+	ratelimitBlockedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ratelimit_blocked_total",
+		Help: "Total requests rejected by RateLimiter.Limit, by key prefix.",
+	}, []string{"key_prefix"})
+)
+
+// PlanScoper is implemented by both auth.Claims (JWKS/Federated) and
+// auth.LegacyClaims (HMAC fallback) so RateLimiter can resolve bucket sizes
+// per caller instead of applying one global limit.
+type PlanScoper interface {
+	GetPlan() string
+}
+
+// slidingWindowScript implements a sliding-log rate limit atomically: it
+// drops entries older than the window, counts what's left, and (if under
+// limit) admits the new request, all in one round trip so no other request
+// can interleave between the count and the admit. Unlike INCR+EXPIRE, there
+// is no separate "set the expiry" step to lose if the process dies
+// mid-request, and there is no fixed window boundary to burst across --
+// the window always slides relative to now.
+//
+// KEYS[1] = rate limit key
+// ARGV[1] = now, in unix milliseconds
+// ARGV[2] = window size, in milliseconds
+// ARGV[3] = max requests allowed per window
+// ARGV[4] = unique member for this request (so same-millisecond requests
+//           don't collide and get deduplicated by ZADD)
+//
+// Returns {allowed (0/1), remaining, resetMs}, where resetMs is how long
+// until the oldest request in the window ages out (0 if not rate limited).
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+
+if count < limit then
+    redis.call('ZADD', key, now, member)
+    redis.call('PEXPIRE', key, window)
+    return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local resetMs = window
+if oldest[2] then
+    resetMs = tonumber(oldest[2]) + window - now
+end
+return {0, 0, resetMs}
+`)
+
 type RateLimiter struct {
 	redis *redis.Client
+	cfg   *config.Live
 }
 
-func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
-	return &RateLimiter{redis: redisClient}
+func NewRateLimiter(redisClient *redis.Client, cfg *config.Live) *RateLimiter {
+	return &RateLimiter{redis: redisClient, cfg: cfg}
 }
 
-// Limit creates a rate limiting middleware
-func (rl *RateLimiter) Limit(keyPrefix string, maxRequests int, window time.Duration) fiber.Handler {
+// limitFor extracts the field of a plan's RateLimitConfig a given endpoint
+// group cares about (e.g. LyricsPerMin for the lyrics group).
+type limitFor func(config.RateLimitConfig) int
+
+// Limit creates rate limiting middleware whose bucket size is resolved on
+// every request from the caller's plan claim against the live config, so a
+// config file edit changes bucket sizes without a restart and different
+// plans get different limits. The limit itself is enforced by
+// slidingWindowScript rather than INCR+EXPIRE, so a crash between the count
+// and the expire can't leave a counter stuck forever, and a caller can't
+// burst 2x the limit by timing requests around a fixed window boundary.
+func (rl *RateLimiter) Limit(keyPrefix string, limit limitFor, window time.Duration) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userID := GetUserID(c)
 		if userID == "" {
 			return c.Next() // Skip rate limiting if no user (auth middleware should catch this)
 		}
 
+		maxRequests := limit(rl.planLimits(c))
+
 		key := fmt.Sprintf("ratelimit:%s:%s", keyPrefix, userID)
 		ctx := context.Background()
+		now := time.Now().UnixMilli()
 
-		// Increment counter
-		count, err := rl.redis.Incr(ctx, key).Result()
+		res, err := slidingWindowScript.Run(ctx, rl.redis, []string{key},
+			now, window.Milliseconds(), maxRequests, uuid.New().String()).Result()
 		if err != nil {
-			// If Redis fails, allow the request but log the error
+			// If Redis fails, allow the request but don't block on it.
 			return c.Next()
 		}
 
-		// Set expiration on first request
-		if count == 1 {
-			rl.redis.Expire(ctx, key, window)
+		vals, ok := res.([]interface{})
+		if !ok || len(vals) != 3 {
+			return c.Next()
 		}
-
-		if count > int64(maxRequests) {
-			// Get TTL for retry-after header
-			ttl, _ := rl.redis.TTL(ctx, key).Result()
-			c.Set("Retry-After", fmt.Sprintf("%d", int(ttl.Seconds())))
+		allowed, _ := vals[0].(int64)
+		remaining, _ := vals[1].(int64)
+		resetMs, _ := vals[2].(int64)
+
+		if allowed == 0 {
+			ratelimitBlockedTotal.WithLabelValues(keyPrefix).Inc()
+			c.Set("Retry-After", fmt.Sprintf("%d", (resetMs+999)/1000))
+			c.Set("X-RateLimit-Reset", fmt.Sprintf("%d", resetMs/1000))
 			return response.RateLimited(c)
 		}
 
-		// Add rate limit headers
+		ratelimitAllowedTotal.WithLabelValues(keyPrefix).Inc()
 		c.Set("X-RateLimit-Limit", fmt.Sprintf("%d", maxRequests))
-		c.Set("X-RateLimit-Remaining", fmt.Sprintf("%d", maxRequests-int(count)))
+		c.Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
 
 		return c.Next()
 	}
 }
 
-// LyricsLimit returns a rate limiter for lyrics endpoints (30 req/min)
-func (rl *RateLimiter) LyricsLimit(maxPerMin int) fiber.Handler {
-	return rl.Limit("lyrics", maxPerMin, time.Minute)
+// planLimits resolves the RateLimitConfig for the request's plan claim,
+// falling back to "free" if the claim is missing/unrecognized and to a
+// hardcoded default if even "free" isn't in the live config (e.g. a
+// malformed reload), so a bad config file degrades safely instead of
+// blocking every request with a zero-value limit.
+func (rl *RateLimiter) planLimits(c *fiber.Ctx) config.RateLimitConfig {
+	plan := "free"
+	if scoper, ok := c.Locals("claims").(PlanScoper); ok {
+		if p := scoper.GetPlan(); p != "" {
+			plan = p
+		}
+	}
+
+	limits := rl.cfg.Current().RateLimit
+	if rc, ok := limits[plan]; ok {
+		return rc
+	}
+	if rc, ok := limits["free"]; ok {
+		return rc
+	}
+	return config.RateLimitConfig{LyricsPerMin: 30, RenderPerHour: 5, MasterPerHour: 10, ExportPerHour: 20, UploadPerHour: 50}
+}
+
+// LyricsLimit returns a rate limiter for lyrics endpoints, bucketed per
+// minute.
+func (rl *RateLimiter) LyricsLimit() fiber.Handler {
+	return rl.Limit("lyrics", func(rc config.RateLimitConfig) int { return rc.LyricsPerMin }, time.Minute)
 }
 
-// RenderLimit returns a rate limiter for render endpoints (5 req/hour)
-func (rl *RateLimiter) RenderLimit(maxPerHour int) fiber.Handler {
-	return rl.Limit("render", maxPerHour, time.Hour)
+// RenderLimit returns a rate limiter for render endpoints, bucketed hourly.
+func (rl *RateLimiter) RenderLimit() fiber.Handler {
+	return rl.Limit("render", func(rc config.RateLimitConfig) int { return rc.RenderPerHour }, time.Hour)
 }
 
-// MasterLimit returns a rate limiter for master endpoints (10 req/hour)
-func (rl *RateLimiter) MasterLimit(maxPerHour int) fiber.Handler {
-	return rl.Limit("master", maxPerHour, time.Hour)
+// MasterLimit returns a rate limiter for master endpoints, bucketed hourly.
+func (rl *RateLimiter) MasterLimit() fiber.Handler {
+	return rl.Limit("master", func(rc config.RateLimitConfig) int { return rc.MasterPerHour }, time.Hour)
 }
 
-// ExportLimit returns a rate limiter for export endpoints (20 req/hour)
-func (rl *RateLimiter) ExportLimit(maxPerHour int) fiber.Handler {
-	return rl.Limit("export", maxPerHour, time.Hour)
+// ExportLimit returns a rate limiter for export endpoints, bucketed hourly.
+func (rl *RateLimiter) ExportLimit() fiber.Handler {
+	return rl.Limit("export", func(rc config.RateLimitConfig) int { return rc.ExportPerHour }, time.Hour)
 }
 
-// UploadLimit returns a rate limiter for upload endpoints (50 req/hour)
-func (rl *RateLimiter) UploadLimit(maxPerHour int) fiber.Handler {
-	return rl.Limit("upload", maxPerHour, time.Hour)
+// UploadLimit returns a rate limiter for upload endpoints, bucketed hourly.
+func (rl *RateLimiter) UploadLimit() fiber.Handler {
+	return rl.Limit("upload", func(rc config.RateLimitConfig) int { return rc.UploadPerHour }, time.Hour)
 }
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+// runWindow evaluates slidingWindowScript once and returns (allowed, remaining).
+func runWindow(t *testing.T, rdb *redis.Client, key string, now int64, window time.Duration, limit int) (bool, int64) {
+	t.Helper()
+	res, err := slidingWindowScript.Run(context.Background(), rdb, []string{key},
+		now, window.Milliseconds(), limit, uuid.New().String()).Result()
+	if err != nil {
+		t.Fatalf("script run failed: %v", err)
+	}
+	vals := res.([]interface{})
+	allowed := vals[0].(int64) == 1
+	remaining := vals[1].(int64)
+	return allowed, remaining
+}
+
+// TestSlidingWindow_NoLostExpire asserts that the counter is still correctly
+// bounded after the key's TTL would have been missed by a crash between
+// INCR and EXPIRE in the old implementation -- here there's no separate
+// expire step to miss, since PEXPIRE runs in the same script invocation as
+// the count.
+func TestSlidingWindow_NoLostExpire(t *testing.T) {
+	rdb := newTestRedis(t)
+	key := "ratelimit:test:user1"
+	window := time.Minute
+	limit := 3
+	now := time.Now().UnixMilli()
+
+	for i := 0; i < limit; i++ {
+		allowed, _ := runWindow(t, rdb, key, now, window, limit)
+		if !allowed {
+			t.Fatalf("request %d should have been allowed", i)
+		}
+	}
+
+	allowed, remaining := runWindow(t, rdb, key, now, window, limit)
+	if allowed {
+		t.Fatalf("request over the limit should have been rejected")
+	}
+	if remaining != 0 {
+		t.Fatalf("expected 0 remaining, got %d", remaining)
+	}
+}
+
+// TestSlidingWindow_NoBoundaryBurst asserts that a caller can't get 2x the
+// limit by timing requests around a fixed window boundary: with a sliding
+// window, exhausting the limit just before the boundary still blocks
+// requests made just after it, since the window is relative to now rather
+// than to a fixed wall-clock bucket.
+func TestSlidingWindow_NoBoundaryBurst(t *testing.T) {
+	rdb := newTestRedis(t)
+	key := "ratelimit:test:user2"
+	window := time.Minute
+	limit := 5
+	windowStart := time.Now().UnixMilli()
+
+	// Exhaust the limit right at the end of the window.
+	justBeforeBoundary := windowStart + window.Milliseconds() - 1
+	for i := 0; i < limit; i++ {
+		allowed, _ := runWindow(t, rdb, key, justBeforeBoundary, window, limit)
+		if !allowed {
+			t.Fatalf("request %d should have been allowed", i)
+		}
+	}
+
+	// A fixed-window counter would reset here; the sliding window shouldn't,
+	// since all 5 prior requests are still within `window` of this instant.
+	justAfterBoundary := justBeforeBoundary + 1
+	allowed, _ := runWindow(t, rdb, key, justAfterBoundary, window, limit)
+	if allowed {
+		t.Fatalf("request just after the naive window boundary should still be rate limited")
+	}
+}
+
+// TestSlidingWindow_AdmitsAfterWindowSlides asserts requests are admitted
+// again once enough real time has passed for the oldest entries to age out.
+func TestSlidingWindow_AdmitsAfterWindowSlides(t *testing.T) {
+	rdb := newTestRedis(t)
+	key := "ratelimit:test:user3"
+	window := time.Minute
+	limit := 2
+	now := time.Now().UnixMilli()
+
+	runWindow(t, rdb, key, now, window, limit)
+	runWindow(t, rdb, key, now, window, limit)
+	if allowed, _ := runWindow(t, rdb, key, now, window, limit); allowed {
+		t.Fatalf("third request within the window should have been rejected")
+	}
+
+	later := now + window.Milliseconds() + 1
+	if allowed, _ := runWindow(t, rdb, key, later, window, limit); !allowed {
+		t.Fatalf("request after the window fully slides past should be allowed")
+	}
+}
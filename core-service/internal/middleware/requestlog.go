@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// RequestIDHeader is echoed back on every response so clients can correlate
+// their request with the structured logs it produced.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestContext attaches a per-request correlation ID (reusing one the
+// caller already supplies via X-Request-Id, if present) to c.UserContext(),
+// so every log.Info/log.Error call made against it — directly, or via a
+// context handlers derive from it with log.WithFields for jobId/projectId —
+// carries the same requestId. It's also stashed in c.Locals under
+// response.TraceIDKey so pkg/response can echo it back as traceId on
+// problem+json error responses, letting support correlate a failed request
+// with the render/master job queue logs it produced. Register this ahead of
+// auth middleware so the ID is stamped even on 401s; AuthMiddleware.Authenticate
+// attaches userId to the same context once a token validates.
+func RequestContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Set(RequestIDHeader, requestID)
+
+		c.SetUserContext(log.WithFields(c.UserContext(), "requestId", requestID))
+		c.Locals(response.TraceIDKey, requestID)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/runnerapi"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// RunnerAuthMiddleware checks the shared secret the job runner presents on
+// every internal RPC call (see internal/runnerapi). These routes sit
+// outside the gateway-auth group, so this is the only thing standing
+// between them and anyone who can reach the API.
+func RunnerAuthMiddleware(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		provided := c.Get(runnerapi.SharedSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+			return response.Unauthorized(c, "Invalid runner secret")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/pkg/response"
+)
+
+// VerifySignedFileURL guards the /files mount used by the filesystem
+// storage backend (config.StorageConfig.Provider == "fs"): it recomputes
+// client.SignFileURL over the request's method and path against exp/sig
+// query params and rejects anything that doesn't match or has expired.
+// Without an S3-compatible provider's own request signing, this is what
+// stands in for it.
+func VerifySignedFileURL(signingSecret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimPrefix(c.Path(), "/files/")
+
+		expStr := c.Query("exp")
+		exp, err := strconv.ParseInt(expStr, 10, 64)
+		if err != nil {
+			return response.Unauthorized(c, "Missing or invalid exp")
+		}
+		if time.Now().Unix() > exp {
+			return response.Unauthorized(c, "Signed URL expired")
+		}
+
+		want := client.SignFileURL(signingSecret, c.Method(), key, exp)
+		got := c.Query("sig")
+		if !hmac.Equal([]byte(want), []byte(got)) {
+			return response.Unauthorized(c, "Invalid signature")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,12 @@
+package middleware
+
+import "github.com/google/wire"
+
+// ProviderSet wires the middleware that's always present regardless of
+// deployment mode. The request-auth middleware isn't included here: which
+// constructor it needs (JWKS, legacy HMAC, gateway header, or a fallback
+// combination) depends on runtime config, so internal/app builds it by hand
+// the same way it always has.
+var ProviderSet = wire.NewSet(
+	NewRateLimiter,
+)
@@ -0,0 +1,16 @@
+package model
+
+// RevokeRequest represents the request body for POST /api/auth/revoke.
+// Callers may revoke by JTI directly, or pass the token itself and let the
+// handler extract the JTI and expiry from its claims.
+type RevokeRequest struct {
+	Token string `json:"token,omitempty"`
+	JTI   string `json:"jti,omitempty"`
+	Exp   int64  `json:"exp,omitempty"` // unix seconds; required when JTI is set
+}
+
+// RefreshRequest represents the request body for POST /api/auth/refresh.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	ClientID     string `json:"client_id" validate:"required"`
+}
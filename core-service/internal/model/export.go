@@ -2,30 +2,47 @@ package model
 
 import "time"
 
-// ExportMP3Request represents the request for MP3 export
+// ExportMP3Request represents the request for MP3 export. LyricsLRC/
+// LyricsURL supply time-synced lyrics (literal LRC text, or a URL to fetch
+// it from) embedded as ID3v2 USLT+SYLT frames -- independent of
+// Metadata.Lyrics, which is always plain (unsynced) text. When neither is
+// set but EmbedLyrics and Metadata.Lyrics are, MasterDurationSeconds lets
+// the service derive a best-effort synced LRC from the plain lyrics (see
+// service.LyricsTimedService) by spreading its lines evenly over that
+// duration; the caller supplies it because, like LyricsExportRequest.Lines,
+// it isn't queryable server-side from ProjectID alone.
 type ExportMP3Request struct {
-	ProjectID     string         `json:"projectId" validate:"required,uuid"`
-	MasterFileURL string         `json:"masterFileUrl" validate:"required,url"`
-	Quality       *int           `json:"quality" validate:"omitempty,oneof=128 192 256 320"`
-	Metadata      *ExportMetadata `json:"metadata" validate:"omitempty"`
+	ProjectID             string          `json:"projectId" validate:"required,uuid"`
+	MasterFileURL         string          `json:"masterFileUrl" validate:"required,url"`
+	Quality               *int            `json:"quality" validate:"omitempty,oneof=128 192 256 320"`
+	EmbedLyrics           bool            `json:"embedLyrics"`
+	EmbedCover            *bool           `json:"embedCover" validate:"omitempty"`
+	Metadata              *ExportMetadata `json:"metadata" validate:"omitempty"`
+	LyricsLRC             string          `json:"lyricsLrc" validate:"omitempty"`
+	LyricsURL             string          `json:"lyricsUrl" validate:"omitempty,url"`
+	MasterDurationSeconds *float64        `json:"masterDurationSeconds" validate:"omitempty,gt=0"`
 }
 
-// ExportMetadata contains ID3 tag metadata
+// ExportMetadata contains ID3 tag metadata. Lyrics, when set alongside
+// EmbedLyrics on the request, is written as a plain (non-timed) ID3 USLT
+// frame (or MP4 ©lyr atom for ALAC) -- per-line/per-word sync (SYLT) is not
+// embedded this way; use POST /api/lyrics/export for a standalone timed
+// lyrics file instead. CoverURL/CoverSize/CoverFormat describe the still
+// artwork embedded as APIC (MP3), covr (MP4/ALAC), or METADATA_BLOCK_PICTURE
+// (FLAC) when EmbedCover isn't explicitly false. AnimatedArtworkURL is only
+// honored for ALAC, whose MP4 container can carry it as a secondary video
+// track; other formats ignore it.
 type ExportMetadata struct {
-	Title   string `json:"title" validate:"omitempty,max=200"`
-	Artist  string `json:"artist" validate:"omitempty,max=200"`
-	Album   string `json:"album" validate:"omitempty,max=200"`
-	Year    *int   `json:"year" validate:"omitempty,min=1900,max=2100"`
-	Credits string `json:"credits" validate:"omitempty,max=1000"`
-}
-
-// ExportMP3Response represents the response for MP3 export
-type ExportMP3Response struct {
-	FileURL   string    `json:"fileUrl"`
-	Size      int64     `json:"size"`
-	Format    string    `json:"format"`
-	Quality   int       `json:"quality"`
-	ExpiresAt time.Time `json:"expiresAt"`
+	Title              string `json:"title" validate:"omitempty,max=200"`
+	Artist             string `json:"artist" validate:"omitempty,max=200"`
+	Album              string `json:"album" validate:"omitempty,max=200"`
+	Year               *int   `json:"year" validate:"omitempty,min=1900,max=2100"`
+	Credits            string `json:"credits" validate:"omitempty,max=1000"`
+	Lyrics             string `json:"lyrics" validate:"omitempty,max=10000"`
+	CoverURL           string `json:"coverUrl" validate:"omitempty,url"`
+	CoverSize          string `json:"coverSize" validate:"omitempty,oneof=512 1024 2048 original"`
+	CoverFormat        string `json:"coverFormat" validate:"omitempty,oneof=jpg png webp"`
+	AnimatedArtworkURL string `json:"animatedArtworkUrl" validate:"omitempty,url"`
 }
 
 // ExportWAVRequest represents the request for WAV export
@@ -36,31 +53,106 @@ type ExportWAVRequest struct {
 	SampleRate    *int   `json:"sampleRate" validate:"omitempty,oneof=44100 48000 96000"`
 }
 
-// ExportWAVResponse represents the response for WAV export
-type ExportWAVResponse struct {
-	FileURL    string    `json:"fileUrl"`
-	Size       int64     `json:"size"`
-	Format     string    `json:"format"`
-	BitDepth   int       `json:"bitDepth"`
-	SampleRate int       `json:"sampleRate"`
-	ExpiresAt  time.Time `json:"expiresAt"`
-}
-
-// ExportStemsRequest represents the request for stems export
+// ExportStemsRequest represents the request for stems export. LyricsLRC/
+// LyricsURL optionally bundle a lyrics.lrc file (literal LRC text, or a URL
+// to fetch it from) alongside the stem/vocal/master files in the archive.
 type ExportStemsRequest struct {
 	ProjectID     string   `json:"projectId" validate:"required,uuid"`
 	StemURLs      []string `json:"stemUrls" validate:"required,min=1,dive,url"`
-	Format        string   `json:"format" validate:"omitempty,oneof=wav mp3"`
+	Format        string   `json:"format" validate:"omitempty,oneof=wav mp3 alac flac atmos"`
 	IncludeVocals bool     `json:"includeVocals"`
 	VocalURLs     []string `json:"vocalUrls" validate:"omitempty,dive,url"`
 	IncludeMaster bool     `json:"includeMaster"`
 	MasterURL     string   `json:"masterUrl" validate:"omitempty,url"`
+	LyricsLRC     string   `json:"lyricsLrc" validate:"omitempty"`
+	LyricsURL     string   `json:"lyricsUrl" validate:"omitempty,url"`
+}
+
+// ExportALACRequest represents the request for Apple Lossless (ALAC) export.
+type ExportALACRequest struct {
+	ProjectID     string          `json:"projectId" validate:"required,uuid"`
+	MasterFileURL string          `json:"masterFileUrl" validate:"required,url"`
+	BitDepth      *int            `json:"bitDepth" validate:"omitempty,oneof=16 24 32"`
+	SampleRate    *int            `json:"sampleRate" validate:"omitempty,oneof=44100 48000 96000"`
+	EmbedLyrics   bool            `json:"embedLyrics"`
+	EmbedCover    *bool           `json:"embedCover" validate:"omitempty"`
+	Metadata      *ExportMetadata `json:"metadata" validate:"omitempty"`
+}
+
+// ExportFLACRequest represents the request for FLAC export. CompressionLevel
+// follows libFLAC's own 0 (fastest) to 8 (smallest) scale.
+type ExportFLACRequest struct {
+	ProjectID        string          `json:"projectId" validate:"required,uuid"`
+	MasterFileURL    string          `json:"masterFileUrl" validate:"required,url"`
+	BitDepth         *int            `json:"bitDepth" validate:"omitempty,oneof=16 24"`
+	SampleRate       *int            `json:"sampleRate" validate:"omitempty,oneof=44100 48000 88200 96000 192000"`
+	CompressionLevel *int            `json:"compressionLevel" validate:"omitempty,min=0,max=8"`
+	EmbedCover       *bool           `json:"embedCover" validate:"omitempty"`
+	Metadata         *ExportMetadata `json:"metadata" validate:"omitempty"`
+}
+
+// ExportOpusRequest represents the request for an Opus export, suited to
+// podcasts, voice assistants, and other bandwidth-limited playback.
+// Application selects libopus's internal tuning (voip/audio/lowdelay); VBR
+// defaults to true (variable bitrate) and can be set false for a constant
+// target bitrate instead.
+type ExportOpusRequest struct {
+	ProjectID     string `json:"projectId" validate:"required,uuid"`
+	MasterFileURL string `json:"masterFileUrl" validate:"required,url"`
+	Bitrate       *int   `json:"bitrate" validate:"omitempty,min=6,max=510"`
+	VBR           *bool  `json:"vbr" validate:"omitempty"`
+	Application   string `json:"application" validate:"omitempty,oneof=voip audio lowdelay"`
+	ChannelLayout string `json:"channelLayout" validate:"omitempty,oneof=mono stereo"`
+}
+
+// ExportOggRequest represents the request for an Ogg Vorbis export. Quality
+// is libvorbis's -q:a scale (-1 fastest/smallest to 10 best) and is used
+// when VBR is true (the default); Bitrate is used as a constant-bitrate
+// fallback when VBR is false.
+type ExportOggRequest struct {
+	ProjectID     string `json:"projectId" validate:"required,uuid"`
+	MasterFileURL string `json:"masterFileUrl" validate:"required,url"`
+	Quality       *int   `json:"quality" validate:"omitempty,min=-1,max=10"`
+	Bitrate       *int   `json:"bitrate" validate:"omitempty,min=45,max=500"`
+	VBR           *bool  `json:"vbr" validate:"omitempty"`
+	ChannelLayout string `json:"channelLayout" validate:"omitempty,oneof=mono stereo"`
+}
+
+// ExportAtmosRequest represents the request for a Dolby Atmos spatial-audio
+// export: a bed mix plus independently positioned objects, each carrying its
+// own pan/gain automation. Binaural requests an EC-3 JOC binaural render
+// (headphone-downmixed) output alongside the multichannel bed.
+type ExportAtmosRequest struct {
+	ProjectID            string   `json:"projectId" validate:"required,uuid"`
+	MasterFileURL        string   `json:"masterFileUrl" validate:"required,url"`
+	BedLayout            string   `json:"bedLayout" validate:"required,oneof=5.1.4 7.1.4"`
+	ObjectCount          int      `json:"objectCount" validate:"omitempty,min=0,max=118"`
+	Binaural             bool     `json:"binaural"`
+	ObjectAutomationURLs []string `json:"objectAutomationUrls" validate:"omitempty,dive,url"`
+}
+
+// ExportJobResponse is returned when an export is queued, mirroring
+// RenderStartResponse's job-polling shape.
+type ExportJobResponse struct {
+	JobID     string    `json:"jobId"`
+	Status    JobStatus `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
-// ExportStemsResponse represents the response for stems export
-type ExportStemsResponse struct {
-	FileURL   string    `json:"fileUrl"`
-	Size      int64     `json:"size"`
-	FileCount int       `json:"fileCount"`
-	ExpiresAt time.Time `json:"expiresAt"`
+// ExportResultResponse is the polled result of a completed export job. It
+// covers every export kind; fields that don't apply to the kind that
+// produced a given job are left zero-valued and omitted.
+type ExportResultResponse struct {
+	FileURL                 string    `json:"fileUrl"`
+	StreamURL               string    `json:"streamUrl,omitempty"` // stems only: a Range-capable URL available before FileURL's R2 backfill finishes
+	Size                    int64     `json:"size"`
+	Format                  string    `json:"format,omitempty"`
+	Quality                 int       `json:"quality,omitempty"`
+	BitDepth                int       `json:"bitDepth,omitempty"`
+	SampleRate              int       `json:"sampleRate,omitempty"`
+	CompressionLevel        int       `json:"compressionLevel,omitempty"`
+	FileCount               int       `json:"fileCount,omitempty"`
+	CoverEmbedded           bool      `json:"coverEmbedded,omitempty"`
+	AnimatedArtworkEmbedded bool      `json:"animatedArtworkEmbedded,omitempty"`
+	ExpiresAt               time.Time `json:"expiresAt"`
 }
@@ -4,24 +4,58 @@ import "time"
 
 // Job represents a background job in the system
 type Job struct {
-	ID          string     `json:"id"`
-	Type        string     `json:"type"` // "render" or "master"
-	Status      JobStatus  `json:"status"`
-	Progress    int        `json:"progress"`
-	CurrentStep string     `json:"currentStep,omitempty"`
-	Error       *string    `json:"error,omitempty"`
-	Payload     []byte     `json:"-"` // Stored as JSON
-	Result      []byte     `json:"-"` // Stored as JSON
-	CreatedAt   time.Time  `json:"createdAt"`
-	StartedAt   *time.Time `json:"startedAt,omitempty"`
-	CompletedAt *time.Time `json:"completedAt,omitempty"`
-	RetryCount  int        `json:"retryCount"`
+	ID              string              `json:"id"`
+	Type            string              `json:"type"` // "render", "master" or "export"
+	Status          JobStatus           `json:"status"`
+	Progress        int                 `json:"progress"`
+	CurrentStep     string              `json:"currentStep,omitempty"`
+	Error           *string             `json:"error,omitempty"`
+	StructuredError *JobStructuredError `json:"structuredError,omitempty"`
+	ArchiveStatus   ArchiveStatus       `json:"archiveStatus,omitempty"`
+	Payload         []byte              `json:"-"` // Stored as JSON
+	Result          []byte              `json:"-"` // Stored as JSON
+	CreatedAt       time.Time           `json:"createdAt"`
+	StartedAt       *time.Time          `json:"startedAt,omitempty"`
+	CompletedAt     *time.Time          `json:"completedAt,omitempty"`
+	RetryCount      int                 `json:"retryCount"`
+	// LastHeartbeatAt is stamped by RenderService.UpdateJobProgress on every
+	// progress report from the runner holding this job's Asynq lease. It's
+	// how a status read detects a runner that died mid-job without calling
+	// Fail: see RenderService.GetStatus's lease-expiry check.
+	LastHeartbeatAt *time.Time `json:"lastHeartbeatAt,omitempty"`
+}
+
+// ArchiveStatus tracks the lifecycle of a job's move to cold storage, which
+// happens asynchronously after the job itself has succeeded.
+type ArchiveStatus string
+
+const (
+	ArchiveStatusPending   ArchiveStatus = "pending"
+	ArchiveStatusSucceeded ArchiveStatus = "succeeded"
+	ArchiveStatusFailed    ArchiveStatus = "failed"
+)
+
+// JobStructuredError carries per-item failure detail for batch jobs (e.g. a
+// multi-stem master where some stems succeed and others fail) instead of
+// collapsing everything into a single message.
+type JobStructuredError struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Items   []ItemFailure `json:"items,omitempty"`
+}
+
+// ItemFailure describes why a single item in a batch job failed.
+type ItemFailure struct {
+	Index  int    `json:"index"`
+	Ref    string `json:"ref"`
+	Reason string `json:"reason"`
 }
 
 // Job types
 const (
 	JobTypeRender = "render"
 	JobTypeMaster = "master"
+	JobTypeExport = "export"
 )
 
 // RenderJobPayload contains the data for a render job
@@ -38,4 +72,78 @@ type MasterJobPayload struct {
 	StemURLs    []string      `json:"stemUrls"`
 	MixSnapshot MixSnapshot   `json:"mixSnapshot"`
 	VocalTakes  []VocalTake   `json:"vocalTakes,omitempty"`
+
+	// CallbackURL/CallbackSecret are copied across from MasterFinalRequest
+	// when the job is queued. RenderService.fireWebhook reads them back out
+	// of Job.Payload at completion/failure time -- they never appear in any
+	// API response because Payload itself is json:"-" on Job.
+	CallbackURL    string `json:"callbackUrl,omitempty"`
+	CallbackSecret string `json:"callbackSecret,omitempty"`
+}
+
+// WebhookDeliveryPayload is the Asynq task payload for TaskTypeWebhookDelivery:
+// everything WebhookWorker.ProcessTask needs to sign and POST one callback,
+// without re-reading the job record (which may have moved on by the time a
+// retry runs).
+type WebhookDeliveryPayload struct {
+	JobID  string    `json:"jobId"`
+	Event  string    `json:"event"` // "done" or "failed"
+	Status JobStatus `json:"status"`
+	URL    string    `json:"url"`
+	Secret string    `json:"secret"`
+	Body   []byte    `json:"body"`
+}
+
+// ExportKind identifies which encode/archive operation an export job runs.
+type ExportKind string
+
+const (
+	ExportKindMP3   ExportKind = "mp3"
+	ExportKindWAV   ExportKind = "wav"
+	ExportKindStems ExportKind = "stems"
+	ExportKindALAC  ExportKind = "alac"
+	ExportKindFLAC  ExportKind = "flac"
+	ExportKindAtmos ExportKind = "atmos"
+	ExportKindOpus  ExportKind = "opus"
+	ExportKindOgg   ExportKind = "ogg"
+)
+
+// ExportJobPayload contains the data for an export job. SourceURLs holds the
+// master/stem/vocal URLs to download; for ExportKindStems, Filenames holds
+// the matching in-archive path for each entry in SourceURLs. The
+// CompressionLevel/BedLayout/ObjectCount/Binaural/ObjectAutomationURLs
+// fields only apply to ExportKindFLAC and ExportKindAtmos respectively.
+// EmbedLyrics/Metadata.Lyrics only apply to ExportKindMP3 and ExportKindALAC.
+// EmbedCover/Metadata.CoverURL apply to ExportKindMP3, ExportKindALAC, and
+// ExportKindFLAC; Metadata.AnimatedArtworkURL only applies to ExportKindALAC.
+// Bitrate/VBR/Application/ChannelLayout apply to ExportKindOpus and
+// ExportKindOgg: Bitrate is always the target kbps (used directly for Opus,
+// and as the CBR fallback for Ogg when VBR is false); Quality doubles as
+// libvorbis's -q:a scale (-1 to 10) when Ogg's VBR is true; Application is
+// Opus-only. LyricsLRC/LyricsLRCURL carry synced lyrics (literal LRC text,
+// or a URL to fetch it from) for ExportKindMP3 (embedded as ID3v2 USLT+SYLT)
+// and ExportKindStems (bundled as lyrics.lrc in the archive).
+type ExportJobPayload struct {
+	Kind                 ExportKind      `json:"kind"`
+	ProjectID            string          `json:"projectId"`
+	SourceURLs           []string        `json:"sourceUrls"`
+	Filenames            []string        `json:"filenames,omitempty"`
+	OutputKey            string          `json:"outputKey"`
+	Quality              int             `json:"quality,omitempty"`
+	BitDepth             int             `json:"bitDepth,omitempty"`
+	SampleRate           int             `json:"sampleRate,omitempty"`
+	Metadata             *ExportMetadata `json:"metadata,omitempty"`
+	EmbedLyrics          bool            `json:"embedLyrics,omitempty"`
+	EmbedCover           bool            `json:"embedCover,omitempty"`
+	CompressionLevel     int             `json:"compressionLevel,omitempty"`
+	BedLayout            string          `json:"bedLayout,omitempty"`
+	ObjectCount          int             `json:"objectCount,omitempty"`
+	Binaural             bool            `json:"binaural,omitempty"`
+	ObjectAutomationURLs []string        `json:"objectAutomationUrls,omitempty"`
+	Bitrate              int             `json:"bitrate,omitempty"`
+	VBR                  bool            `json:"vbr,omitempty"`
+	Application          string          `json:"application,omitempty"`
+	ChannelLayout        string          `json:"channelLayout,omitempty"`
+	LyricsLRC            string          `json:"lyricsLrc,omitempty"`
+	LyricsLRCURL         string          `json:"lyricsLrcUrl,omitempty"`
 }
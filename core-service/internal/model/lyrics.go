@@ -1,20 +1,35 @@
 package model
 
-// LyricsGenerateRequest represents the request body for lyrics generation
+// LyricsGenerateRequest represents the request body for lyrics generation.
+// ProjectID, when set, lets service.LyricsService's filesystem agent serve
+// lyrics already written to disk for this project instead of generating
+// new ones. SeedArtist/SeedTitle, when both set, let its LRCLIB agent look
+// up an existing track's lyrics to draw from rather than generating from
+// scratch.
 type LyricsGenerateRequest struct {
+	ProjectID   string      `json:"projectId" validate:"omitempty,uuid"`
 	Genre       Genre       `json:"genre" validate:"required,oneof=pop rock hiphop rnb electronic jazz country folk classical latin reggae blues"`
 	SectionType SectionType `json:"sectionType" validate:"required,oneof=intro verse prechorus chorus bridge outro instrumental"`
 	Vibes       []string    `json:"vibes" validate:"required,min=1,max=3,dive,min=1"`
 	Language    Language    `json:"language" validate:"omitempty,oneof=en tr fr"`
+	SeedArtist  string      `json:"seedArtist" validate:"omitempty,max=200"`
+	SeedTitle   string      `json:"seedTitle" validate:"omitempty,max=200"`
 }
 
-// LyricsGenerateResponse represents the response for lyrics generation
+// LyricsGenerateResponse represents the response for lyrics generation.
+// Cached is set by handler.LyricsHandler after the fact (from the same hit/
+// miss flag that drives the X-Cache header) -- no agent populates it itself.
 type LyricsGenerateResponse struct {
 	Drafts [][]string `json:"drafts"`
+	Cached bool       `json:"cached"`
 }
 
-// LyricsRewriteRequest represents the request body for lyrics rewriting
+// LyricsRewriteRequest represents the request body for lyrics rewriting.
+// ProjectID, when set, lets service.LyricsService's filesystem agent serve
+// lyrics already written to disk for this project instead of rewriting
+// CurrentLyrics with AI.
 type LyricsRewriteRequest struct {
+	ProjectID     string      `json:"projectId" validate:"omitempty,uuid"`
 	CurrentLyrics string      `json:"currentLyrics" validate:"required,min=1"`
 	Genre         Genre       `json:"genre" validate:"required,oneof=pop rock hiphop rnb electronic jazz country folk classical latin reggae blues"`
 	SectionType   SectionType `json:"sectionType" validate:"required,oneof=intro verse prechorus chorus bridge outro instrumental"`
@@ -22,7 +37,61 @@ type LyricsRewriteRequest struct {
 	Instructions  string      `json:"instructions" validate:"omitempty,max=500"`
 }
 
-// LyricsRewriteResponse represents the response for lyrics rewriting
+// LyricsRewriteResponse represents the response for lyrics rewriting. Cached
+// is set by handler.LyricsHandler the same way as LyricsGenerateResponse's.
 type LyricsRewriteResponse struct {
-	Lines []string `json:"lines"`
+	Lines  []string `json:"lines"`
+	Cached bool     `json:"cached"`
+}
+
+// LyricsExportRequest requests a time-synced lyrics file (an LRC-family
+// format, or a subtitle cue file) built from a set of lyrics lines already
+// anchored to section timing. That bar-to-seconds timing is computed by the
+// render engine from the project's Brief/Structure/BPM, but is not yet
+// persisted anywhere queryable by project ID alone, so callers that already
+// hold it (the client that just rendered the track) pass it through in
+// Lines rather than having it looked up server-side.
+type LyricsExportRequest struct {
+	ProjectID     string           `json:"projectId" validate:"required,uuid"`
+	Format        string           `json:"format" validate:"required,oneof=lrc elrc srt vtt"`
+	Lines         []TimedLyricLine `json:"lines" validate:"required,min=1,dive"`
+	Offset        int              `json:"offset" validate:"omitempty"` // milliseconds, added to every timestamp
+	Encoding      string           `json:"encoding" validate:"omitempty,oneof=utf8 utf8bom"`
+	IncludeTitle  bool             `json:"includeTitle"`
+	IncludeArtist bool             `json:"includeArtist"`
+	IncludeAlbum  bool             `json:"includeAlbum"`
+	Title         string           `json:"title" validate:"omitempty,max=200"`
+	Artist        string           `json:"artist" validate:"omitempty,max=200"`
+	Album         string           `json:"album" validate:"omitempty,max=200"`
+}
+
+// TimedLyricLine is one line of lyrics anchored to a start time. Words is
+// optional per-word timing used for enhanced LRC (format "elrc"); when
+// omitted, enhanced LRC falls back to tagging the whole line at StartSeconds.
+type TimedLyricLine struct {
+	StartSeconds float64     `json:"startSeconds" validate:"min=0"`
+	Text         string      `json:"text" validate:"required"`
+	Words        []TimedWord `json:"words" validate:"omitempty,dive"`
+}
+
+// TimedWord is a single word within a TimedLyricLine, anchored to its own
+// start time for per-word (enhanced LRC) highlighting.
+type TimedWord struct {
+	StartSeconds float64 `json:"startSeconds" validate:"min=0"`
+	Text         string  `json:"text" validate:"required"`
+}
+
+// LyricsCachePurgeResponse reports how many cached generate/rewrite
+// entries a DELETE /api/lyrics/cache call evicted.
+type LyricsCachePurgeResponse struct {
+	PurgedCount int `json:"purgedCount"`
+}
+
+// LyricsExportResponse carries the generated lyrics file as inline content,
+// mirroring how the rest of the API returns JSON rather than raw file
+// downloads.
+type LyricsExportResponse struct {
+	Filename string `json:"filename"`
+	MimeType string `json:"mimeType"`
+	Content  string `json:"content"`
 }
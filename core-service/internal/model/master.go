@@ -39,6 +39,20 @@ type MasterFinalRequest struct {
 	StemURLs    []string      `json:"stemUrls" validate:"required,min=1,dive,url"`
 	MixSnapshot MixSnapshot   `json:"mixSnapshot" validate:"required"`
 	VocalTakes  []VocalTake   `json:"vocalTakes" validate:"omitempty,dive"`
+
+	// CallbackURL/CallbackSecret opt this job into a webhook delivery when
+	// it reaches a terminal status (see RenderService.fireWebhook): both
+	// are optional, but CallbackSecret is required alongside CallbackURL
+	// since an unsigned callback isn't verifiable by the receiver. The
+	// "required_with" tag enforces that pairing the same way validator
+	// already enforces every other cross-field rule in this codebase. The
+	// "url" tag here only checks that CallbackURL is syntactically a URL --
+	// RenderService.StartMaster additionally resolves the host and rejects
+	// loopback/link-local/private addresses (webhook.ValidateCallbackURL)
+	// before the job is queued, since that check needs a DNS lookup this
+	// struct tag can't perform.
+	CallbackURL    string `json:"callbackUrl,omitempty" validate:"omitempty,url"`
+	CallbackSecret string `json:"callbackSecret,omitempty" validate:"required_with=CallbackURL,omitempty,min=16"`
 }
 
 // VocalTake represents a vocal take for mastering
@@ -58,10 +72,47 @@ type MasterFinalResponse struct {
 
 // MasterStatusResponse represents the status of a master job
 type MasterStatusResponse struct {
-	JobID       string    `json:"jobId"`
-	Status      JobStatus `json:"status"`
-	Progress    int       `json:"progress"`
-	CurrentStep string    `json:"currentStep,omitempty"`
+	JobID         string    `json:"jobId"`
+	Status        JobStatus `json:"status"`
+	Progress      int       `json:"progress"`
+	CurrentStep   string    `json:"currentStep,omitempty"`
+	ArchiveStatus string    `json:"archiveStatus,omitempty"`
+}
+
+// MasterBatchRequest submits multiple final-mastering jobs in one call.
+// Each item is validated independently (see MasterHandler.Batch) so one
+// bad entry doesn't block the rest; Items keeps request order, and each
+// result in MasterBatchResponse.Items reports back at the same Index.
+type MasterBatchRequest struct {
+	Items []MasterFinalRequest `json:"items" validate:"required,min=1,max=50"`
+}
+
+// MasterBatchResponse reports the group ID every job the batch started
+// shares, plus each item's outcome in request order.
+type MasterBatchResponse struct {
+	GroupID string             `json:"groupId"`
+	Items   []MasterBatchItem  `json:"items"`
+}
+
+// MasterBatchItem is one MasterBatchRequest.Items entry's outcome: either a
+// started job (JobID/Status set, Errors empty) or one or more validation
+// failures (Errors set, JobID empty) -- never both.
+type MasterBatchItem struct {
+	Index  int                    `json:"index"`
+	JobID  string                 `json:"jobId,omitempty"`
+	Status JobStatus              `json:"status,omitempty"`
+	Errors []MasterBatchItemError `json:"errors,omitempty"`
+}
+
+// MasterBatchItemError is the validation-failure shape for one
+// MasterBatchItem. It's kept local to this package rather than reusing
+// pkg/response.Violation directly, since model has no dependency on the
+// response envelope layer -- MasterHandler.Batch is what maps one onto the
+// other, prefixing Path with this item's ["items", index] location.
+type MasterBatchItemError struct {
+	Slug    string        `json:"slug"`
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
 }
 
 // MasterResultResponse represents the result of completed mastering
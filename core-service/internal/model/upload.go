@@ -6,8 +6,91 @@ import "time"
 type UploadVocalResponse struct {
 	ID         string    `json:"id"`
 	FileURL    string    `json:"fileUrl"`
+	Codec      string    `json:"codec"`
 	Duration   float64   `json:"duration"`
 	SampleRate int       `json:"sampleRate"`
 	Channels   int       `json:"channels"`
 	CreatedAt  time.Time `json:"createdAt"`
 }
+
+// UploadBatchEntryResult is the outcome of uploading one file contained in a
+// batch ZIP archive. Exactly one of Result/Error is set.
+type UploadBatchEntryResult struct {
+	Filename string               `json:"filename"`
+	TakeName string               `json:"takeName,omitempty"`
+	Success  bool                 `json:"success"`
+	Result   *UploadVocalResponse `json:"result,omitempty"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// UploadBatchResponse represents the response for a batch vocal-take upload.
+// Per-entry failures don't fail the whole request; callers inspect Entries.
+type UploadBatchResponse struct {
+	Entries []UploadBatchEntryResult `json:"entries"`
+}
+
+// PresignVocalUploadRequest is the request to presign a direct-to-storage
+// vocal upload. ContentLength and ContentType are both enforced server-side
+// (size cap, MIME whitelist) before a URL is ever minted, since the upload
+// itself bypasses this API and can't be checked again until Complete.
+// ContentSHA256 is optional and, if supplied, is only recorded for the
+// caller's own later reference -- unlike the chunked-upload path, it isn't
+// verified here (Complete only has a HeadObject to go on, and S3 ETags
+// aren't SHA-256 digests).
+type PresignVocalUploadRequest struct {
+	ProjectID     string `json:"projectId" validate:"required"`
+	SectionID     string `json:"sectionId" validate:"required"`
+	TakeName      string `json:"takeName"`
+	ContentType   string `json:"contentType" validate:"required"`
+	ContentLength int64  `json:"contentLength" validate:"required,gt=0"`
+	ContentSHA256 string `json:"contentSha256,omitempty" validate:"omitempty,len=64,hexadecimal"`
+}
+
+// PresignVocalUploadResponse is the response for a direct-to-storage vocal
+// upload: the client PUTs its file to UploadURL with the same Content-Type
+// it requested the presign with, instead of routing the body through this
+// API, then calls POST /api/upload/vocal/complete/{id} so the server can
+// confirm the object landed before the take is considered uploaded.
+type PresignVocalUploadResponse struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	UploadURL string    `json:"uploadUrl"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ChunkedUploadInitRequest starts a resumable vocal-take upload.
+// ContentSHA256 is optional: a client that already hashed the take locally
+// (the common case -- it has the whole file before it starts resuming
+// chunks of it) can supply it so the server can both verify the upload on
+// completion and, if an object with that digest is already stored, skip
+// the transfer entirely. See ChunkedUploadService.Init.
+type ChunkedUploadInitRequest struct {
+	ProjectID     string `json:"projectId" validate:"required"`
+	SectionID     string `json:"sectionId" validate:"required"`
+	TakeName      string `json:"takeName"`
+	ContentType   string `json:"contentType" validate:"required"`
+	TotalSize     int64  `json:"totalSize" validate:"required,gt=0"`
+	ContentSHA256 string `json:"contentSha256,omitempty" validate:"omitempty,len=64,hexadecimal"`
+}
+
+// ChunkedUploadInitResponse carries the new upload's ID and the minimum
+// chunk size the client must send per PATCH (every part but the last one
+// must meet it, an S3 multipart-upload constraint). If the caller supplied
+// ContentSHA256 and an object with that digest already exists, Deduplicated
+// is true and FileURL is populated immediately -- the client doesn't PATCH
+// or complete anything, UploadID is empty.
+type ChunkedUploadInitResponse struct {
+	UploadID     string `json:"uploadId,omitempty"`
+	MinChunkSize int64  `json:"minChunkSize,omitempty"`
+	Deduplicated bool   `json:"deduplicated,omitempty"`
+	FileURL      string `json:"fileUrl,omitempty"`
+}
+
+// ChunkedUploadOffsetResponse reports how many bytes of a resumable upload
+// have been committed so far, so a client can resume a PATCH stream after a
+// dropped connection without re-sending already-received bytes.
+type ChunkedUploadOffsetResponse struct {
+	UploadID string `json:"uploadId"`
+	Offset   int64  `json:"offset"`
+	Complete bool   `json:"complete"`
+}
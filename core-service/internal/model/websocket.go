@@ -5,6 +5,7 @@ const (
 	WSMessageTypeProgress = "progress"
 	WSMessageTypeComplete = "complete"
 	WSMessageTypeError    = "error"
+	WSMessageTypeLog      = "log"
 	WSMessageTypePing     = "ping"
 	WSMessageTypePong     = "pong"
 )
@@ -21,6 +22,7 @@ type WSProgressMessage struct {
 	Progress    int       `json:"progress"`
 	Status      JobStatus `json:"status"`
 	CurrentStep string    `json:"currentStep,omitempty"`
+	Seq         int64     `json:"seq"`
 }
 
 // WSCompleteMessage represents job completion
@@ -28,6 +30,7 @@ type WSCompleteMessage struct {
 	Type   string      `json:"type"`
 	JobID  string      `json:"jobId"`
 	Result interface{} `json:"result"`
+	Seq    int64       `json:"seq"`
 }
 
 // WSErrorMessage represents an error
@@ -35,10 +38,20 @@ type WSErrorMessage struct {
 	Type  string  `json:"type"`
 	JobID string  `json:"jobId"`
 	Error WSError `json:"error"`
+	Seq   int64   `json:"seq"`
+}
+
+// WSLogMessage represents a batch of worker log lines for a job
+type WSLogMessage struct {
+	Type   string   `json:"type"`
+	JobID  string   `json:"jobId"`
+	Stream string   `json:"stream"` // e.g. "stdout" or "stderr"
+	Lines  []string `json:"lines"`
 }
 
 // WSError represents error details
 type WSError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Items   []ItemFailure `json:"items,omitempty"`
 }
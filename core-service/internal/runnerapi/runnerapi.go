@@ -0,0 +1,127 @@
+// Package runnerapi defines the RPC contract between the API process and
+// the standalone job runner binary (cmd/runner). The runner pulls tasks
+// from Asynq and executes them against the Suno/audio microservices, but
+// never writes job state directly — it reports progress, logs, completion,
+// and failure back to the API over this HTTP contract, so the API (which
+// owns the WebSocket hub and Redis job records) can fan updates out to
+// connected clients and trigger follow-up work like archival.
+package runnerapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// SharedSecretHeader carries the secret the runner presents on every call.
+// The API checks it via middleware.RunnerAuthMiddleware before a request
+// reaches a handler.
+const SharedSecretHeader = "X-Runner-Secret"
+
+// ProgressRequest reports incremental progress for a running job.
+type ProgressRequest struct {
+	Progress int    `json:"progress"`
+	Step     string `json:"step"`
+}
+
+// LogsRequest reports a batch of stdout/stderr lines for a running job.
+type LogsRequest struct {
+	Stream string   `json:"stream"`
+	Lines  []string `json:"lines" validate:"required,min=1"`
+}
+
+// CompleteRequest reports a successful job result. ArchiveKeys, if set, are
+// object keys the API should copy to cold storage once the result is saved.
+type CompleteRequest struct {
+	Result      json.RawMessage `json:"result"`
+	ArchiveKeys []string        `json:"archiveKeys,omitempty"`
+}
+
+// FailRequest reports that a job failed. StructuredError, when present,
+// takes precedence over Error so status responses can surface which items
+// failed within an otherwise-successful batch.
+type FailRequest struct {
+	Error           string                    `json:"error"`
+	StructuredError *model.JobStructuredError `json:"structuredError,omitempty"`
+}
+
+// Client is used by the runner to report job state back to the API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	secret     string
+}
+
+// NewClient creates a client that reports to the API at baseURL,
+// authenticating with the shared secret.
+func NewClient(baseURL, secret string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+		secret:     secret,
+	}
+}
+
+// Progress reports incremental job progress.
+func (c *Client) Progress(ctx context.Context, jobID string, progress int, step string) error {
+	return c.post(ctx, jobID, "progress", &ProgressRequest{Progress: progress, Step: step})
+}
+
+// Logs reports a batch of log lines for a job.
+func (c *Client) Logs(ctx context.Context, jobID, stream string, lines []string) error {
+	return c.post(ctx, jobID, "logs", &LogsRequest{Stream: stream, Lines: lines})
+}
+
+// Complete reports a successful job result, marshaling result to JSON.
+// archiveKeys, if non-empty, asks the API to archive those object keys to
+// cold storage once the result is saved.
+func (c *Client) Complete(ctx context.Context, jobID string, result interface{}, archiveKeys []string) error {
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return c.post(ctx, jobID, "complete", &CompleteRequest{Result: resultBytes, ArchiveKeys: archiveKeys})
+}
+
+// Fail reports a plain job failure.
+func (c *Client) Fail(ctx context.Context, jobID, errMsg string) error {
+	return c.post(ctx, jobID, "fail", &FailRequest{Error: errMsg})
+}
+
+// FailStructured reports a job failure with per-item failure detail.
+func (c *Client) FailStructured(ctx context.Context, jobID string, structErr *model.JobStructuredError) error {
+	return c.post(ctx, jobID, "fail", &FailRequest{Error: structErr.Message, StructuredError: structErr})
+}
+
+func (c *Client) post(ctx context.Context, jobID, action string, body interface{}) error {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/internal/jobs/%s/%s", c.baseURL, jobID, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SharedSecretHeader, c.secret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to report job state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("runner API call failed (status %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
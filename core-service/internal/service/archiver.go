@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/errs"
+	"github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/internal/model"
+)
+
+const archiveTimeout = 5 * time.Minute
+
+// Archiver copies completed jobs' output objects from the hot working bucket
+// to a cold/long-term bucket in the background, without blocking the worker
+// that just finished the job.
+type Archiver struct {
+	redis      *redis.Client
+	storage    client.StorageClient
+	coldBucket string
+
+	// OngoingArchivings tracks in-flight archival goroutines so main.go's
+	// graceful shutdown can wait for them before exiting.
+	OngoingArchivings sync.WaitGroup
+}
+
+// NewArchiver creates a new Archiver. Archival is a no-op if coldBucket is
+// empty, matching the repo's convention of degrading gracefully when optional
+// infrastructure isn't configured.
+func NewArchiver(redisClient *redis.Client, storageClient client.StorageClient, coldBucket string) *Archiver {
+	return &Archiver{
+		redis:      redisClient,
+		storage:    storageClient,
+		coldBucket: coldBucket,
+	}
+}
+
+// Archive schedules background archival of keys for jobID, marking the job
+// pending immediately and succeeded/failed once the copy finishes.
+func (a *Archiver) Archive(jobID string, keys []string) {
+	if a.coldBucket == "" || a.storage == nil || len(keys) == 0 {
+		return
+	}
+
+	ctx := log.WithFields(context.Background(), "jobId", jobID)
+	if err := a.setArchiveStatus(ctx, jobID, model.ArchiveStatusPending); err != nil {
+		log.Error(ctx, "archiver: failed to mark job pending", "err", err)
+	}
+
+	a.OngoingArchivings.Add(1)
+	go func() {
+		defer a.OngoingArchivings.Done()
+
+		copyCtx, cancel := context.WithTimeout(ctx, archiveTimeout)
+		defer cancel()
+
+		status := model.ArchiveStatusSucceeded
+		for _, key := range keys {
+			if err := a.storage.Copy(copyCtx, key, a.coldBucket, key); err != nil {
+				log.Error(ctx, "archiver: failed to copy object", "key", key, "err", err)
+				status = model.ArchiveStatusFailed
+				break
+			}
+		}
+
+		if err := a.setArchiveStatus(ctx, jobID, status); err != nil {
+			log.Error(ctx, "archiver: failed to update job", "err", err)
+		}
+	}()
+}
+
+// ArchiveJob looks up jobID and re-triggers archival of its result, for the
+// admin re-trigger endpoint. It returns an error if the job isn't found or
+// hasn't produced any archivable output yet.
+func (a *Archiver) ArchiveJob(ctx context.Context, jobID string) error {
+	job, err := a.getJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	if job.Status != model.JobStatusSucceeded {
+		return errs.ErrJobHasNoResult
+	}
+
+	keys, err := a.resultKeys(job)
+	if err != nil {
+		return err
+	}
+
+	a.Archive(job.ID, keys)
+	return nil
+}
+
+// resultKeys extracts the object keys worth archiving from a job's stored
+// result, recovering them from the public URLs the workers generated.
+func (a *Archiver) resultKeys(job *model.Job) ([]string, error) {
+	switch job.Type {
+	case model.JobTypeMaster:
+		var result struct {
+			FileURL string `json:"fileUrl"`
+		}
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal master result: %w", err)
+		}
+		return []string{a.storage.KeyFromURL(result.FileURL)}, nil
+	case model.JobTypeRender:
+		var result struct {
+			Stems []struct {
+				FileURL string `json:"fileUrl"`
+			} `json:"stems"`
+		}
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal render result: %w", err)
+		}
+		keys := make([]string, 0, len(result.Stems))
+		for _, stem := range result.Stems {
+			keys = append(keys, a.storage.KeyFromURL(stem.FileURL))
+		}
+		return keys, nil
+	default:
+		return nil, fmt.Errorf("unknown job type %q", job.Type)
+	}
+}
+
+func (a *Archiver) setArchiveStatus(ctx context.Context, jobID string, status model.ArchiveStatus) error {
+	job, err := a.getJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	job.ArchiveStatus = status
+	return a.saveJob(ctx, job)
+}
+
+func (a *Archiver) getJob(ctx context.Context, jobID string) (*model.Job, error) {
+	data, err := a.redis.Get(ctx, fmt.Sprintf("job:%s", jobID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errs.ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	var job model.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (a *Archiver) saveJob(ctx context.Context, job *model.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return a.redis.Set(ctx, fmt.Sprintf("job:%s", job.ID), data, 24*time.Hour).Err()
+}
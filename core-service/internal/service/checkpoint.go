@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RenderCheckpoint records how far RenderWorker.processWithSuno has gotten
+// through its Suno pipeline, so a retried task (asynq redelivery after a
+// network blip or worker restart) can resume instead of re-running steps
+// that already cost real Suno API usage.
+type RenderCheckpoint struct {
+	// Stage is the last step that completed successfully: "", "music_queued",
+	// "music_done", "stems_queued", "stems_done", or "stems_uploaded".
+	Stage string `json:"stage"`
+
+	// SunoMusicTaskID is set once GenerateMusic has been called, so a retry
+	// polls the existing Suno task instead of starting a new one.
+	SunoMusicTaskID string `json:"sunoMusicTaskId,omitempty"`
+
+	// SunoStemTaskID is set once SplitStems has been called, for the same
+	// reason.
+	SunoStemTaskID string `json:"sunoStemTaskId,omitempty"`
+
+	// UploadedStemKeys maps a stem's name to the object storage key it was
+	// already uploaded to, so a retry re-presigns rather than re-downloading
+	// from Suno and re-uploading to R2.
+	UploadedStemKeys map[string]string `json:"uploadedStemKeys,omitempty"`
+}
+
+func checkpointKey(jobID string) string {
+	return fmt.Sprintf("job:%s:checkpoint", jobID)
+}
+
+// SaveCheckpoint persists cp for jobID with the same retention as the job
+// record it belongs to, so it never outlives the job it's resuming.
+func SaveCheckpoint(ctx context.Context, redisClient *redis.Client, jobID string, cp *RenderCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	return redisClient.Set(ctx, checkpointKey(jobID), data, 24*time.Hour).Err()
+}
+
+// GetCheckpoint returns jobID's checkpoint, or a zero-value checkpoint if
+// none has been saved yet (first attempt, no retry in progress).
+func GetCheckpoint(ctx context.Context, redisClient *redis.Client, jobID string) (*RenderCheckpoint, error) {
+	data, err := redisClient.Get(ctx, checkpointKey(jobID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return &RenderCheckpoint{}, nil
+		}
+		return nil, err
+	}
+
+	var cp RenderCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// ClearCheckpoint deletes jobID's checkpoint. Called once the job reaches a
+// terminal state (succeeded or failed) so a later, unrelated retry of the
+// same jobID can never resume from stale progress.
+func ClearCheckpoint(ctx context.Context, redisClient *redis.Client, jobID string) error {
+	return redisClient.Del(ctx, checkpointKey(jobID)).Err()
+}
+
+func cancelledFlagKey(jobID string) string {
+	return fmt.Sprintf("job:cancelled:%s", jobID)
+}
+
+// IsCancelled reports whether jobID has been flagged for cancellation. Unlike
+// the pub/sub notice WatchForCancellation listens for, this flag is durable:
+// it survives a worker restart, so a checkpoint-resumed task still notices a
+// cancellation that was published while nothing was subscribed to hear it.
+func IsCancelled(ctx context.Context, redisClient *redis.Client, jobID string) (bool, error) {
+	n, err := redisClient.Exists(ctx, cancelledFlagKey(jobID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
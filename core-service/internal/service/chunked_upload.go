@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/model"
+)
+
+const (
+	// chunkedUploadTTL bounds how long an abandoned resumable upload's state
+	// (and its S3-side multipart upload) is kept before it's considered
+	// stale. There is no background sweeper yet -- see ChunkedUploadService
+	// doc comment.
+	chunkedUploadTTL = 24 * time.Hour
+
+	// minChunkSize is the smallest part a client may PATCH, short of the
+	// final part -- an S3 multipart-upload constraint (every part but the
+	// last must be at least 5 MiB), not an arbitrary choice here.
+	minChunkSize = 5 * 1024 * 1024
+)
+
+// ErrChunkedUploadNotFound is returned when uploadID has no session, either
+// because it never existed or its TTL expired.
+var ErrChunkedUploadNotFound = fmt.Errorf("chunked upload not found")
+
+// ErrChunkedUploadOffsetMismatch is returned when a PATCH's Upload-Offset
+// doesn't match the session's committed offset, so the client knows to
+// re-sync via HEAD rather than silently corrupting the object.
+var ErrChunkedUploadOffsetMismatch = fmt.Errorf("chunked upload offset mismatch")
+
+// ErrChunkedUploadDigestMismatch is returned at Complete when the caller
+// supplied a ContentSHA256 at Init that doesn't match what was actually
+// received, so the object is never persisted under a digest claiming to be
+// content it isn't.
+var ErrChunkedUploadDigestMismatch = fmt.Errorf("chunked upload content does not match the declared sha256 digest")
+
+// chunkedUploadSession is the Redis-persisted state of one resumable
+// upload, keyed so any API replica can serve a PATCH/HEAD/complete call
+// regardless of which replica handled init.
+type chunkedUploadSession struct {
+	ProjectID   string                 `json:"projectId"`
+	SectionID   string                 `json:"sectionId"`
+	TakeName    string                 `json:"takeName"`
+	ContentType string                 `json:"contentType"`
+	Key         string                 `json:"key"`
+	S3UploadID  string                 `json:"s3UploadId"`
+	TotalSize   int64                  `json:"totalSize"`
+	Offset      int64                  `json:"offset"`
+	Parts       []client.CompletedPart `json:"parts"`
+
+	// ExpectedSHA256/HashState are set only when the caller supplied
+	// ContentSHA256 at Init. HashState is the running sha256 hasher's
+	// marshaled state (crypto/sha256's digest type implements
+	// encoding.BinaryMarshaler precisely so a hash can be checkpointed like
+	// this), updated after every Patch so verifying the final digest at
+	// Complete doesn't require re-reading the assembled object back from
+	// storage.
+	ExpectedSHA256 string `json:"expectedSha256,omitempty"`
+	HashState      []byte `json:"hashState,omitempty"`
+}
+
+// contentAddressedKey is the storage key a content-verified upload is
+// stored at, independent of project/section/uploadID, so a second upload of
+// the same bytes resolves to the same object.
+func contentAddressedKey(sha256Hex, contentType string) string {
+	return fmt.Sprintf("vocals/%s/%s.%s", sha256Hex[:2], sha256Hex, extensionForContentType(contentType))
+}
+
+// ChunkedUploadService implements a tus.io-style resumable upload on top of
+// S3 multipart uploads: each PATCH becomes one UploadPart call, so the API
+// process never has to spool a whole take to local disk. Session state
+// (offset, S3 upload ID, uploaded part ETags) lives in Redis with a TTL so
+// any API replica can resume a client's upload, not just the one that
+// served init.
+//
+// There is no background sweeper for abandoned uploads yet: the Redis
+// session expiring leaves an orphaned S3 multipart upload (and its parts)
+// billable until the bucket's own lifecycle rule reaps incomplete
+// multipart uploads -- most S3-compatible providers, including R2, support
+// configuring that bucket-side. A sweeper that calls AbortMultipartUpload
+// proactively is a reasonable follow-up if that's not acceptable.
+type ChunkedUploadService struct {
+	redis    *redis.Client
+	r2Client client.StorageClient
+}
+
+func NewChunkedUploadService(redisClient *redis.Client, r2Client client.StorageClient) *ChunkedUploadService {
+	return &ChunkedUploadService{redis: redisClient, r2Client: r2Client}
+}
+
+// Init starts a new resumable upload and returns its ID. If req carries a
+// ContentSHA256 and an object with that digest is already stored (a repeat
+// upload of a take the caller already transferred once), it short-circuits:
+// no multipart upload is created, and the response reports the existing
+// object's URL directly.
+func (s *ChunkedUploadService) Init(ctx context.Context, req *model.ChunkedUploadInitRequest) (*model.ChunkedUploadInitResponse, error) {
+	if s.r2Client == nil {
+		return nil, fmt.Errorf("object storage is not configured")
+	}
+
+	key := fmt.Sprintf("vocals/%s/%s/%s.%s", req.ProjectID, req.SectionID, uuid.New().String(), extensionForContentType(req.ContentType))
+	var expectedSHA256 string
+	if req.ContentSHA256 != "" {
+		key = contentAddressedKey(req.ContentSHA256, req.ContentType)
+		expectedSHA256 = req.ContentSHA256
+
+		exists, err := s.r2Client.Exists(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for an existing object: %w", err)
+		}
+		if exists {
+			return &model.ChunkedUploadInitResponse{Deduplicated: true, FileURL: s.r2Client.GetPublicURL(key)}, nil
+		}
+	}
+
+	s3UploadID, err := s.r2Client.CreateMultipartUpload(ctx, key, req.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	uploadID := uuid.New().String()
+	session := chunkedUploadSession{
+		ProjectID:      req.ProjectID,
+		SectionID:      req.SectionID,
+		TakeName:       req.TakeName,
+		ContentType:    req.ContentType,
+		Key:            key,
+		S3UploadID:     s3UploadID,
+		TotalSize:      req.TotalSize,
+		ExpectedSHA256: expectedSHA256,
+	}
+	if expectedSHA256 != "" {
+		state, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize content hasher: %w", err)
+		}
+		session.HashState = state
+	}
+	if err := s.save(ctx, uploadID, &session); err != nil {
+		return nil, err
+	}
+
+	return &model.ChunkedUploadInitResponse{UploadID: uploadID, MinChunkSize: minChunkSize}, nil
+}
+
+// Offset returns how many bytes of uploadID have been committed, for a
+// client resuming after a dropped connection.
+func (s *ChunkedUploadService) Offset(ctx context.Context, uploadID string) (*model.ChunkedUploadOffsetResponse, error) {
+	session, err := s.load(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ChunkedUploadOffsetResponse{
+		UploadID: uploadID,
+		Offset:   session.Offset,
+		Complete: session.Offset >= session.TotalSize,
+	}, nil
+}
+
+// Patch appends one chunk starting at offset. offset must equal the
+// session's current committed offset -- otherwise the client and server
+// have drifted (e.g. a retried request after a response was lost) and the
+// caller should HEAD to resync rather than risk writing the chunk at the
+// wrong position. Every chunk but the final one must be at least
+// minChunkSize, mirroring the S3 multipart-upload part-size constraint.
+func (s *ChunkedUploadService) Patch(ctx context.Context, uploadID string, offset int64, chunk []byte) (*model.ChunkedUploadOffsetResponse, error) {
+	session, err := s.load(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != session.Offset {
+		return nil, ErrChunkedUploadOffsetMismatch
+	}
+
+	isFinal := session.Offset+int64(len(chunk)) >= session.TotalSize
+	if !isFinal && int64(len(chunk)) < minChunkSize {
+		return nil, fmt.Errorf("chunk smaller than minimum part size %d bytes (not the final chunk)", minChunkSize)
+	}
+
+	partNumber := int32(len(session.Parts)) + 1
+	etag, err := s.r2Client.UploadPart(ctx, session.Key, session.S3UploadID, partNumber, bytes.NewReader(chunk))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+
+	session.Parts = append(session.Parts, client.CompletedPart{PartNumber: partNumber, ETag: etag})
+	session.Offset += int64(len(chunk))
+
+	if session.ExpectedSHA256 != "" {
+		hasher := sha256.New()
+		if err := hasher.(encoding.BinaryUnmarshaler).UnmarshalBinary(session.HashState); err != nil {
+			return nil, fmt.Errorf("failed to resume content hasher: %w", err)
+		}
+		hasher.Write(chunk)
+		state, err := hasher.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to checkpoint content hasher: %w", err)
+		}
+		session.HashState = state
+	}
+
+	if err := s.save(ctx, uploadID, session); err != nil {
+		return nil, err
+	}
+
+	return &model.ChunkedUploadOffsetResponse{
+		UploadID: uploadID,
+		Offset:   session.Offset,
+		Complete: session.Offset >= session.TotalSize,
+	}, nil
+}
+
+// Complete assembles the uploaded parts into the final object. The caller
+// must have PATCHed the full TotalSize first. If Init was given a
+// ContentSHA256, the digest actually received is checked against it before
+// the multipart upload is completed -- a mismatch aborts the upload rather
+// than persisting content under a key that claims to be something it isn't.
+func (s *ChunkedUploadService) Complete(ctx context.Context, uploadID string) (*model.UploadVocalResponse, error) {
+	session, err := s.load(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Offset < session.TotalSize {
+		return nil, fmt.Errorf("upload incomplete: received %d of %d bytes", session.Offset, session.TotalSize)
+	}
+
+	if session.ExpectedSHA256 != "" {
+		hasher := sha256.New()
+		if err := hasher.(encoding.BinaryUnmarshaler).UnmarshalBinary(session.HashState); err != nil {
+			return nil, fmt.Errorf("failed to resume content hasher: %w", err)
+		}
+		if actual := hex.EncodeToString(hasher.Sum(nil)); actual != session.ExpectedSHA256 {
+			_ = s.r2Client.AbortMultipartUpload(ctx, session.Key, session.S3UploadID)
+			s.redis.Del(ctx, chunkedUploadKey(uploadID))
+			return nil, ErrChunkedUploadDigestMismatch
+		}
+	}
+
+	fileURL, err := s.r2Client.CompleteMultipartUpload(ctx, session.Key, session.S3UploadID, session.Parts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	s.redis.Del(ctx, chunkedUploadKey(uploadID))
+
+	return &model.UploadVocalResponse{
+		ID:        uploadID,
+		FileURL:   fileURL,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Abort discards uploadID's S3 multipart upload and Redis session, for a
+// client that gives up partway through.
+func (s *ChunkedUploadService) Abort(ctx context.Context, uploadID string) error {
+	session, err := s.load(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+	if err := s.r2Client.AbortMultipartUpload(ctx, session.Key, session.S3UploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return s.redis.Del(ctx, chunkedUploadKey(uploadID)).Err()
+}
+
+func (s *ChunkedUploadService) load(ctx context.Context, uploadID string) (*chunkedUploadSession, error) {
+	data, err := s.redis.Get(ctx, chunkedUploadKey(uploadID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrChunkedUploadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upload session: %w", err)
+	}
+
+	var session chunkedUploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal upload session: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *ChunkedUploadService) save(ctx context.Context, uploadID string, session *chunkedUploadSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload session: %w", err)
+	}
+	return s.redis.Set(ctx, chunkedUploadKey(uploadID), data, chunkedUploadTTL).Err()
+}
+
+func chunkedUploadKey(uploadID string) string {
+	return fmt.Sprintf("upload:chunked:%s", uploadID)
+}
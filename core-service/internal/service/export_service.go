@@ -2,95 +2,99 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/makeasinger/api/internal/client"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/errs"
 	"github.com/makeasinger/api/internal/model"
 )
 
-// FileExporter defines the interface for file export operations
-type FileExporter interface {
-	ExportMP3(ctx context.Context, req *model.ExportMP3Request) (*model.ExportMP3Response, error)
-	ExportWAV(ctx context.Context, req *model.ExportWAVRequest) (*model.ExportWAVResponse, error)
-	ExportStems(ctx context.Context, req *model.ExportStemsRequest) (*model.ExportStemsResponse, error)
-}
+// TaskTypeExport is the Asynq task type consumed by worker.ExportWorker.
+const TaskTypeExport = "export:process"
 
-// ExportService handles file exports using the audio processing service
+// ExportService queues export jobs (MP3/WAV/ALAC/FLAC/Opus/Ogg/Atmos/stems)
+// and reports their status,
+// mirroring RenderService's queue-and-poll shape. The actual encoding work
+// happens out of process in worker.ExportWorker, which reports progress and
+// completion back over the same internal/runnerapi contract render and
+// master jobs use.
 type ExportService struct {
-	r2Client    client.StorageClient
-	audioClient client.AudioProcessor
+	redis       *redis.Client
+	asynqClient *asynq.Client
+	lyricsTimed *LyricsTimedService
 }
 
 // NewExportService creates a new export service
-func NewExportService(r2Client client.StorageClient, audioClient client.AudioProcessor) *ExportService {
+func NewExportService(redisClient *redis.Client, asynqClient *asynq.Client, lyricsTimed *LyricsTimedService) *ExportService {
 	return &ExportService{
-		r2Client:    r2Client,
-		audioClient: audioClient,
+		redis:       redisClient,
+		asynqClient: asynqClient,
+		lyricsTimed: lyricsTimed,
 	}
 }
 
-// ExportMP3 exports master to MP3 format
-func (s *ExportService) ExportMP3(ctx context.Context, req *model.ExportMP3Request) (*model.ExportMP3Response, error) {
+// StartMP3 queues an MP3 export job
+func (s *ExportService) StartMP3(ctx context.Context, req *model.ExportMP3Request) (*model.ExportJobResponse, error) {
 	quality := 320
 	if req.Quality != nil {
 		quality = *req.Quality
 	}
 
-	// Use mock response if audio client is not configured
-	if s.audioClient == nil {
-		return s.exportMP3Mock(quality)
+	lyricsLRC := req.LyricsLRC
+	if lyricsLRC == "" && req.LyricsURL == "" && req.EmbedLyrics && req.Metadata != nil && req.Metadata.Lyrics != "" && req.MasterDurationSeconds != nil {
+		lyricsLRC = s.lyricsTimed.DeriveLRC(req.Metadata.Lyrics, *req.MasterDurationSeconds)
 	}
 
 	exportID := uuid.New().String()
-	outputKey := fmt.Sprintf("exports/%s.mp3", exportID)
-
-	// Build metadata map if provided
-	var metadata map[string]string
-	if req.Metadata != nil {
-		metadata = make(map[string]string)
-		if req.Metadata.Title != "" {
-			metadata["title"] = req.Metadata.Title
-		}
-		if req.Metadata.Artist != "" {
-			metadata["artist"] = req.Metadata.Artist
-		}
-		if req.Metadata.Album != "" {
-			metadata["album"] = req.Metadata.Album
-		}
-		if req.Metadata.Year != nil {
-			metadata["year"] = fmt.Sprintf("%d", *req.Metadata.Year)
-		}
+	payload := &model.ExportJobPayload{
+		Kind:         model.ExportKindMP3,
+		ProjectID:    req.ProjectID,
+		SourceURLs:   []string{req.MasterFileURL},
+		OutputKey:    fmt.Sprintf("exports/%s.mp3", exportID),
+		Quality:      quality,
+		Metadata:     req.Metadata,
+		EmbedLyrics:  req.EmbedLyrics,
+		EmbedCover:   embedCoverDefault(req.EmbedCover),
+		LyricsLRC:    lyricsLRC,
+		LyricsLRCURL: req.LyricsURL,
 	}
 
-	encodeReq := &client.EncodeRequest{
-		InputURL:  req.MasterFileURL,
-		Format:    "mp3",
-		Quality:   quality,
-		OutputKey: outputKey,
-		Metadata:  metadata,
+	return s.startExport(ctx, payload)
+}
+
+// StartWAV queues a WAV export job
+func (s *ExportService) StartWAV(ctx context.Context, req *model.ExportWAVRequest) (*model.ExportJobResponse, error) {
+	bitDepth := 24
+	sampleRate := 48000
+	if req.BitDepth != nil {
+		bitDepth = *req.BitDepth
+	}
+	if req.SampleRate != nil {
+		sampleRate = *req.SampleRate
 	}
 
-	resp, err := s.audioClient.Encode(ctx, encodeReq)
-	if err != nil {
-		return nil, fmt.Errorf("MP3 encoding failed: %w", err)
+	exportID := uuid.New().String()
+	payload := &model.ExportJobPayload{
+		Kind:       model.ExportKindWAV,
+		ProjectID:  req.ProjectID,
+		SourceURLs: []string{req.MasterFileURL},
+		OutputKey:  fmt.Sprintf("exports/%s.wav", exportID),
+		BitDepth:   bitDepth,
+		SampleRate: sampleRate,
 	}
 
-	return &model.ExportMP3Response{
-		FileURL:   resp.OutputURL,
-		Size:      resp.Size,
-		Format:    "mp3",
-		Quality:   quality,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
-	}, nil
+	return s.startExport(ctx, payload)
 }
 
-// ExportWAV exports master to WAV format
-func (s *ExportService) ExportWAV(ctx context.Context, req *model.ExportWAVRequest) (*model.ExportWAVResponse, error) {
+// StartALAC queues an Apple Lossless (ALAC) export job
+func (s *ExportService) StartALAC(ctx context.Context, req *model.ExportALACRequest) (*model.ExportJobResponse, error) {
 	bitDepth := 24
 	sampleRate := 48000
-
 	if req.BitDepth != nil {
 		bitDepth = *req.BitDepth
 	}
@@ -98,135 +102,298 @@ func (s *ExportService) ExportWAV(ctx context.Context, req *model.ExportWAVReque
 		sampleRate = *req.SampleRate
 	}
 
-	// Use mock response if audio client is not configured
-	if s.audioClient == nil {
-		return s.exportWAVMock(bitDepth, sampleRate)
+	exportID := uuid.New().String()
+	payload := &model.ExportJobPayload{
+		Kind:        model.ExportKindALAC,
+		ProjectID:   req.ProjectID,
+		SourceURLs:  []string{req.MasterFileURL},
+		OutputKey:   fmt.Sprintf("exports/%s.m4a", exportID),
+		BitDepth:    bitDepth,
+		SampleRate:  sampleRate,
+		Metadata:    req.Metadata,
+		EmbedLyrics: req.EmbedLyrics,
+		EmbedCover:  embedCoverDefault(req.EmbedCover),
+	}
+
+	return s.startExport(ctx, payload)
+}
+
+// StartFLAC queues a FLAC export job
+func (s *ExportService) StartFLAC(ctx context.Context, req *model.ExportFLACRequest) (*model.ExportJobResponse, error) {
+	bitDepth := 24
+	sampleRate := 48000
+	compressionLevel := 5
+	if req.BitDepth != nil {
+		bitDepth = *req.BitDepth
+	}
+	if req.SampleRate != nil {
+		sampleRate = *req.SampleRate
+	}
+	if req.CompressionLevel != nil {
+		compressionLevel = *req.CompressionLevel
 	}
 
 	exportID := uuid.New().String()
-	outputKey := fmt.Sprintf("exports/%s.wav", exportID)
+	payload := &model.ExportJobPayload{
+		Kind:             model.ExportKindFLAC,
+		ProjectID:        req.ProjectID,
+		SourceURLs:       []string{req.MasterFileURL},
+		OutputKey:        fmt.Sprintf("exports/%s.flac", exportID),
+		BitDepth:         bitDepth,
+		SampleRate:       sampleRate,
+		CompressionLevel: compressionLevel,
+		Metadata:         req.Metadata,
+		EmbedCover:       embedCoverDefault(req.EmbedCover),
+	}
 
-	encodeReq := &client.EncodeRequest{
-		InputURL:   req.MasterFileURL,
-		Format:     "wav",
-		BitDepth:   bitDepth,
-		SampleRate: sampleRate,
-		OutputKey:  outputKey,
+	return s.startExport(ctx, payload)
+}
+
+// StartOpus queues an Opus export job
+func (s *ExportService) StartOpus(ctx context.Context, req *model.ExportOpusRequest) (*model.ExportJobResponse, error) {
+	bitrate := 128
+	vbr := true
+	application := "audio"
+	channelLayout := "stereo"
+	if req.Bitrate != nil {
+		bitrate = *req.Bitrate
+	}
+	if req.VBR != nil {
+		vbr = *req.VBR
+	}
+	if req.Application != "" {
+		application = req.Application
+	}
+	if req.ChannelLayout != "" {
+		channelLayout = req.ChannelLayout
 	}
 
-	resp, err := s.audioClient.Encode(ctx, encodeReq)
-	if err != nil {
-		return nil, fmt.Errorf("WAV encoding failed: %w", err)
+	exportID := uuid.New().String()
+	payload := &model.ExportJobPayload{
+		Kind:          model.ExportKindOpus,
+		ProjectID:     req.ProjectID,
+		SourceURLs:    []string{req.MasterFileURL},
+		OutputKey:     fmt.Sprintf("exports/%s.opus", exportID),
+		Bitrate:       bitrate,
+		VBR:           vbr,
+		Application:   application,
+		ChannelLayout: channelLayout,
 	}
 
-	return &model.ExportWAVResponse{
-		FileURL:    resp.OutputURL,
-		Size:       resp.Size,
-		Format:     "wav",
-		BitDepth:   bitDepth,
-		SampleRate: sampleRate,
-		ExpiresAt:  time.Now().Add(24 * time.Hour),
-	}, nil
+	return s.startExport(ctx, payload)
 }
 
-// ExportStems exports stems as ZIP
-func (s *ExportService) ExportStems(ctx context.Context, req *model.ExportStemsRequest) (*model.ExportStemsResponse, error) {
-	// Use mock response if audio client is not configured
-	if s.audioClient == nil {
-		return s.exportStemsMock(req)
+// StartOgg queues an Ogg Vorbis export job
+func (s *ExportService) StartOgg(ctx context.Context, req *model.ExportOggRequest) (*model.ExportJobResponse, error) {
+	quality := 6
+	bitrate := 192
+	vbr := true
+	channelLayout := "stereo"
+	if req.Quality != nil {
+		quality = *req.Quality
+	}
+	if req.Bitrate != nil {
+		bitrate = *req.Bitrate
+	}
+	if req.VBR != nil {
+		vbr = *req.VBR
+	}
+	if req.ChannelLayout != "" {
+		channelLayout = req.ChannelLayout
+	}
+
+	exportID := uuid.New().String()
+	payload := &model.ExportJobPayload{
+		Kind:          model.ExportKindOgg,
+		ProjectID:     req.ProjectID,
+		SourceURLs:    []string{req.MasterFileURL},
+		OutputKey:     fmt.Sprintf("exports/%s.ogg", exportID),
+		Quality:       quality,
+		Bitrate:       bitrate,
+		VBR:           vbr,
+		ChannelLayout: channelLayout,
 	}
 
+	return s.startExport(ctx, payload)
+}
+
+// StartAtmos queues a Dolby Atmos spatial-audio export job. Rendering the
+// EC-3 JOC bitstream itself is delegated to the audio microservice (see
+// worker.ExportWorker.processWithAudioService) — ffmpeg has no Atmos encoder,
+// so the local fallback path can only fail fast with an explanatory error.
+func (s *ExportService) StartAtmos(ctx context.Context, req *model.ExportAtmosRequest) (*model.ExportJobResponse, error) {
 	exportID := uuid.New().String()
-	outputKey := fmt.Sprintf("exports/%s.zip", exportID)
 
-	// Build file list for ZIP
-	files := make([]client.ZipFileEntry, 0)
+	payload := &model.ExportJobPayload{
+		Kind:                 model.ExportKindAtmos,
+		ProjectID:            req.ProjectID,
+		SourceURLs:           []string{req.MasterFileURL},
+		OutputKey:            fmt.Sprintf("exports/%s.ec3", exportID),
+		BedLayout:            req.BedLayout,
+		ObjectCount:          req.ObjectCount,
+		Binaural:             req.Binaural,
+		ObjectAutomationURLs: req.ObjectAutomationURLs,
+	}
+
+	return s.startExport(ctx, payload)
+}
+
+// StartStems queues a stems-archive export job
+func (s *ExportService) StartStems(ctx context.Context, req *model.ExportStemsRequest) (*model.ExportJobResponse, error) {
+	var urls, names []string
 
-	// Add stems
 	for i, url := range req.StemURLs {
-		files = append(files, client.ZipFileEntry{
-			URL:      url,
-			Filename: fmt.Sprintf("stems/stem_%d.wav", i+1),
-		})
+		urls = append(urls, url)
+		names = append(names, fmt.Sprintf("stems/stem_%d.wav", i+1))
 	}
-
-	// Add vocals if requested
-	if req.IncludeVocals && len(req.VocalURLs) > 0 {
+	if req.IncludeVocals {
 		for i, url := range req.VocalURLs {
-			files = append(files, client.ZipFileEntry{
-				URL:      url,
-				Filename: fmt.Sprintf("vocals/vocal_%d.wav", i+1),
-			})
+			urls = append(urls, url)
+			names = append(names, fmt.Sprintf("vocals/vocal_%d.wav", i+1))
 		}
 	}
-
-	// Add master if requested
 	if req.IncludeMaster && req.MasterURL != "" {
-		files = append(files, client.ZipFileEntry{
-			URL:      req.MasterURL,
-			Filename: "master.wav",
-		})
+		urls = append(urls, req.MasterURL)
+		names = append(names, "master.wav")
 	}
 
-	zipReq := &client.ZipRequest{
-		Files:     files,
-		OutputKey: outputKey,
+	exportID := uuid.New().String()
+	payload := &model.ExportJobPayload{
+		Kind:         model.ExportKindStems,
+		ProjectID:    req.ProjectID,
+		SourceURLs:   urls,
+		Filenames:    names,
+		OutputKey:    fmt.Sprintf("exports/%s.zip", exportID),
+		LyricsLRC:    req.LyricsLRC,
+		LyricsLRCURL: req.LyricsURL,
 	}
 
-	resp, err := s.audioClient.CreateZip(ctx, zipReq)
+	return s.startExport(ctx, payload)
+}
+
+// embedCoverDefault reports whether cover art should be embedded: true
+// unless the caller explicitly opted out with embedCover: false.
+func embedCoverDefault(embedCover *bool) bool {
+	return embedCover == nil || *embedCover
+}
+
+func (s *ExportService) startExport(ctx context.Context, payload *model.ExportJobPayload) (*model.ExportJobResponse, error) {
+	jobID := uuid.New().String()
+	now := time.Now()
+
+	job := &model.Job{
+		ID:        jobID,
+		Type:      model.JobTypeExport,
+		Status:    model.JobStatusQueued,
+		Progress:  0,
+		CreatedAt: now,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("ZIP creation failed: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
+	job.Payload = payloadBytes
 
-	return &model.ExportStemsResponse{
-		FileURL:   resp.OutputURL,
-		Size:      resp.Size,
-		FileCount: resp.FileCount,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+	if err := s.saveJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	task, err := newExportTask(jobID, payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	_, err = s.asynqClient.Enqueue(task,
+		asynq.Queue("export"),
+		asynq.MaxRetry(3),
+		asynq.Retention(24*time.Hour),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	jobsEnqueuedTotal.WithLabelValues("export").Inc()
+
+	return &model.ExportJobResponse{
+		JobID:     jobID,
+		Status:    model.JobStatusQueued,
+		CreatedAt: now,
 	}, nil
 }
 
-// Mock implementations for development/testing
-func (s *ExportService) exportMP3Mock(quality int) (*model.ExportMP3Response, error) {
-	exportID := uuid.New().String()
+// GetStatus returns the current status of an export job
+func (s *ExportService) GetStatus(ctx context.Context, jobID string) (*model.RenderStatusResponse, error) {
+	job, err := s.getJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
 
-	return &model.ExportMP3Response{
-		FileURL:   fmt.Sprintf("https://cdn.makeasinger.com/exports/%s.mp3", exportID),
-		Size:      5242880, // ~5MB
-		Format:    "mp3",
-		Quality:   quality,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+	return &model.RenderStatusResponse{
+		JobID:         job.ID,
+		Status:        job.Status,
+		Progress:      job.Progress,
+		CurrentStep:   job.CurrentStep,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		CompletedAt:   job.CompletedAt,
+		RetryCount:    job.RetryCount,
+		ArchiveStatus: string(job.ArchiveStatus),
 	}, nil
 }
 
-func (s *ExportService) exportWAVMock(bitDepth, sampleRate int) (*model.ExportWAVResponse, error) {
-	exportID := uuid.New().String()
+// GetResult returns the result of a completed export job
+func (s *ExportService) GetResult(ctx context.Context, jobID string) (*model.ExportResultResponse, error) {
+	job, err := s.getJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
 
-	return &model.ExportWAVResponse{
-		FileURL:    fmt.Sprintf("https://cdn.makeasinger.com/exports/%s.wav", exportID),
-		Size:       31457280, // ~30MB
-		Format:     "wav",
-		BitDepth:   bitDepth,
-		SampleRate: sampleRate,
-		ExpiresAt:  time.Now().Add(24 * time.Hour),
-	}, nil
+	if job.Status != model.JobStatusSucceeded {
+		return nil, errs.ErrJobNotCompleted
+	}
+
+	var result model.ExportResultResponse
+	if err := json.Unmarshal(job.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return &result, nil
 }
 
-func (s *ExportService) exportStemsMock(req *model.ExportStemsRequest) (*model.ExportStemsResponse, error) {
-	exportID := uuid.New().String()
-	fileCount := len(req.StemURLs)
+func (s *ExportService) saveJob(ctx context.Context, job *model.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(ctx, fmt.Sprintf("job:%s", job.ID), data, 24*time.Hour).Err()
+}
 
-	if req.IncludeVocals && len(req.VocalURLs) > 0 {
-		fileCount += len(req.VocalURLs)
+func (s *ExportService) getJob(ctx context.Context, jobID string) (*model.Job, error) {
+	data, err := s.redis.Get(ctx, fmt.Sprintf("job:%s", jobID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errs.ErrJobNotFound
+		}
+		return nil, err
 	}
-	if req.IncludeMaster && req.MasterURL != "" {
-		fileCount++
+
+	var job model.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
 	}
 
-	return &model.ExportStemsResponse{
-		FileURL:   fmt.Sprintf("https://cdn.makeasinger.com/exports/%s.zip", exportID),
-		Size:      52428800, // ~50MB
-		FileCount: fileCount,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
-	}, nil
+	return &job, nil
+}
+
+func newExportTask(jobID string, payload []byte) (*asynq.Task, error) {
+	taskPayload := map[string]interface{}{
+		"jobId":   jobID,
+		"payload": payload,
+	}
+	data, err := json.Marshal(taskPayload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskTypeExport, data), nil
 }
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/internal/lyrics"
+	"github.com/makeasinger/api/internal/model"
+)
+
+// breakerAgentNames are the chain members backed by a network call, and so
+// the only ones worth circuit-breaking -- cache/filesystem failures aren't
+// the kind of sustained outage a breaker protects against.
+var breakerAgentNames = map[string]bool{"lrclib": true, "groq": true}
+
+// cacheAgentName identifies cacheAgent within a LyricsService chain, so
+// LyricsService can recognize a cache hit (for the X-Cache header) and skip
+// writing an agent's answer back into the cache it came from.
+const cacheAgentName = "cache"
+
+// cacheAgent adapts *LyricsCache into a lyrics.Agent, letting a cache hit
+// short-circuit the chain like any other agent's contribution rather than
+// wrapping the whole service. It never writes to the cache itself --
+// LyricsService does that for whichever later agent answers.
+type cacheAgent struct {
+	cache *LyricsCache
+}
+
+func (a *cacheAgent) Name() string  { return cacheAgentName }
+func (a *cacheAgent) Priority() int { return 0 }
+
+func (a *cacheAgent) Generate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, error) {
+	if resp, ok := a.cache.GetGenerate(ctx, req); ok {
+		return resp, nil
+	}
+	return nil, nil
+}
+
+func (a *cacheAgent) Rewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, error) {
+	if resp, ok := a.cache.GetRewrite(ctx, req); ok {
+		return resp, nil
+	}
+	return nil, nil
+}
+
+// BuildLyricsAgentChain resolves agentNames (config.LyricsConfig.
+// EffectiveAgents) to concrete lyrics.Agent instances, in order. Unknown
+// names are logged and skipped rather than failing startup, since a typo in
+// LYRICS_AGENTS shouldn't take the whole service down. Network-backed agents
+// (lrclib, groq) are wrapped in a lyrics.BreakerAgent using breakerThreshold/
+// breakerCooldownMS, mirroring client.AudioClient/client.SunoClient's
+// per-host breakers, so a downed provider degrades the chain to the next
+// agent instead of eating its timeout on every request.
+func BuildLyricsAgentChain(ctx context.Context, agentNames []string, filesystemRoot, lrclibBaseURL string, groqClient *client.GroqClient, cache *LyricsCache, breakerThreshold, breakerCooldownMS int) []lyrics.Agent {
+	registry := map[string]lyrics.Agent{
+		cacheAgentName: &cacheAgent{cache: cache},
+		"filesystem":   lyrics.NewFilesystemAgent(filesystemRoot),
+		"lrclib":       lyrics.NewLRCLIBAgent(lrclibBaseURL),
+		"groq":         lyrics.NewGroqAgent(groqClient),
+	}
+	cooldown := time.Duration(breakerCooldownMS) * time.Millisecond
+
+	agents := make([]lyrics.Agent, 0, len(agentNames))
+	for _, name := range agentNames {
+		agent, ok := registry[name]
+		if !ok {
+			log.Warn(ctx, "unknown lyrics agent, skipping", "agent", name)
+			continue
+		}
+		if breakerAgentNames[name] {
+			agent = lyrics.NewBreakerAgent(agent, breakerThreshold, cooldown)
+		}
+		agents = append(agents, agent)
+	}
+	return agents
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// lyricsCacheKeyPrefix namespaces every key LyricsCache writes, so Purge can
+// sweep them (or a narrower sub-prefix) without touching unrelated keys.
+const lyricsCacheKeyPrefix = "lyrics:v1:"
+
+// LyricsCachePurger lets an admin endpoint evict cached lyrics entries by
+// key prefix without depending on LyricsCache's concrete type.
+type LyricsCachePurger interface {
+	Purge(ctx context.Context, prefix string) (int, error)
+}
+
+// LyricsCache is a Redis-backed TTL cache in front of lyrics generate/
+// rewrite, keyed by a SHA-256 hash of the normalized request (genre,
+// section type, sorted vibes, and language or currentLyrics/instructions as
+// applicable). It's wired into LyricsService's agent chain as the "cache"
+// agent (see cacheAgent) rather than decorating the whole service, so a
+// cache hit short-circuits the chain and a miss falls through to whichever
+// agent answers next, which then populates the cache on the way back.
+type LyricsCache struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewLyricsCache creates a new lyrics cache.
+func NewLyricsCache(redisClient *redis.Client, ttl time.Duration) *LyricsCache {
+	return &LyricsCache{redis: redisClient, ttl: ttl}
+}
+
+// GetGenerate returns a cached model.LyricsGenerateResponse for req, if any.
+func (c *LyricsCache) GetGenerate(ctx context.Context, req *model.LyricsGenerateRequest) (*model.LyricsGenerateResponse, bool) {
+	var cached model.LyricsGenerateResponse
+	if c.get(ctx, generateCacheKey(req), &cached) {
+		return &cached, true
+	}
+	return nil, false
+}
+
+// StoreGenerate caches resp under req's key.
+func (c *LyricsCache) StoreGenerate(ctx context.Context, req *model.LyricsGenerateRequest, resp *model.LyricsGenerateResponse) {
+	c.set(ctx, generateCacheKey(req), resp)
+}
+
+// GetRewrite returns a cached model.LyricsRewriteResponse for req, if any.
+func (c *LyricsCache) GetRewrite(ctx context.Context, req *model.LyricsRewriteRequest) (*model.LyricsRewriteResponse, bool) {
+	var cached model.LyricsRewriteResponse
+	if c.get(ctx, rewriteCacheKey(req), &cached) {
+		return &cached, true
+	}
+	return nil, false
+}
+
+// StoreRewrite caches resp under req's key.
+func (c *LyricsCache) StoreRewrite(ctx context.Context, req *model.LyricsRewriteRequest, resp *model.LyricsRewriteResponse) {
+	c.set(ctx, rewriteCacheKey(req), resp)
+}
+
+// Purge deletes every cached entry whose key is lyrics:v1:<prefix>* (prefix
+// == "" purges the entire lyrics cache) and reports how many keys were
+// removed.
+func (c *LyricsCache) Purge(ctx context.Context, prefix string) (int, error) {
+	pattern := lyricsCacheKeyPrefix + prefix + "*"
+
+	var purged int
+	iter := c.redis.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		if err := c.redis.Del(ctx, iter.Val()).Err(); err != nil {
+			return purged, fmt.Errorf("failed to delete %s: %w", iter.Val(), err)
+		}
+		purged++
+	}
+	if err := iter.Err(); err != nil {
+		return purged, fmt.Errorf("failed to scan lyrics cache: %w", err)
+	}
+
+	return purged, nil
+}
+
+func (c *LyricsCache) get(ctx context.Context, key string, out interface{}) bool {
+	data, err := c.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+func (c *LyricsCache) set(ctx context.Context, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	_ = c.redis.Set(ctx, key, data, c.ttl).Err()
+}
+
+// generateCacheKey hashes req's normalized genre/sectionType/sorted
+// vibes/language into a lyrics:v1:<hash> cache key.
+func generateCacheKey(req *model.LyricsGenerateRequest) string {
+	return lyricsCacheKeyPrefix + hashCacheFields("generate", string(req.Genre), string(req.SectionType), sortedJoin(req.Vibes), string(req.Language), "", "")
+}
+
+// rewriteCacheKey hashes req's normalized currentLyrics/genre/sectionType/
+// sorted vibes/instructions into a lyrics:v1:<hash> cache key.
+func rewriteCacheKey(req *model.LyricsRewriteRequest) string {
+	return lyricsCacheKeyPrefix + hashCacheFields("rewrite", string(req.Genre), string(req.SectionType), sortedJoin(req.Vibes), "", req.CurrentLyrics, req.Instructions)
+}
+
+// hashCacheFields combines kind (so Generate and Rewrite keys can never
+// collide) with the normalized request fields and returns their SHA-256 hex
+// digest.
+func hashCacheFields(kind string, genre, sectionType, vibes, language, currentLyrics, instructions string) string {
+	h := sha256.New()
+	for _, field := range []string{kind, genre, sectionType, vibes, language, currentLyrics, instructions} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sortedJoin joins vibes in sorted order so cache keys don't depend on the
+// caller's ordering of an otherwise-identical vibe set.
+func sortedJoin(vibes []string) string {
+	sorted := append([]string(nil), vibes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// cacheHitKey is the context key LyricsService uses to report whether a call
+// was served from cache, via WithCacheHitRecorder.
+type cacheHitKey struct{}
+
+// WithCacheHitRecorder returns a context that lets LyricsService report
+// whether its next Generate/Rewrite call was served from cache, via *hit --
+// callers (handler.LyricsHandler) read *hit afterward to set the X-Cache
+// response header.
+func WithCacheHitRecorder(ctx context.Context, hit *bool) context.Context {
+	return context.WithValue(ctx, cacheHitKey{}, hit)
+}
+
+func recordCacheHit(ctx context.Context, hit bool) {
+	if p, ok := ctx.Value(cacheHitKey{}).(*bool); ok {
+		*p = hit
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// BuildLyricsExport renders req.Lines into the requested lyrics file format
+// and returns its content plus the content-type/extension to serve it with.
+func BuildLyricsExport(req *model.LyricsExportRequest) (*model.LyricsExportResponse, error) {
+	offset := time.Duration(req.Offset) * time.Millisecond
+
+	var content string
+	switch req.Format {
+	case "lrc":
+		content = buildLRC(req, offset, false)
+	case "elrc":
+		content = buildLRC(req, offset, true)
+	case "srt":
+		content = buildSRT(req, offset)
+	case "vtt":
+		content = buildVTT(req, offset)
+	default:
+		return nil, fmt.Errorf("unsupported lyrics export format %q", req.Format)
+	}
+
+	if req.Encoding == "utf8bom" {
+		content = "\uFEFF" + content
+	}
+
+	return &model.LyricsExportResponse{
+		Filename: req.ProjectID + "." + req.Format,
+		MimeType: mimeTypeFor(req.Format),
+		Content:  content,
+	}, nil
+}
+
+// buildLRC emits standard LRC ([mm:ss.xx]line text) or, when enhanced is
+// set, per-word enhanced LRC ([mm:ss.xx]<mm:ss.xx>word <mm:ss.xx>word ...).
+// A line with no per-word timing falls back to tagging the whole line.
+func buildLRC(req *model.LyricsExportRequest, offset time.Duration, enhanced bool) string {
+	var b strings.Builder
+
+	writeLRCTag(&b, "ti", req.IncludeTitle, req.Title)
+	writeLRCTag(&b, "ar", req.IncludeArtist, req.Artist)
+	writeLRCTag(&b, "al", req.IncludeAlbum, req.Album)
+
+	for _, line := range req.Lines {
+		b.WriteString("[")
+		b.WriteString(lrcTimestamp(line.StartSeconds, offset))
+		b.WriteString("]")
+
+		if enhanced && len(line.Words) > 0 {
+			for i, word := range line.Words {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				b.WriteString("<")
+				b.WriteString(lrcTimestamp(word.StartSeconds, offset))
+				b.WriteString(">")
+				b.WriteString(word.Text)
+			}
+		} else {
+			b.WriteString(line.Text)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func writeLRCTag(b *strings.Builder, tag string, include bool, value string) {
+	if include && value != "" {
+		fmt.Fprintf(b, "[%s:%s]\n", tag, value)
+	}
+}
+
+// buildSRT emits numbered SubRip cues. Each cue's end time is the next
+// line's start time (or, for the last line, srtLastLineDuration after its
+// own start, since no later line exists to bound it).
+func buildSRT(req *model.LyricsExportRequest, offset time.Duration) string {
+	var b strings.Builder
+
+	for i, line := range req.Lines {
+		start := time.Duration(line.StartSeconds*float64(time.Second)) + offset
+		end := cueEnd(req.Lines, i, offset)
+
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, srtTimestamp(start), srtTimestamp(end), line.Text)
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// buildVTT emits a WebVTT cue file, identical in structure to SRT but with
+// the "WEBVTT" header and a "." millisecond separator instead of ",".
+func buildVTT(req *model.LyricsExportRequest, offset time.Duration) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+
+	for i, line := range req.Lines {
+		start := time.Duration(line.StartSeconds*float64(time.Second)) + offset
+		end := cueEnd(req.Lines, i, offset)
+
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, vttTimestamp(start), vttTimestamp(end), line.Text)
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// srtLastLineDuration bounds the final cue's end time when there's no next
+// line to derive it from.
+const srtLastLineDuration = 4 * time.Second
+
+func cueEnd(lines []model.TimedLyricLine, i int, offset time.Duration) time.Duration {
+	if i+1 < len(lines) {
+		return time.Duration(lines[i+1].StartSeconds*float64(time.Second)) + offset
+	}
+	return time.Duration(lines[i].StartSeconds*float64(time.Second)) + offset + srtLastLineDuration
+}
+
+// lrcTimestamp formats d as LRC's [mm:ss.xx] (centisecond) body.
+func lrcTimestamp(seconds float64, offset time.Duration) string {
+	d := time.Duration(seconds*float64(time.Second)) + offset
+	if d < 0 {
+		d = 0
+	}
+	minutes := int(d / time.Minute)
+	secs := d % time.Minute
+	centis := secs.Milliseconds() / 10 % 100
+	return fmt.Sprintf("%02d:%02d.%02d", minutes, int(secs.Seconds()), centis)
+}
+
+// srtTimestamp formats d as SubRip's hh:mm:ss,mmm.
+func srtTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	hours := int(d / time.Hour)
+	d -= time.Duration(hours) * time.Hour
+	minutes := int(d / time.Minute)
+	d -= time.Duration(minutes) * time.Minute
+	seconds := int(d / time.Second)
+	millis := int((d % time.Second) / time.Millisecond)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, seconds, millis)
+}
+
+// vttTimestamp formats d as WebVTT's hh:mm:ss.mmm.
+func vttTimestamp(d time.Duration) string {
+	return strings.Replace(srtTimestamp(d), ",", ".", 1)
+}
+
+func mimeTypeFor(format string) string {
+	switch format {
+	case "lrc", "elrc":
+		return "text/plain; charset=utf-8"
+	case "srt":
+		return "application/x-subrip"
+	case "vtt":
+		return "text/vtt"
+	default:
+		return "application/octet-stream"
+	}
+}
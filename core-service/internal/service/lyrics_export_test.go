@@ -0,0 +1,96 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+func baseLyricsExportRequest(format string) *model.LyricsExportRequest {
+	return &model.LyricsExportRequest{
+		ProjectID: "proj-1",
+		Format:    format,
+		Lines: []model.TimedLyricLine{
+			{StartSeconds: 0, Text: "First line"},
+			{StartSeconds: 5, Text: "Second line"},
+		},
+	}
+}
+
+// bom is the UTF-8 encoding of U+FEFF, written as an escape rather than a
+// raw literal character -- a raw BOM byte sequence anywhere in a Go source
+// file other than as the file's own leading bytes is a hard parse error
+// ("invalid BOM in the middle of the file"), which is exactly the bug this
+// test exists to catch a regression of.
+const bom = "\uFEFF"
+
+func TestBuildLyricsExport_UTF8BOM(t *testing.T) {
+	req := baseLyricsExportRequest("lrc")
+	req.Encoding = "utf8bom"
+
+	result, err := BuildLyricsExport(req)
+	if err != nil {
+		t.Fatalf("BuildLyricsExport failed: %v", err)
+	}
+
+	if !strings.HasPrefix(result.Content, bom) {
+		t.Fatalf("expected content to start with a BOM, got %q", result.Content)
+	}
+	if strings.HasPrefix(strings.TrimPrefix(result.Content, bom), bom) {
+		t.Fatal("expected exactly one BOM, got it duplicated")
+	}
+}
+
+func TestBuildLyricsExport_NoBOMByDefault(t *testing.T) {
+	req := baseLyricsExportRequest("lrc")
+
+	result, err := BuildLyricsExport(req)
+	if err != nil {
+		t.Fatalf("BuildLyricsExport failed: %v", err)
+	}
+	if strings.HasPrefix(result.Content, bom) {
+		t.Error("expected no BOM when Encoding isn't utf8bom")
+	}
+}
+
+func TestBuildLyricsExport_ELRC(t *testing.T) {
+	req := baseLyricsExportRequest("elrc")
+	req.Lines = []model.TimedLyricLine{
+		{StartSeconds: 0, Text: "fallback text", Words: []model.TimedWord{
+			{StartSeconds: 0, Text: "Hello"},
+			{StartSeconds: 0.5, Text: "world"},
+		}},
+	}
+
+	result, err := BuildLyricsExport(req)
+	if err != nil {
+		t.Fatalf("BuildLyricsExport failed: %v", err)
+	}
+	if !strings.Contains(result.Content, "<00:00.00>Hello <00:00.50>world") {
+		t.Errorf("expected per-word enhanced LRC tags, got %q", result.Content)
+	}
+}
+
+func TestBuildLyricsExport_VTT(t *testing.T) {
+	req := baseLyricsExportRequest("vtt")
+
+	result, err := BuildLyricsExport(req)
+	if err != nil {
+		t.Fatalf("BuildLyricsExport failed: %v", err)
+	}
+	if !strings.HasPrefix(result.Content, "WEBVTT\n\n") {
+		t.Errorf("expected a WEBVTT header, got %q", result.Content)
+	}
+	if !strings.Contains(result.Content, "00:00:00.000 --> 00:00:05.000") {
+		t.Errorf("expected first cue timing with a '.' millisecond separator, got %q", result.Content)
+	}
+}
+
+func TestBuildLyricsExport_UnsupportedFormat(t *testing.T) {
+	req := baseLyricsExportRequest("xyz")
+
+	if _, err := BuildLyricsExport(req); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// LyricsTimedService derives best-effort line timing for plain lyrics that
+// have no real per-line timestamps, so flows that want synced lyrics (see
+// ExportMP3Request.LyricsLRC) can still produce something when the caller
+// doesn't have real timing from the render engine. It holds no dependencies
+// and does its own lookups for nothing -- durationSeconds is supplied by the
+// caller (typically read from a completed master job's
+// MasterResultResponse.Duration) since, like LyricsExportRequest.Lines,
+// there's nowhere server-side to look it up from a project ID alone.
+type LyricsTimedService struct{}
+
+// NewLyricsTimedService creates a new lyrics-timing service.
+func NewLyricsTimedService() *LyricsTimedService {
+	return &LyricsTimedService{}
+}
+
+// DeriveLRC splits plainLyrics into non-empty lines and spreads them evenly
+// across [0, durationSeconds), returning a plain LRC string. This is a
+// best-effort fallback only -- timing is linear and ignores actual syllable
+// or bar boundaries -- so real timed lines (model.LyricsExportRequest.Lines)
+// should be preferred whenever they're available. Returns "" if plainLyrics
+// has no non-empty lines or durationSeconds isn't positive.
+func (s *LyricsTimedService) DeriveLRC(plainLyrics string, durationSeconds float64) string {
+	lines := nonEmptyLines(plainLyrics)
+	if len(lines) == 0 || durationSeconds <= 0 {
+		return ""
+	}
+
+	step := durationSeconds / float64(len(lines))
+	timed := make([]model.TimedLyricLine, len(lines))
+	for i, line := range lines {
+		timed[i] = model.TimedLyricLine{StartSeconds: float64(i) * step, Text: line}
+	}
+
+	resp, err := BuildLyricsExport(&model.LyricsExportRequest{Format: "lrc", Lines: timed})
+	if err != nil {
+		return ""
+	}
+	return resp.Content
+}
+
+// nonEmptyLines splits text on newlines, trims each line, and drops any that
+// are blank.
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			lines = append(lines, trimmed)
+		}
+	}
+	return lines
+}
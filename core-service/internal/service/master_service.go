@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/errs"
+	"github.com/makeasinger/api/internal/model"
+)
+
+// MasterService handles mastering requests. Preview calls the audio
+// microservice synchronously for a quick one-off render that's never
+// tracked as a job; StartFinal/StartBatch queue a MasterWorker job through
+// RenderService's generic job-record schema instead of tracking master
+// jobs in a parallel mechanism of their own -- GetStatus/GetRawResult
+// already work against any job type's record, and ReplayWebhook against any
+// master job's, for exactly that reason.
+type MasterService struct {
+	render      *RenderService
+	audioClient client.AudioProcessor
+}
+
+// NewMasterService creates a mastering service. audioClient may be nil in
+// environments where the Python audio microservice isn't configured --
+// Preview returns errs.ErrAudioServiceUnavailable in that case, the same
+// way MasterWorker falls back to in-process mastering for final jobs
+// (StartFinal/StartBatch queue regardless, since MasterWorker decides the
+// processing path itself once the job is picked up).
+func NewMasterService(renderService *RenderService, audioClient client.AudioProcessor) *MasterService {
+	return &MasterService{render: renderService, audioClient: audioClient}
+}
+
+// Preview masters req synchronously against the audio microservice and
+// returns the rendered file directly, without creating a job record --
+// it's meant for a quick, disposable listen rather than something a client
+// polls for.
+func (s *MasterService) Preview(ctx context.Context, req *model.MasterPreviewRequest) (*model.MasterPreviewResponse, error) {
+	if s.audioClient == nil {
+		return nil, errs.ErrAudioServiceUnavailable
+	}
+
+	previewID := uuid.New().String()
+	masterReq := &client.MasterRequest{
+		StemURLs:    req.StemURLs,
+		MixSettings: buildMixChannels(req.StemURLs, req.MixSnapshot),
+		Profile:     string(req.Profile),
+		OutputKey:   fmt.Sprintf("previews/%s.wav", previewID),
+	}
+
+	resp, err := s.audioClient.Master(ctx, previewID, masterReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MasterPreviewResponse{
+		FileURL:   resp.OutputURL,
+		Duration:  int(resp.Duration),
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+	}, nil
+}
+
+// StartFinal queues a final mastering job for req, the same way StartRender
+// queues a render job -- MasterWorker picks it up off the "master" queue.
+func (s *MasterService) StartFinal(ctx context.Context, req *model.MasterFinalRequest) (*model.MasterFinalResponse, error) {
+	jobID := uuid.New().String()
+
+	if err := s.render.StartMaster(ctx, jobID, masterJobPayloadFrom(req)); err != nil {
+		return nil, err
+	}
+
+	return &model.MasterFinalResponse{
+		JobID:             jobID,
+		Status:            model.JobStatusQueued,
+		EstimatedDuration: 45,
+	}, nil
+}
+
+// StartBatch queues one final mastering job per item, all sharing groupID.
+// Every item has already passed validation by the time MasterHandler.Batch
+// calls this -- a per-item enqueue failure here is an infrastructure
+// problem, not a bad request, so it aborts the whole batch rather than
+// reporting a partial result.
+func (s *MasterService) StartBatch(ctx context.Context, groupID string, items []model.MasterFinalRequest) ([]model.MasterFinalResponse, error) {
+	started := make([]model.MasterFinalResponse, len(items))
+	for i := range items {
+		jobID := uuid.New().String()
+		payload := masterJobPayloadFrom(&items[i])
+
+		if err := s.render.StartMaster(ctx, jobID, payload); err != nil {
+			return nil, fmt.Errorf("failed to start batch item %d: %w", i, err)
+		}
+		started[i] = model.MasterFinalResponse{
+			JobID:             jobID,
+			Status:            model.JobStatusQueued,
+			EstimatedDuration: 45,
+		}
+	}
+	return started, nil
+}
+
+// GetStatus returns a master job's status. It delegates to RenderService,
+// which works against any job type's record, and maps the wider
+// RenderStatusResponse down to the fields master clients expect.
+func (s *MasterService) GetStatus(ctx context.Context, jobID string) (*model.MasterStatusResponse, error) {
+	status, err := s.render.GetStatus(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MasterStatusResponse{
+		JobID:         status.JobID,
+		Status:        status.Status,
+		Progress:      status.Progress,
+		CurrentStep:   status.CurrentStep,
+		ArchiveStatus: status.ArchiveStatus,
+	}, nil
+}
+
+// GetResult returns a completed master job's result.
+func (s *MasterService) GetResult(ctx context.Context, jobID string) (*model.MasterResultResponse, error) {
+	resultBytes, err := s.render.GetRawResult(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result model.MasterResultResponse
+	if err := json.Unmarshal(resultBytes, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+	return &result, nil
+}
+
+// masterJobPayloadFrom copies a validated MasterFinalRequest into the
+// payload MasterWorker/RenderService.fireWebhook read back out of the job
+// record.
+func masterJobPayloadFrom(req *model.MasterFinalRequest) *model.MasterJobPayload {
+	return &model.MasterJobPayload{
+		ProjectID:      req.ProjectID,
+		Profile:        req.Profile,
+		StemURLs:       req.StemURLs,
+		MixSnapshot:    req.MixSnapshot,
+		VocalTakes:     req.VocalTakes,
+		CallbackURL:    req.CallbackURL,
+		CallbackSecret: req.CallbackSecret,
+	}
+}
+
+// buildMixChannels mirrors MasterWorker.buildMixSettings for the preview
+// path, which has no MasterJobPayload to read from.
+func buildMixChannels(stemURLs []string, snapshot model.MixSnapshot) []client.MixChannel {
+	if len(snapshot.Channels) == 0 {
+		channels := make([]client.MixChannel, len(stemURLs))
+		for i, url := range stemURLs {
+			channels[i] = client.MixChannel{StemURL: url, Volume: 1.0}
+		}
+		return channels
+	}
+
+	var channels []client.MixChannel
+	for i, ch := range snapshot.Channels {
+		if i >= len(stemURLs) {
+			break
+		}
+		channels = append(channels, client.MixChannel{
+			StemURL: stemURLs[i],
+			Volume:  dbToLinear(ch.VolumeDb),
+			Mute:    ch.Mute,
+			Solo:    ch.Solo,
+		})
+	}
+	return channels
+}
+
+// dbToLinear converts a dB gain to a linear multiplier, matching
+// worker.dbToLinear -- duplicated rather than imported since internal/worker
+// depends on internal/service, not the other way around.
+func dbToLinear(db float64) float64 {
+	if db <= -60 {
+		return 0.0
+	}
+	return math.Pow(10, db/20)
+}
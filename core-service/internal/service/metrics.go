@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// jobsEnqueuedTotal counts Asynq tasks handed off to the queue, by queue
+// name, so a stalled worker (enqueues climbing with no matching completions)
+// shows up on a dashboard instead of only as a growing Redis backlog.
+var jobsEnqueuedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "jobs_enqueued_total",
+	Help: "Total Asynq tasks enqueued, by queue name.",
+}, []string{"queue"})
+
+var (
+	// uploadSizeBytes tracks how large accepted vocal-take uploads are, so a
+	// shift in typical file size (e.g. toward long-form a cappella takes)
+	// shows up before it exhausts request-size or storage assumptions.
+	uploadSizeBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "upload_size_bytes",
+		Help:    "Size, in bytes, of accepted vocal-take uploads.",
+		Buckets: prometheus.ExponentialBuckets(1<<16, 4, 8), // 64KiB .. 1GiB
+	})
+	// uploadDurationSeconds tracks how long UploadVocal takes end to end
+	// (probe + storage upload), so a slow storage backend shows up as
+	// latency here rather than only as client-side upload timeouts.
+	uploadDurationSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "upload_duration_seconds",
+		Help:    "Duration of UploadService.UploadVocal, probe through storage upload.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
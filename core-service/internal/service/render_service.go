@@ -9,24 +9,42 @@ import (
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/errs"
+	"github.com/makeasinger/api/internal/log"
 	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/webhook"
 )
 
 const (
-	TaskTypeRender = "render:process"
-	TaskTypeMaster = "master:process"
+	TaskTypeRender          = "render:process"
+	TaskTypeMaster          = "master:process"
+	TaskTypeWebhookDelivery = "webhook:deliver"
 )
 
+// webhookMaxRetries bounds how many times Asynq redelivers one webhook
+// callback (exponential backoff via its own default RetryDelayFunc) before
+// archiving it. An archived task is this subsystem's dead-letter log: it's
+// already inspectable via AdminQueueService (GET /api/admin/queues), so
+// there's no separate dead-letter store to build.
+const webhookMaxRetries = 8
+
 // RenderService handles render job management
 type RenderService struct {
-	redis       *redis.Client
-	asynqClient *asynq.Client
+	redis        *redis.Client
+	asynqClient  *asynq.Client
+	leaseTimeout time.Duration
 }
 
-func NewRenderService(redisClient *redis.Client, asynqClient *asynq.Client) *RenderService {
+// NewRenderService creates a render/master job service. leaseTimeout bounds
+// how long a running job may go without a progress report (config.
+// RunnerConfig.LeaseTimeoutSeconds) before GetStatus treats its runner as
+// dead; zero disables the check.
+func NewRenderService(redisClient *redis.Client, asynqClient *asynq.Client, leaseTimeout time.Duration) *RenderService {
 	return &RenderService{
-		redis:       redisClient,
-		asynqClient: asynqClient,
+		redis:        redisClient,
+		asynqClient:  asynqClient,
+		leaseTimeout: leaseTimeout,
 	}
 }
 
@@ -69,15 +87,19 @@ func (s *RenderService) StartRender(ctx context.Context, req *model.RenderStartR
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
-	// Enqueue the task
+	// MaxRetry is higher than the other queues because processWithSuno
+	// resumes from its checkpoint instead of re-running from step 1, so a
+	// retry after a network blip or runner restart is cheap rather than
+	// re-charging the Suno account for work already paid for.
 	_, err = s.asynqClient.Enqueue(task,
 		asynq.Queue("render"),
-		asynq.MaxRetry(3),
+		asynq.MaxRetry(8),
 		asynq.Retention(24*time.Hour),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to enqueue task: %w", err)
 	}
+	jobsEnqueuedTotal.WithLabelValues("render").Inc()
 
 	return &model.RenderStartResponse{
 		JobID:             jobID,
@@ -87,26 +109,123 @@ func (s *RenderService) StartRender(ctx context.Context, req *model.RenderStartR
 	}, nil
 }
 
-// GetStatus returns the current status of a render job
+// StartMaster creates and enqueues a master job using the same job-record
+// schema StartRender uses for render jobs, so GetStatus/GetRawResult/
+// ReplayWebhook/fireWebhook all work against it; only fireWebhook and
+// ReplayWebhook branch on job.Type, to decode its MasterJobPayload.
+// MasterService.StartFinal/StartBatch are the only callers; it takes jobID
+// rather than generating one so a batch can assign every item's ID upfront.
+func (s *RenderService) StartMaster(ctx context.Context, jobID string, payload *model.MasterJobPayload) error {
+	if payload.CallbackURL != "" {
+		if err := webhook.ValidateCallbackURL(ctx, payload.CallbackURL); err != nil {
+			return errs.Validation(fmt.Sprintf("invalid callback url: %v", err), nil)
+		}
+	}
+
+	job := &model.Job{
+		ID:        jobID,
+		Type:      model.JobTypeMaster,
+		Status:    model.JobStatusQueued,
+		Progress:  0,
+		CreatedAt: time.Now(),
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	job.Payload = payloadBytes
+
+	if err := s.saveJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	task, err := newMasterTask(jobID, payloadBytes)
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+
+	// MaxRetry(3) matches ExportService.startExport rather than StartRender's
+	// 8: mastering has no resumable checkpoint the way Suno render steps do,
+	// so a retry re-runs the whole job from scratch and shouldn't be cheap
+	// to hand out.
+	_, err = s.asynqClient.Enqueue(task,
+		asynq.Queue("master"),
+		asynq.MaxRetry(3),
+		asynq.Retention(24*time.Hour),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	jobsEnqueuedTotal.WithLabelValues("master").Inc()
+	return nil
+}
+
+// GetStatus returns the current status of a render job. A Running job whose
+// runner has gone silent past leaseTimeout is failed here, on read, rather
+// than by a background reaper -- consistent with how the rest of this
+// service only touches a job record when something asks about it.
 func (s *RenderService) GetStatus(ctx context.Context, jobID string) (*model.RenderStatusResponse, error) {
 	job, err := s.getJob(ctx, jobID)
 	if err != nil {
 		return nil, err
 	}
 
+	if expired := s.failIfLeaseExpired(ctx, job); expired != nil {
+		job = expired
+	}
+
 	return &model.RenderStatusResponse{
-		JobID:       job.ID,
-		Status:      job.Status,
-		Progress:    job.Progress,
-		CurrentStep: job.CurrentStep,
-		Error:       job.Error,
-		CreatedAt:   job.CreatedAt,
-		StartedAt:   job.StartedAt,
-		CompletedAt: job.CompletedAt,
-		RetryCount:  job.RetryCount,
+		JobID:         job.ID,
+		Status:        job.Status,
+		Progress:      job.Progress,
+		CurrentStep:   job.CurrentStep,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		CompletedAt:   job.CompletedAt,
+		RetryCount:    job.RetryCount,
+		ArchiveStatus: string(job.ArchiveStatus),
 	}, nil
 }
 
+// waitPollInterval is how often WaitForTerminal re-reads a job record while
+// it's still in flight. Jobs report progress on whatever cadence their
+// runner chooses (see UpdateJobProgress), so there's no event to block on
+// here -- this mirrors failIfLeaseExpired's own approach of only touching a
+// job record when something asks about it, just on a tighter loop.
+const waitPollInterval = 500 * time.Millisecond
+
+// WaitForTerminal blocks until jobID reaches a terminal status (succeeded,
+// failed, or canceled) or timeout elapses, then returns its status either
+// way -- the caller tells "finished" from "timed out" apart by checking the
+// returned Status. It's the long-poll counterpart to GetStatus, for a
+// caller that would otherwise have to poll GetStatus itself; like
+// GetStatus and CancelRender, it works against any job type's record, not
+// just render's.
+func (s *RenderService) WaitForTerminal(ctx context.Context, jobID string, timeout time.Duration) (*model.RenderStatusResponse, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := s.GetStatus(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if isTerminalJobStatus(status.Status) || !time.Now().Before(deadline) {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+func isTerminalJobStatus(status model.JobStatus) bool {
+	return status == model.JobStatusSucceeded || status == model.JobStatusFailed || status == model.JobStatusCanceled
+}
+
 // GetResult returns the result of a completed render job
 func (s *RenderService) GetResult(ctx context.Context, jobID string) (*model.RenderResultResponse, error) {
 	job, err := s.getJob(ctx, jobID)
@@ -115,7 +234,7 @@ func (s *RenderService) GetResult(ctx context.Context, jobID string) (*model.Ren
 	}
 
 	if job.Status != model.JobStatusSucceeded {
-		return nil, fmt.Errorf("job not completed")
+		return nil, errs.ErrJobNotCompleted
 	}
 
 	var result model.RenderResultResponse
@@ -126,15 +245,44 @@ func (s *RenderService) GetResult(ctx context.Context, jobID string) (*model.Ren
 	return &result, nil
 }
 
-// CancelRender cancels a render job
+// GetRawResult returns a completed job's result payload as the raw JSON it
+// was saved with, for a caller (MasterService.GetResult) whose result shape
+// isn't RenderResultResponse.
+func (s *RenderService) GetRawResult(ctx context.Context, jobID string) ([]byte, error) {
+	job, err := s.getJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != model.JobStatusSucceeded {
+		return nil, errs.ErrJobNotCompleted
+	}
+
+	return job.Result, nil
+}
+
+// ErrJobStatusBehind is returned by CancelRender when the job has already
+// moved past a cancelable state (e.g. the worker finished it) by the time
+// the cancel request arrives, so the caller can surface the real status
+// instead of silently overwriting a terminal one.
+type ErrJobStatusBehind struct {
+	CurrentStatus model.JobStatus
+}
+
+func (e *ErrJobStatusBehind) Error() string {
+	return fmt.Sprintf("job already %s", e.CurrentStatus)
+}
+
+// CancelRender cancels a render or master job, publishing a cancellation
+// notice the owning worker listens for via WatchForCancellation.
 func (s *RenderService) CancelRender(ctx context.Context, jobID string) (*model.RenderCancelResponse, error) {
 	job, err := s.getJob(ctx, jobID)
 	if err != nil {
 		return nil, err
 	}
 
-	if job.Status == model.JobStatusSucceeded || job.Status == model.JobStatusFailed {
-		return nil, fmt.Errorf("job already completed")
+	if job.Status == model.JobStatusSucceeded || job.Status == model.JobStatusFailed || job.Status == model.JobStatusCanceled {
+		return nil, &ErrJobStatusBehind{CurrentStatus: job.Status}
 	}
 
 	job.Status = model.JobStatusCanceled
@@ -145,6 +293,19 @@ func (s *RenderService) CancelRender(ctx context.Context, jobID string) (*model.
 		return nil, err
 	}
 
+	// The pub/sub publish below only reaches a worker that's subscribed at
+	// this exact moment; a worker mid-retry between checkpoint steps isn't
+	// listening yet. The flag is durable, so processWithSuno's between-step
+	// checkCancelled catches the cancellation even if it was published into an empty
+	// channel.
+	if err := s.redis.Set(ctx, cancelledFlagKey(jobID), "1", 24*time.Hour).Err(); err != nil {
+		log.Error(log.WithFields(ctx, "jobId", jobID), "failed to set cancellation flag", "err", err)
+	}
+
+	if err := s.redis.Publish(ctx, cancelChannel(jobID), "cancel").Err(); err != nil {
+		log.Error(log.WithFields(ctx, "jobId", jobID), "failed to publish cancellation", "err", err)
+	}
+
 	return &model.RenderCancelResponse{
 		Success: true,
 		JobID:   jobID,
@@ -152,6 +313,38 @@ func (s *RenderService) CancelRender(ctx context.Context, jobID string) (*model.
 	}, nil
 }
 
+// WatchForCancellation subscribes to jobID's cancel channel and returns a
+// context that is canceled either when parentCtx is done or when a
+// cancellation notice arrives, whichever happens first. Callers must invoke
+// the returned cancel func once the job finishes to release the
+// subscription.
+func (s *RenderService) WatchForCancellation(parentCtx context.Context, jobID string) (context.Context, context.CancelFunc) {
+	return WatchForCancellation(parentCtx, s.redis, jobID)
+}
+
+// WatchForCancellation is the package-level form used by workers that keep
+// their own Redis handle (e.g. MasterWorker) rather than going through
+// RenderService.
+func WatchForCancellation(parentCtx context.Context, redisClient *redis.Client, jobID string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parentCtx)
+
+	sub := redisClient.Subscribe(parentCtx, cancelChannel(jobID))
+	go func() {
+		defer sub.Close()
+		select {
+		case <-sub.Channel():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+func cancelChannel(jobID string) string {
+	return fmt.Sprintf("job:%s:cancel", jobID)
+}
+
 // UpdateJobProgress updates job progress (called by worker)
 func (s *RenderService) UpdateJobProgress(ctx context.Context, jobID string, progress int, step string) error {
 	job, err := s.getJob(ctx, jobID)
@@ -161,16 +354,42 @@ func (s *RenderService) UpdateJobProgress(ctx context.Context, jobID string, pro
 
 	job.Progress = progress
 	job.CurrentStep = step
+	now := time.Now()
+	job.LastHeartbeatAt = &now
 
 	if job.Status == model.JobStatusQueued {
 		job.Status = model.JobStatusRunning
-		now := time.Now()
 		job.StartedAt = &now
 	}
 
 	return s.saveJob(ctx, job)
 }
 
+// failIfLeaseExpired fails job and returns the updated record if it's
+// Running and hasn't heartbeated within s.leaseTimeout -- its runner most
+// likely crashed or was evicted mid-job -- otherwise it returns nil and
+// leaves job untouched.
+func (s *RenderService) failIfLeaseExpired(ctx context.Context, job *model.Job) *model.Job {
+	if s.leaseTimeout <= 0 || job.Status != model.JobStatusRunning || job.LastHeartbeatAt == nil {
+		return nil
+	}
+	if time.Since(*job.LastHeartbeatAt) < s.leaseTimeout {
+		return nil
+	}
+
+	errMsg := "runner lease expired: no progress reported within the lease timeout"
+	job.Status = model.JobStatusFailed
+	job.Error = &errMsg
+	now := time.Now()
+	job.CompletedAt = &now
+
+	if err := s.saveJob(ctx, job); err != nil {
+		log.Error(log.WithFields(ctx, "jobId", job.ID), "failed to fail lease-expired job", "err", err)
+		return nil
+	}
+	return job
+}
+
 // CompleteJob marks job as completed (called by worker)
 func (s *RenderService) CompleteJob(ctx context.Context, jobID string, result interface{}) error {
 	job, err := s.getJob(ctx, jobID)
@@ -189,7 +408,11 @@ func (s *RenderService) CompleteJob(ctx context.Context, jobID string, result in
 	now := time.Now()
 	job.CompletedAt = &now
 
-	return s.saveJob(ctx, job)
+	if err := s.saveJob(ctx, job); err != nil {
+		return err
+	}
+	s.fireWebhook(ctx, job, "done")
+	return s.clearCheckpoint(ctx, jobID)
 }
 
 // FailJob marks job as failed (called by worker)
@@ -204,7 +427,148 @@ func (s *RenderService) FailJob(ctx context.Context, jobID string, errMsg string
 	now := time.Now()
 	job.CompletedAt = &now
 
-	return s.saveJob(ctx, job)
+	if err := s.saveJob(ctx, job); err != nil {
+		return err
+	}
+	s.fireWebhook(ctx, job, "failed")
+	return s.clearCheckpoint(ctx, jobID)
+}
+
+// FailJobStructured marks job as failed with per-item failure detail, e.g. a
+// multi-stem master where some stems mastered successfully and others did
+// not. The top-level Error field is kept populated with the structured
+// message so status responses that only look at Error still read sensibly.
+func (s *RenderService) FailJobStructured(ctx context.Context, jobID string, structErr *model.JobStructuredError) error {
+	job, err := s.getJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	job.Status = model.JobStatusFailed
+	job.Error = &structErr.Message
+	job.StructuredError = structErr
+	now := time.Now()
+	job.CompletedAt = &now
+
+	if err := s.saveJob(ctx, job); err != nil {
+		return err
+	}
+	s.fireWebhook(ctx, job, "failed")
+	return s.clearCheckpoint(ctx, jobID)
+}
+
+// fireWebhook enqueues a TaskTypeWebhookDelivery task if job is a master job
+// carrying a CallbackURL (see MasterJobPayload) -- render and export jobs
+// don't carry callback fields yet, so this is a no-op for them. Enqueue
+// failures are logged rather than returned: the job itself already reached
+// its terminal status and saved successfully, and a client that cares about
+// missed callbacks can still poll GetStatus or call ReplayWebhook.
+func (s *RenderService) fireWebhook(ctx context.Context, job *model.Job, event string) {
+	if job.Type != model.JobTypeMaster {
+		return
+	}
+
+	var payload model.MasterJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		log.Error(log.WithFields(ctx, "jobId", job.ID), "failed to decode master payload for webhook", "err", err)
+		return
+	}
+	if payload.CallbackURL == "" {
+		return
+	}
+
+	if err := s.enqueueWebhookDelivery(ctx, job, event, payload.CallbackURL, payload.CallbackSecret); err != nil {
+		log.Error(log.WithFields(ctx, "jobId", job.ID), "failed to enqueue webhook delivery", "err", err)
+	}
+}
+
+// enqueueWebhookDelivery builds and enqueues the TaskTypeWebhookDelivery
+// task for one callback. It's split out from fireWebhook so ReplayWebhook
+// can reuse it without re-deciding whether the job is eligible.
+func (s *RenderService) enqueueWebhookDelivery(ctx context.Context, job *model.Job, event, url, secret string) error {
+	body, err := json.Marshal(struct {
+		JobID     string          `json:"jobId"`
+		Event     string          `json:"event"`
+		Status    model.JobStatus `json:"status"`
+		Error     *string         `json:"error,omitempty"`
+		Timestamp time.Time       `json:"timestamp"`
+	}{
+		JobID:     job.ID,
+		Event:     event,
+		Status:    job.Status,
+		Error:     job.Error,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook body: %w", err)
+	}
+
+	taskPayload, err := json.Marshal(model.WebhookDeliveryPayload{
+		JobID:  job.ID,
+		Event:  event,
+		Status: job.Status,
+		URL:    url,
+		Secret: secret,
+		Body:   body,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook task payload: %w", err)
+	}
+
+	_, err = s.asynqClient.Enqueue(
+		asynq.NewTask(TaskTypeWebhookDelivery, taskPayload),
+		asynq.Queue("webhook"),
+		asynq.MaxRetry(webhookMaxRetries),
+		asynq.Retention(24*time.Hour),
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue webhook task: %w", err)
+	}
+	jobsEnqueuedTotal.WithLabelValues("webhook").Inc()
+	return nil
+}
+
+// ReplayWebhook re-fires jobID's callback on demand (MasterHandler.ReplayWebhook,
+// an admin-only endpoint): useful when a receiver's endpoint was down for the
+// original delivery window and its retries have already been exhausted and
+// archived. It requires the job to be a terminal master job with a
+// CallbackURL -- there's nothing to resend otherwise.
+func (s *RenderService) ReplayWebhook(ctx context.Context, jobID string) error {
+	job, err := s.getJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.Type != model.JobTypeMaster {
+		return errs.ErrJobNotFound
+	}
+	if !isTerminalJobStatus(job.Status) {
+		return errs.ErrJobNotCompleted
+	}
+
+	var payload model.MasterJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode master payload: %w", err)
+	}
+	if payload.CallbackURL == "" {
+		return errs.ErrWebhookNotConfigured
+	}
+
+	event := "done"
+	if job.Status == model.JobStatusFailed {
+		event = "failed"
+	}
+	return s.enqueueWebhookDelivery(ctx, job, event, payload.CallbackURL, payload.CallbackSecret)
+}
+
+// clearCheckpoint removes jobID's resume checkpoint once it reaches a
+// terminal state. Errors are logged rather than returned: the job record
+// itself already saved successfully, and a stray checkpoint is harmless
+// since it's keyed off this jobID and a new job never reuses an old ID.
+func (s *RenderService) clearCheckpoint(ctx context.Context, jobID string) error {
+	if err := ClearCheckpoint(ctx, s.redis, jobID); err != nil {
+		log.Error(log.WithFields(ctx, "jobId", jobID), "failed to clear checkpoint", "err", err)
+	}
+	return nil
 }
 
 // Helper methods
@@ -221,7 +585,7 @@ func (s *RenderService) getJob(ctx context.Context, jobID string) (*model.Job, e
 	data, err := s.redis.Get(ctx, fmt.Sprintf("job:%s", jobID)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, fmt.Errorf("job not found")
+			return nil, errs.ErrJobNotFound
 		}
 		return nil, err
 	}
@@ -245,3 +609,15 @@ func newRenderTask(jobID string, payload []byte) (*asynq.Task, error) {
 	}
 	return asynq.NewTask(TaskTypeRender, data), nil
 }
+
+func newMasterTask(jobID string, payload []byte) (*asynq.Task, error) {
+	taskPayload := map[string]interface{}{
+		"jobId":   jobID,
+		"payload": payload,
+	}
+	data, err := json.Marshal(taskPayload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TaskTypeMaster, data), nil
+}
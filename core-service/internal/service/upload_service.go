@@ -1,62 +1,287 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/audio/probe"
 	"github.com/makeasinger/api/internal/client"
 	"github.com/makeasinger/api/internal/model"
 )
 
 // FileUploader defines the interface for file upload operations
 type FileUploader interface {
-	UploadVocal(ctx context.Context, projectID, sectionID, takeName string, file io.Reader, fileSize int64) (*model.UploadVocalResponse, error)
+	UploadVocal(ctx context.Context, projectID, sectionID, takeName string, file io.Reader, fileSize int64, probed *probe.Result) (*model.UploadVocalResponse, error)
+	UploadVocalsBatch(ctx context.Context, zr *zip.Reader) (*model.UploadBatchResponse, error)
 	DeleteVocal(ctx context.Context, takeID string) error
 }
 
+const (
+	// batchManifestName is the required manifest entry describing how each
+	// other entry in the archive maps to a (project, section, take).
+	batchManifestName = "manifest.json"
+	// batchUploadWorkers bounds how many entries upload to R2 concurrently,
+	// since PutObject is I/O-bound and a 50-entry batch shouldn't open 50
+	// simultaneous connections.
+	batchUploadWorkers = 4
+	// vocalPresignUploadTTL is how long a presigned direct-upload URL stays
+	// valid, long enough for a slow client to finish sending a large take
+	// without being forced to request a fresh URL mid-upload. The presign
+	// session recording the declared size/type lives in Redis for the same
+	// duration, since it's useless once the URL it backs has expired.
+	vocalPresignUploadTTL = 15 * time.Minute
+
+	// maxPresignUploadSize bounds a direct-to-storage upload. It's larger
+	// than maxUploadSize (the proxied /vocal path's limit) precisely because
+	// the point of presigning is to let bigger takes bypass Fiber's body
+	// limit -- but it still needs a ceiling so a client can't reserve an
+	// unbounded object against the bucket.
+	maxPresignUploadSize = 1024 * 1024 * 1024 // 1GB
+)
+
+// allowedPresignContentTypes is the MIME whitelist for a presigned direct
+// upload, mirroring the codecs probe.Probe recognizes on the proxied /vocal
+// path -- a presigned upload never passes through this process to be
+// probed, so the whitelist is the only check available before Complete.
+var allowedPresignContentTypes = map[string]bool{
+	"audio/wav":  true,
+	"audio/mpeg": true,
+	"audio/mp4":  true,
+}
+
+// ErrPresignContentTypeUnsupported is returned when PresignVocalUpload is
+// asked to presign a Content-Type outside allowedPresignContentTypes.
+var ErrPresignContentTypeUnsupported = errors.New("unsupported content type for direct upload")
+
+// ErrPresignUploadTooLarge is returned when PresignVocalUpload is asked to
+// presign a ContentLength over maxPresignUploadSize.
+var ErrPresignUploadTooLarge = errors.New("content length exceeds the direct-upload size limit")
+
+// ErrPresignSessionNotFound is returned when CompletePresignedVocalUpload is
+// given an id with no presign session, either because it never existed or
+// its TTL (the same as the presigned URL's own) expired.
+var ErrPresignSessionNotFound = errors.New("presigned upload not found")
+
+// ErrPresignUploadIncomplete is returned when CompletePresignedVocalUpload's
+// HeadObject can't find the object yet, i.e. the client hasn't finished (or
+// hasn't started) PUTting to the presigned URL.
+var ErrPresignUploadIncomplete = errors.New("presigned upload has not landed in storage yet")
+
+// ErrPresignSizeMismatch is returned when the object HeadObject finds at
+// Complete doesn't match the size declared at presign time.
+var ErrPresignSizeMismatch = errors.New("uploaded object size does not match the declared content length")
+
+// presignSession is the Redis-persisted record of one outstanding presigned
+// upload, keyed by take ID, so Complete can be served by any API replica
+// and knows what to check the finished object against.
+type presignSession struct {
+	Key           string `json:"key"`
+	ContentType   string `json:"contentType"`
+	ContentLength int64  `json:"contentLength"`
+	ContentSHA256 string `json:"contentSha256,omitempty"`
+}
+
+func presignSessionKey(takeID string) string {
+	return fmt.Sprintf("upload:presign:%s", takeID)
+}
+
+// batchManifestEntry is one value in manifest.json, keyed by the archive
+// entry's filename.
+type batchManifestEntry struct {
+	ProjectID string `json:"projectId"`
+	SectionID string `json:"sectionId"`
+	TakeName  string `json:"takeName"`
+}
+
+// vocalTakeNamespace namespaces the deterministic take IDs batch uploads are
+// derived from (see deterministicTakeID).
+var vocalTakeNamespace = uuid.MustParse("2f6e9f0a-9b8f-4b0a-8e96-7e8f0a6b1c2d")
+
+// fileExtension and contentType return the storage key extension and
+// Content-Type to use for a probed codec, rather than always writing
+// ".wav"/"audio/wav" regardless of what was actually uploaded.
+func fileExtension(codec probe.Codec) string {
+	switch codec {
+	case probe.CodecMP3:
+		return "mp3"
+	case probe.CodecALAC, probe.CodecAAC:
+		return "m4a"
+	default:
+		return "wav"
+	}
+}
+
+func contentType(codec probe.Codec) string {
+	switch codec {
+	case probe.CodecMP3:
+		return "audio/mpeg"
+	case probe.CodecALAC, probe.CodecAAC:
+		return "audio/mp4"
+	default:
+		return "audio/wav"
+	}
+}
+
 // UploadService handles file uploads to R2 storage
 type UploadService struct {
+	redis    *redis.Client
 	r2Client client.StorageClient
 }
 
 // NewUploadService creates a new upload service with R2 client
-func NewUploadService(r2Client client.StorageClient) *UploadService {
+func NewUploadService(redisClient *redis.Client, r2Client client.StorageClient) *UploadService {
 	return &UploadService{
+		redis:    redisClient,
 		r2Client: r2Client,
 	}
 }
 
-// UploadVocal uploads a vocal recording to R2 storage
-func (s *UploadService) UploadVocal(ctx context.Context, projectID, sectionID, takeName string, file io.Reader, fileSize int64) (*model.UploadVocalResponse, error) {
+// UploadVocal uploads a vocal recording to R2 storage. probed is the result
+// of probing the file's actual container/codec (see internal/audio/probe),
+// which drives the storage key extension, Content-Type, and the metadata
+// returned to the client.
+func (s *UploadService) UploadVocal(ctx context.Context, projectID, sectionID, takeName string, file io.Reader, fileSize int64, probed *probe.Result) (*model.UploadVocalResponse, error) {
+	start := time.Now()
+	defer func() { uploadDurationSeconds.Observe(time.Since(start).Seconds()) }()
+
 	takeID := uuid.New().String()
 
 	// Generate storage key
-	key := fmt.Sprintf("vocals/%s/%s/%s.wav", projectID, sectionID, takeID)
+	key := fmt.Sprintf("vocals/%s/%s/%s.%s", projectID, sectionID, takeID, fileExtension(probed.Codec))
 
 	// Use mock response if client is not configured
 	if s.r2Client == nil {
-		return s.uploadMock(takeID, projectID)
+		return s.uploadMock(takeID, projectID, probed)
 	}
 
 	// Upload to R2
-	fileURL, err := s.r2Client.Upload(ctx, key, file, "audio/wav")
+	fileURL, err := s.r2Client.Upload(ctx, key, file, contentType(probed.Codec))
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload vocal: %w", err)
 	}
+	uploadSizeBytes.Observe(float64(fileSize))
 
 	return &model.UploadVocalResponse{
 		ID:         takeID,
 		FileURL:    fileURL,
-		Duration:   0, // Would need audio analysis to get actual duration
-		SampleRate: 44100,
-		Channels:   1,
+		Codec:      string(probed.Codec),
+		Duration:   probed.Duration,
+		SampleRate: probed.SampleRate,
+		Channels:   probed.Channels,
 		CreatedAt:  time.Now(),
 	}, nil
 }
 
+// extensionForContentType maps a client-declared upload Content-Type to a
+// storage key extension. Unlike fileExtension, this trusts the caller's
+// declared type rather than a probed codec, since a presigned upload never
+// passes through this process for probing.
+func extensionForContentType(contentType string) string {
+	switch contentType {
+	case "audio/mpeg":
+		return "mp3"
+	case "audio/mp4":
+		return "m4a"
+	default:
+		return "wav"
+	}
+}
+
+// PresignVocalUpload returns a presigned PUT URL the client can upload a
+// vocal take to directly, bypassing the API's Fiber body-size limit
+// entirely. Unlike UploadVocal, the file never passes through this process,
+// so its codec/duration/sample rate can't be probed here -- the response
+// carries only the storage key, not a full UploadVocalResponse. The caller
+// must then hit CompletePresignedVocalUpload with the returned ID once the
+// PUT finishes, which HeadObjects the key to confirm it actually landed
+// before the take is considered uploaded.
+func (s *UploadService) PresignVocalUpload(ctx context.Context, req *model.PresignVocalUploadRequest) (*model.PresignVocalUploadResponse, error) {
+	if s.r2Client == nil {
+		return nil, fmt.Errorf("object storage is not configured")
+	}
+	if !allowedPresignContentTypes[req.ContentType] {
+		return nil, ErrPresignContentTypeUnsupported
+	}
+	if req.ContentLength > maxPresignUploadSize {
+		return nil, ErrPresignUploadTooLarge
+	}
+
+	takeID := uuid.New().String()
+	key := fmt.Sprintf("vocals/%s/%s/%s.%s", req.ProjectID, req.SectionID, takeID, extensionForContentType(req.ContentType))
+
+	uploadURL, err := s.r2Client.PresignPutURL(ctx, key, req.ContentType, vocalPresignUploadTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign vocal upload: %w", err)
+	}
+
+	session := presignSession{Key: key, ContentType: req.ContentType, ContentLength: req.ContentLength, ContentSHA256: req.ContentSHA256}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal presign session: %w", err)
+	}
+	if err := s.redis.Set(ctx, presignSessionKey(takeID), data, vocalPresignUploadTTL).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save presign session: %w", err)
+	}
+
+	return &model.PresignVocalUploadResponse{
+		ID:        takeID,
+		Key:       key,
+		UploadURL: uploadURL,
+		ExpiresAt: time.Now().Add(vocalPresignUploadTTL),
+	}, nil
+}
+
+// CompletePresignedVocalUpload confirms a presigned direct upload actually
+// landed in storage before the take is considered uploaded: it HeadObjects
+// the key the matching PresignVocalUpload call reserved and checks the
+// returned size against what was declared at presign time. The ETag is
+// neither returned nor checked against ContentSHA256 -- S3-compatible
+// ETags are an MD5 of the object for a single-part PUT but something
+// provider- and part-layout-specific for anything else, so it isn't a
+// reliable stand-in for a SHA-256 digest.
+func (s *UploadService) CompletePresignedVocalUpload(ctx context.Context, takeID string) (*model.UploadVocalResponse, error) {
+	data, err := s.redis.Get(ctx, presignSessionKey(takeID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrPresignSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load presign session: %w", err)
+	}
+	var session presignSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal presign session: %w", err)
+	}
+
+	info, err := s.r2Client.StatObject(ctx, session.Key)
+	if errors.Is(err, client.ErrObjectNotFound) {
+		return nil, ErrPresignUploadIncomplete
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm presigned upload: %w", err)
+	}
+	if info.Size != session.ContentLength {
+		return nil, ErrPresignSizeMismatch
+	}
+
+	s.redis.Del(ctx, presignSessionKey(takeID))
+
+	return &model.UploadVocalResponse{
+		ID:        takeID,
+		FileURL:   s.r2Client.GetPublicURL(session.Key),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
 // DeleteVocal deletes a vocal recording from R2 storage
 func (s *UploadService) DeleteVocal(ctx context.Context, takeID string) error {
 	if s.r2Client == nil {
@@ -89,13 +314,147 @@ func (s *UploadService) GetSignedURL(ctx context.Context, key string, expiry tim
 }
 
 // Mock implementation for development/testing
-func (s *UploadService) uploadMock(takeID, projectID string) (*model.UploadVocalResponse, error) {
+func (s *UploadService) uploadMock(takeID, projectID string, probed *probe.Result) (*model.UploadVocalResponse, error) {
 	return &model.UploadVocalResponse{
 		ID:         takeID,
-		FileURL:    fmt.Sprintf("https://cdn.makeasinger.com/vocals/%s/%s.wav", projectID, takeID),
-		Duration:   32.5,
-		SampleRate: 44100,
-		Channels:   1,
+		FileURL:    fmt.Sprintf("https://cdn.makeasinger.com/vocals/%s/%s.%s", projectID, takeID, fileExtension(probed.Codec)),
+		Codec:      string(probed.Codec),
+		Duration:   probed.Duration,
+		SampleRate: probed.SampleRate,
+		Channels:   probed.Channels,
 		CreatedAt:  time.Now(),
 	}, nil
 }
+
+// deterministicTakeID derives a stable take ID from the manifest entry
+// instead of a random one, so retrying a failed batch upload (e.g. after a
+// client-side timeout) overwrites the same storage key rather than leaking
+// an orphaned duplicate.
+func deterministicTakeID(entry batchManifestEntry) string {
+	return uuid.NewSHA1(vocalTakeNamespace, []byte(entry.ProjectID+"/"+entry.SectionID+"/"+entry.TakeName)).String()
+}
+
+// UploadVocalsBatch uploads every audio entry in zr, as described by its
+// required manifest.json, concurrently bounded by batchUploadWorkers.
+// Per-entry failures (a bad probe, a missing manifest mapping, an R2 error)
+// are reported in the corresponding UploadBatchEntryResult rather than
+// failing the whole request, since one bad take in a 50-file archive
+// shouldn't force the client to re-upload the other 49.
+func (s *UploadService) UploadVocalsBatch(ctx context.Context, zr *zip.Reader) (*model.UploadBatchResponse, error) {
+	manifest, manifestErr := readBatchManifest(zr)
+
+	var files []*zip.File
+	for _, f := range zr.File {
+		if f.Name == batchManifestName || f.FileInfo().IsDir() {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	entries := make([]model.UploadBatchEntryResult, len(files))
+	sem := make(chan struct{}, batchUploadWorkers)
+	var wg sync.WaitGroup
+
+	for i, f := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, f *zip.File) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entries[i] = s.uploadBatchEntry(ctx, f, manifest, manifestErr)
+		}(i, f)
+	}
+	wg.Wait()
+
+	return &model.UploadBatchResponse{Entries: entries}, nil
+}
+
+// readBatchManifest parses manifest.json into a lookup by archive filename.
+// The error is returned rather than bailing out immediately so a missing or
+// malformed manifest still produces one failed entry per file instead of
+// rejecting the whole batch outright.
+func readBatchManifest(zr *zip.Reader) (map[string]batchManifestEntry, error) {
+	for _, f := range zr.File {
+		if f.Name != batchManifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", batchManifestName, err)
+		}
+		defer rc.Close()
+
+		var manifest map[string]batchManifestEntry
+		if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", batchManifestName, err)
+		}
+		return manifest, nil
+	}
+	return nil, fmt.Errorf("%s not found in archive", batchManifestName)
+}
+
+// uploadBatchEntry probes and uploads a single archive entry, translating
+// any failure into a result the client can match back to its filename.
+func (s *UploadService) uploadBatchEntry(ctx context.Context, f *zip.File, manifest map[string]batchManifestEntry, manifestErr error) model.UploadBatchEntryResult {
+	result := model.UploadBatchEntryResult{Filename: f.Name}
+
+	if manifestErr != nil {
+		result.Error = manifestErr.Error()
+		return result
+	}
+
+	entry, ok := manifest[f.Name]
+	if !ok {
+		result.Error = "no manifest entry for this file"
+		return result
+	}
+	result.TakeName = entry.TakeName
+
+	rc, err := f.Open()
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to open archive entry: %v", err)
+		return result
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read archive entry: %v", err)
+		return result
+	}
+	fileSize := int64(len(data))
+
+	probed, err := probe.Probe(bytes.NewReader(data), fileSize)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to probe file: %v", err)
+		return result
+	}
+
+	takeID := deterministicTakeID(entry)
+	key := fmt.Sprintf("vocals/%s/%s/%s.%s", entry.ProjectID, entry.SectionID, takeID, fileExtension(probed.Codec))
+
+	if s.r2Client == nil {
+		mock, _ := s.uploadMock(takeID, entry.ProjectID, probed)
+		result.Success = true
+		result.Result = mock
+		return result
+	}
+
+	fileURL, err := s.r2Client.Upload(ctx, key, bytes.NewReader(data), contentType(probed.Codec))
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to upload vocal: %v", err)
+		return result
+	}
+
+	result.Success = true
+	result.Result = &model.UploadVocalResponse{
+		ID:         takeID,
+		FileURL:    fileURL,
+		Codec:      string(probed.Codec),
+		Duration:   probed.Duration,
+		SampleRate: probed.SampleRate,
+		Channels:   probed.Channels,
+		CreatedAt:  time.Now(),
+	}
+	return result
+}
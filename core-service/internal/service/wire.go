@@ -0,0 +1,20 @@
+package service
+
+import "github.com/google/wire"
+
+// ProviderSet wires the services that only depend on other wired
+// components. NewLyricsCache and NewLyricsService are excluded:
+// LyricsService is built from an ordered lyrics.Agent chain
+// (BuildLyricsAgentChain) whose membership depends on
+// config.LyricsConfig.EffectiveAgents, which Wire can't resolve as a
+// provider graph, so that construction stays explicit in wire_gen.go.
+var ProviderSet = wire.NewSet(
+	NewLyricsTimedService,
+	NewRenderService,
+	NewMasterService,
+	NewExportService,
+	NewUploadService,
+	NewChunkedUploadService,
+	NewArchiver,
+	NewAdminQueueService,
+)
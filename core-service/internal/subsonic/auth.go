@@ -0,0 +1,39 @@
+package subsonic
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/auth"
+)
+
+// Authenticate validates the Subsonic `u`/`p` query parameters against the
+// same JWT secret the rest of the API uses. Subsonic's `p` is meant to hold
+// a (possibly hex-encoded) password, and `t`/`s` a salted hash of one; this
+// backend has no password to check either against, since auth is delegated
+// to JWT/Zitadel (see package doc). Clients must instead be configured to
+// send their JWT as `p`. Unlike the rest of the API, failures here must
+// come back as a Subsonic <error> envelope (not response.Unauthorized),
+// since that's the only failure shape Subsonic clients understand.
+func Authenticate(jwtSecret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Query("u") == "" {
+			return fail(c, errMissingParam, "Required parameter 'u' is missing")
+		}
+
+		token := c.Query("p")
+		if token == "" {
+			return fail(c, errMissingParam, "Required parameter 'p' is missing")
+		}
+		if c.Query("t") != "" || c.Query("s") != "" {
+			return fail(c, errBadCredentials, "Token authentication (t/s) is not supported; pass a JWT as 'p' instead")
+		}
+
+		claims, err := auth.ValidateLegacyToken(token, jwtSecret)
+		if err != nil {
+			return fail(c, errBadCredentials, "Wrong username or password")
+		}
+
+		c.Locals("subsonicClaims", claims)
+		return c.Next()
+	}
+}
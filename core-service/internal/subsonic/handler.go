@@ -0,0 +1,197 @@
+package subsonic
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/service"
+)
+
+// exportKindSuffix maps an export job's format to the file suffix a Subsonic
+// client needs to play it back correctly (suffixContentType turns the suffix
+// into a MIME type).
+var exportKindSuffix = map[string]string{
+	string(model.ExportKindMP3):  "mp3",
+	string(model.ExportKindWAV):  "wav",
+	string(model.ExportKindALAC): "m4a",
+	string(model.ExportKindFLAC): "flac",
+}
+
+// Handler serves the Subsonic REST subset described in the package doc,
+// backed by the same ExportService the /api/export routes use — a
+// completed export job's jobId doubles as this package's Subsonic song id.
+type Handler struct {
+	exportService *service.ExportService
+}
+
+// NewHandler creates a Handler over the export jobs already queued/served
+// through the ordinary /api/export routes.
+func NewHandler(exportService *service.ExportService) *Handler {
+	return &Handler{exportService: exportService}
+}
+
+// Ping handles GET/POST /rest/ping.view — a bare liveness/auth check every
+// Subsonic client makes first when a server is added.
+func (h *Handler) Ping(c *fiber.Ctx) error {
+	return ok(c, func(e *envelope) {})
+}
+
+// GetLicense handles /rest/getLicense.view. This backend has no license
+// concept of its own, so it always reports a permanently valid license —
+// third-party servers like Navidrome do the same when they don't implement
+// Subsonic Premium-style licensing.
+func (h *Handler) GetLicense(c *fiber.Ctx) error {
+	return ok(c, func(e *envelope) {
+		e.License = &license{Valid: true}
+	})
+}
+
+// GetMusicFolders handles /rest/getMusicFolders.view. There's exactly one
+// "folder": the caller's own export jobs.
+func (h *Handler) GetMusicFolders(c *fiber.Ctx) error {
+	return ok(c, func(e *envelope) {
+		e.MusicFolders = &musicFolders{Folder: []musicFolder{{ID: 1, Name: "Renders"}}}
+	})
+}
+
+// GetIndexes handles /rest/getIndexes.view. Always empty: see the package
+// doc comment on why there's no artist/album catalog to index.
+func (h *Handler) GetIndexes(c *fiber.Ctx) error {
+	return ok(c, func(e *envelope) {
+		e.Indexes = &indexes{LastModified: time.Now().Unix(), Index: []string{}}
+	})
+}
+
+// GetAlbumList2 handles /rest/getAlbumList2.view. Always empty, for the same
+// reason as GetIndexes — browsing a "list all albums" view isn't something
+// this backend's job-record storage can answer.
+func (h *Handler) GetAlbumList2(c *fiber.Ctx) error {
+	return ok(c, func(e *envelope) {
+		e.AlbumList2 = &albumList2{Album: []albumSummary{}}
+	})
+}
+
+// GetAlbum handles /rest/getAlbum.view?id=. The only "album" this backend
+// can answer for is a single completed export job, presented as a one-song
+// album so clients that insist on album-level browsing still have
+// somewhere to hang the song.
+func (h *Handler) GetAlbum(c *fiber.Ctx) error {
+	jobID := c.Query("id")
+	if jobID == "" {
+		return fail(c, errMissingParam, "Required parameter 'id' is missing")
+	}
+
+	s, err := h.buildSong(c, jobID)
+	if err != nil {
+		return fail(c, errNotFound, "Album not found")
+	}
+
+	return ok(c, func(e *envelope) {
+		e.Album = &album{
+			albumSummary: albumSummary{ID: jobID, Name: s.Title, Artist: s.Artist, SongCount: 1},
+			Song:         []song{*s},
+		}
+	})
+}
+
+// GetSong handles /rest/getSong.view?id=, returning metadata for a single
+// completed export job.
+func (h *Handler) GetSong(c *fiber.Ctx) error {
+	jobID := c.Query("id")
+	if jobID == "" {
+		return fail(c, errMissingParam, "Required parameter 'id' is missing")
+	}
+
+	s, err := h.buildSong(c, jobID)
+	if err != nil {
+		return fail(c, errNotFound, "Song not found")
+	}
+
+	return ok(c, func(e *envelope) {
+		e.Song = s
+	})
+}
+
+// Stream handles /rest/stream.view?id= and Download handles
+// /rest/download.view?id=: both redirect to the same signed URL the
+// ordinary /api/export/result endpoint returns, rather than proxying bytes
+// through this process.
+func (h *Handler) Stream(c *fiber.Ctx) error {
+	return h.redirectToResult(c)
+}
+
+func (h *Handler) Download(c *fiber.Ctx) error {
+	return h.redirectToResult(c)
+}
+
+func (h *Handler) redirectToResult(c *fiber.Ctx) error {
+	jobID := c.Query("id")
+	if jobID == "" {
+		return fail(c, errMissingParam, "Required parameter 'id' is missing")
+	}
+
+	result, err := h.exportService.GetResult(c.Context(), jobID)
+	if err != nil {
+		return fail(c, errNotFound, "Requested data was not found")
+	}
+
+	return c.Redirect(result.FileURL, fiber.StatusFound)
+}
+
+// GetCoverArt handles /rest/getCoverArt.view?id=. Export jobs don't keep
+// their own cover art once embedded into the output file, so there's
+// nothing to serve back out-of-band; clients fall back to their own
+// placeholder art when this 404s, same as against a real Subsonic server
+// for a track with no art.
+func (h *Handler) GetCoverArt(c *fiber.Ctx) error {
+	return fail(c, errNotFound, "Requested data was not found")
+}
+
+// GetLyrics handles /rest/getLyrics.view?artist=&title=. This backend
+// doesn't persist a job's timed lyrics lines once the client used them to
+// request an export (see service.BuildLyricsExport, which takes them fresh
+// on every call) — so there's no stored lyrics body to return here, and
+// Subsonic's getLyrics takes artist/title rather than a jobId, which this
+// backend has no index from anyway.
+func (h *Handler) GetLyrics(c *fiber.Ctx) error {
+	return ok(c, func(e *envelope) {
+		e.Lyrics = &lyrics{}
+	})
+}
+
+func (h *Handler) buildSong(c *fiber.Ctx, jobID string) (*song, error) {
+	result, err := h.exportService.GetResult(c.Context(), jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &song{
+		ID:          jobID,
+		Parent:      jobID,
+		Title:       jobID,
+		Album:       "Renders",
+		Artist:      "make-singer",
+		IsDir:       false,
+		Size:        result.Size,
+		Suffix:      exportKindSuffix[result.Format],
+		ContentType: suffixContentType(exportKindSuffix[result.Format]),
+		CoverArt:    "",
+	}, nil
+}
+
+func suffixContentType(suffix string) string {
+	switch suffix {
+	case "mp3":
+		return "audio/mpeg"
+	case "wav":
+		return "audio/wav"
+	case "m4a":
+		return "audio/mp4"
+	case "flac":
+		return "audio/flac"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,134 @@
+package subsonic
+
+import (
+	"encoding/xml"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// envelope is the outer <subsonic-response>/"subsonic-response" object every
+// endpoint wraps its payload in. Exactly one of the optional fields below is
+// populated per response, mirroring how real Subsonic servers inline the
+// single relevant element rather than nesting under a generic "data" key.
+type envelope struct {
+	XMLName       xml.Name `xml:"subsonic-response" json:"-"`
+	Status        string   `xml:"status,attr" json:"status"`
+	Version       string   `xml:"version,attr" json:"version"`
+	Type          string   `xml:"type,attr" json:"type"`
+	ServerVersion string   `xml:"serverVersion,attr" json:"serverVersion"`
+
+	Error          *subsonicError  `xml:"error,omitempty" json:"error,omitempty"`
+	License        *license        `xml:"license,omitempty" json:"license,omitempty"`
+	MusicFolders   *musicFolders   `xml:"musicFolders,omitempty" json:"musicFolders,omitempty"`
+	Indexes        *indexes        `xml:"indexes,omitempty" json:"indexes,omitempty"`
+	AlbumList2     *albumList2     `xml:"albumList2,omitempty" json:"albumList2,omitempty"`
+	Album          *album          `xml:"album,omitempty" json:"album,omitempty"`
+	Song           *song           `xml:"song,omitempty" json:"song,omitempty"`
+	Lyrics         *lyrics         `xml:"lyrics,omitempty" json:"lyrics,omitempty"`
+}
+
+// jsonEnvelope is how the JSON transport wraps envelope: Subsonic's f=json
+// nests everything one level deeper, under a "subsonic-response" key,
+// instead of inlining the attributes like the XML transport does.
+type jsonEnvelope struct {
+	Response envelope `json:"subsonic-response"`
+}
+
+type subsonicError struct {
+	Code    int    `xml:"code,attr" json:"code"`
+	Message string `xml:"message,attr" json:"message"`
+}
+
+// Subsonic error codes (a subset of the protocol's; not all are used here).
+const (
+	errGeneric            = 0
+	errMissingParam       = 10
+	errClientUnsupported  = 20
+	errBadCredentials     = 40
+	errUnauthorizedAccess = 41
+	errNotFound           = 70
+)
+
+type license struct {
+	Valid bool `xml:"valid,attr" json:"valid"`
+}
+
+type musicFolders struct {
+	Folder []musicFolder `xml:"musicFolder" json:"musicFolder"`
+}
+
+type musicFolder struct {
+	ID   int    `xml:"id,attr" json:"id"`
+	Name string `xml:"name,attr" json:"name"`
+}
+
+type indexes struct {
+	LastModified int64    `xml:"lastModified,attr" json:"lastModified"`
+	Index        []string `xml:"index" json:"index"` // always empty: see package doc
+}
+
+type albumList2 struct {
+	Album []albumSummary `xml:"album" json:"album"`
+}
+
+type albumSummary struct {
+	ID        string `xml:"id,attr" json:"id"`
+	Name      string `xml:"name,attr" json:"name"`
+	Artist    string `xml:"artist,attr" json:"artist"`
+	SongCount int    `xml:"songCount,attr" json:"songCount"`
+}
+
+type album struct {
+	albumSummary
+	Song []song `xml:"song" json:"song"`
+}
+
+type song struct {
+	ID          string `xml:"id,attr" json:"id"`
+	Parent      string `xml:"parent,attr,omitempty" json:"parent,omitempty"`
+	Title       string `xml:"title,attr" json:"title"`
+	Album       string `xml:"album,attr,omitempty" json:"album,omitempty"`
+	Artist      string `xml:"artist,attr,omitempty" json:"artist,omitempty"`
+	IsDir       bool   `xml:"isDir,attr" json:"isDir"`
+	Duration    int    `xml:"duration,attr,omitempty" json:"duration,omitempty"`
+	Size        int64  `xml:"size,attr,omitempty" json:"size,omitempty"`
+	Suffix      string `xml:"suffix,attr,omitempty" json:"suffix,omitempty"`
+	ContentType string `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
+	CoverArt    string `xml:"coverArt,attr,omitempty" json:"coverArt,omitempty"`
+}
+
+type lyrics struct {
+	Artist string `xml:"artist,attr,omitempty" json:"artist,omitempty"`
+	Title  string `xml:"title,attr,omitempty" json:"title,omitempty"`
+	Value  string `xml:",chardata" json:"value,omitempty"`
+}
+
+// wantsJSON implements Subsonic's f=json content-negotiation parameter;
+// every other value (including the default, unset) means XML.
+func wantsJSON(c *fiber.Ctx) bool {
+	return c.Query("f") == "json"
+}
+
+func ok(c *fiber.Ctx, set func(*envelope)) error {
+	env := envelope{Status: "ok", Version: APIVersion, Type: ServerName, ServerVersion: APIVersion}
+	set(&env)
+	return write(c, env)
+}
+
+func fail(c *fiber.Ctx, code int, message string) error {
+	return write(c, envelope{
+		Status:        "failed",
+		Version:       APIVersion,
+		Type:          ServerName,
+		ServerVersion: APIVersion,
+		Error:         &subsonicError{Code: code, Message: message},
+	})
+}
+
+func write(c *fiber.Ctx, env envelope) error {
+	if wantsJSON(c) {
+		return c.JSON(jsonEnvelope{Response: env})
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXML)
+	return c.XML(env)
+}
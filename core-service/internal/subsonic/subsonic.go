@@ -0,0 +1,34 @@
+// Package subsonic exposes a small, honest subset of the Subsonic REST API
+// (http://www.subsonic.org/pages/api.jsp) over this backend's completed
+// export jobs, so existing Subsonic clients (DSub, play:Sub, Symfonium,
+// Navidrome's web player, ...) can stream a finished render without a
+// custom client.
+//
+// This backend has no persistent project/album/artist catalog — renders
+// live as TTL'd job records in Redis (see service.ExportService), not rows
+// in a browsable library. So this package does not attempt to fake one:
+// getIndexes/getAlbumList2 return spec-compliant *empty* collections rather
+// than invented artists/albums, and getAlbum/getSong/stream/download only
+// work when the caller already knows the export jobId it wants (the
+// realistic path: the app's own UI hands a user a Subsonic stream URL for a
+// render it just finished, rather than a client browsing a library from
+// scratch); getAlbum then presents that one job as a one-song album.
+// getCoverArt always answers not-found and getLyrics always returns empty
+// lyrics.
+//
+// Subsonic's token auth (t = md5(password+salt), s = salt) requires the
+// server to hold a plaintext/recoverable password to hash against; this
+// backend delegates authentication to Zitadel/JWT and holds no such
+// secret, so only the plain `p` parameter is supported, and it must carry
+// a legacy HMAC-signed JWT rather than a real password. Federated (JWKS)
+// tokens are not accepted here.
+package subsonic
+
+// APIVersion is the Subsonic REST API version this package implements
+// against. Clients use it to gate which optional fields/endpoints they try.
+const APIVersion = "1.16.1"
+
+// ServerName is reported in every response as the "serving" application,
+// matching the convention third-party Subsonic servers (Navidrome, Airsonic)
+// use to identify themselves to clients that branch on it.
+const ServerName = "make-singer-backend"
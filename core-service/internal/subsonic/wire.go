@@ -0,0 +1,8 @@
+package subsonic
+
+import "github.com/google/wire"
+
+// ProviderSet wires Handler for app.InitializeApp/InitializeTestApp.
+var ProviderSet = wire.NewSet(
+	NewHandler,
+)
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// ValidateCallbackURL rejects a callback URL that isn't safe for this
+// service to make an outbound POST to: anything other than http/https, and
+// any hostname that resolves (at the time of the check) to a loopback,
+// link-local, or private-range address -- covers localhost, the cloud
+// metadata endpoint (169.254.169.254), and internal service addresses.
+//
+// It's called both when a callback is first submitted
+// (RenderService.StartMaster) and again immediately before delivery
+// (Deliver). A submission-only check would miss DNS rebinding: a hostname
+// that resolves to a public address when the job is queued and a private
+// one by the time delivery actually runs.
+func ValidateCallbackURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("callback url scheme must be http or https")
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("callback url has no host")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve callback host: %w", err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("callback host did not resolve to any address")
+	}
+	for _, addr := range addrs {
+		if isDisallowedCallbackIP(addr.IP) {
+			return fmt.Errorf("callback host resolves to a disallowed address: %s", addr.IP)
+		}
+	}
+	return nil
+}
+
+// isDisallowedCallbackIP reports whether ip is loopback, link-local, or a
+// private (RFC1918/RFC4193) range.
+func isDisallowedCallbackIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified()
+}
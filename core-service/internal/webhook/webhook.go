@@ -0,0 +1,81 @@
+// Package webhook signs and delivers the job-completion callbacks
+// MasterFinalRequest.CallbackURL opts a caller into (see
+// RenderService.fireWebhook and worker.WebhookWorker), so the signing
+// convention lives in one place shared by the enqueue side and the
+// delivery side.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sign computes the signature a delivery carries in its X-Signature
+// header: an HMAC-SHA256 over "<timestamp>.<body>", Stripe-style, so a
+// captured (timestamp, signature) pair can't be replayed against a
+// different body, and a receiver that rejects stale X-Timestamp values can
+// refuse a replayed body. Verify checks only the signature, not the
+// timestamp's age.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify recomputes Sign and compares it against signature in constant
+// time, for the rare receiver inside this codebase that also consumes one
+// of its own callbacks (tests, and anything that replays a callback to a
+// second internal listener). External receivers reimplement this from the
+// docs, same as any other webhook signing scheme.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	want := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(want), []byte(signature))
+}
+
+// Deliver POSTs body to url with X-Signature and X-Timestamp headers set,
+// returning an error for any transport failure or non-2xx response so the
+// caller (WebhookWorker.ProcessTask) can hand it back to Asynq's own
+// retry/backoff instead of re-implementing one here.
+//
+// url is re-validated with ValidateCallbackURL immediately before the
+// request is made, even though RenderService.StartMaster already validated
+// it at submission time -- a retried delivery can run long enough after
+// submission for a rebinding DNS record to have moved the host to a
+// disallowed address in between.
+func Deliver(ctx context.Context, httpClient *http.Client, url, secret string, body []byte) error {
+	if err := ValidateCallbackURL(ctx, url); err != nil {
+		return fmt.Errorf("refusing to deliver webhook: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", Sign(secret, timestamp, body))
+	req.Header.Set("X-Timestamp", timestamp)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
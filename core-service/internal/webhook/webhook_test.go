@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSign_VerifyRoundTrip(t *testing.T) {
+	secret := "super-secret-callback-key"
+	body := []byte(`{"jobId":"abc123","event":"done"}`)
+	timestamp := "1700000000"
+
+	sig := Sign(secret, timestamp, body)
+	if sig == "" {
+		t.Fatal("expected a non-empty signature")
+	}
+	if !Verify(secret, timestamp, body, sig) {
+		t.Error("expected Verify to accept the signature Sign produced")
+	}
+}
+
+func TestVerify_RejectsTamperedBody(t *testing.T) {
+	secret := "super-secret-callback-key"
+	timestamp := "1700000000"
+	sig := Sign(secret, timestamp, []byte(`{"jobId":"abc123","event":"done"}`))
+
+	if Verify(secret, timestamp, []byte(`{"jobId":"abc123","event":"failed"}`), sig) {
+		t.Error("expected Verify to reject a body that doesn't match the signed one")
+	}
+}
+
+func TestVerify_RejectsWrongSecret(t *testing.T) {
+	body := []byte(`{"jobId":"abc123","event":"done"}`)
+	timestamp := "1700000000"
+	sig := Sign("secret-a", timestamp, body)
+
+	if Verify("secret-b", timestamp, body, sig) {
+		t.Error("expected Verify to reject a signature produced with a different secret")
+	}
+}
+
+func TestValidateCallbackURL_RejectsLoopback(t *testing.T) {
+	if err := ValidateCallbackURL(context.Background(), "http://127.0.0.1:8080/callback"); err == nil {
+		t.Error("expected loopback callback url to be rejected")
+	}
+}
+
+func TestValidateCallbackURL_RejectsLinkLocal(t *testing.T) {
+	// 169.254.169.254 is the cloud metadata endpoint on every major provider.
+	if err := ValidateCallbackURL(context.Background(), "http://169.254.169.254/latest/meta-data/"); err == nil {
+		t.Error("expected link-local callback url to be rejected")
+	}
+}
+
+func TestValidateCallbackURL_RejectsPrivateRange(t *testing.T) {
+	if err := ValidateCallbackURL(context.Background(), "http://10.0.0.5/callback"); err == nil {
+		t.Error("expected RFC1918 callback url to be rejected")
+	}
+}
+
+func TestValidateCallbackURL_RejectsNonHTTPScheme(t *testing.T) {
+	if err := ValidateCallbackURL(context.Background(), "ftp://93.184.216.34/callback"); err == nil {
+		t.Error("expected a non-http(s) scheme to be rejected")
+	}
+}
+
+func TestValidateCallbackURL_AcceptsPublicAddress(t *testing.T) {
+	if err := ValidateCallbackURL(context.Background(), "https://93.184.216.34/callback"); err != nil {
+		t.Errorf("expected a public address to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,538 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/internal/model"
+)
+
+const (
+	// eventsStreamMaxLen bounds how many events job:{id}:events keeps, so a
+	// long-running job can't grow its replay buffer without limit.
+	eventsStreamMaxLen = 500
+	eventsStreamTTL    = 24 * time.Hour
+
+	// subscribeBaseBackoff/subscribeMaxBackoff bound how Start retries a
+	// dropped job:* subscription: doubling from base up to max rather than
+	// hammering Redis immediately after a disconnect.
+	subscribeBaseBackoff = time.Second
+	subscribeMaxBackoff  = 30 * time.Second
+)
+
+var (
+	wsConnectionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ws_hub_connections_active",
+		Help: "Number of currently registered WebSocket clients.",
+	})
+	wsMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ws_hub_messages_total",
+		Help: "Total messages broadcast to job subscribers, by message type.",
+	}, []string{"type"})
+)
+
+// Client represents a WebSocket client
+type Client struct {
+	JobID string
+	Conn  *websocket.Conn
+	Send  chan []byte
+}
+
+// Hub maintains active WebSocket connections
+type Hub struct {
+	// Clients grouped by job ID
+	clients map[string]map[*Client]bool
+
+	// Register requests
+	register chan *Client
+
+	// Unregister requests
+	unregister chan *Client
+
+	// Broadcast messages to job subscribers
+	broadcast chan *BroadcastMessage
+
+	// redis backs the per-job event replay buffer (job:{id}:events) so a
+	// reconnecting client can catch up on what it missed. May be nil, in
+	// which case events are only ever delivered live.
+	redis *redis.Client
+
+	mu sync.RWMutex
+}
+
+// BroadcastMessage represents a message to broadcast
+type BroadcastMessage struct {
+	JobID   string
+	Message []byte
+}
+
+// NewHub creates a new Hub. redisClient backs the replay buffer used by
+// HandleConnection's `since` resume support; pass nil to disable it.
+func NewHub(redisClient *redis.Client) *Hub {
+	return &Hub{
+		clients:    make(map[string]map[*Client]bool),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan *BroadcastMessage, 256),
+		redis:      redisClient,
+	}
+}
+
+// Run starts the hub's main loop
+func (h *Hub) Run() {
+	for {
+		select {
+		case client := <-h.register:
+			h.mu.Lock()
+			if h.clients[client.JobID] == nil {
+				h.clients[client.JobID] = make(map[*Client]bool)
+			}
+			h.clients[client.JobID][client] = true
+			h.mu.Unlock()
+			wsConnectionsActive.Inc()
+			log.Info(log.WithFields(context.Background(), "jobId", client.JobID), "client registered")
+
+		case client := <-h.unregister:
+			h.mu.Lock()
+			if clients, ok := h.clients[client.JobID]; ok {
+				if _, ok := clients[client]; ok {
+					delete(clients, client)
+					close(client.Send)
+					wsConnectionsActive.Dec()
+					if len(clients) == 0 {
+						delete(h.clients, client.JobID)
+					}
+				}
+			}
+			h.mu.Unlock()
+			log.Info(log.WithFields(context.Background(), "jobId", client.JobID), "client unregistered")
+
+		case msg := <-h.broadcast:
+			h.mu.RLock()
+			if clients, ok := h.clients[msg.JobID]; ok {
+				for client := range clients {
+					select {
+					case client.Send <- msg.Message:
+					default:
+						close(client.Send)
+						delete(clients, client)
+					}
+				}
+			}
+			h.mu.RUnlock()
+		}
+	}
+}
+
+// Subscribe registers a lightweight client that only ever receives broadcast
+// payloads over its Send channel, for callers (the SSE handlers) that don't
+// hold a *websocket.Conn. It joins the same register/broadcast/unregister
+// plumbing HandleConnection uses, so it transparently receives whatever
+// subscribeLoop fans out from Redis. The caller must invoke unsubscribe when
+// done, or the client (and its buffered Send channel) leaks.
+func (h *Hub) Subscribe(jobID string) (client *Client, unsubscribe func()) {
+	client = &Client{JobID: jobID, Send: make(chan []byte, 256)}
+	h.Register(client)
+	return client, func() { h.Unregister(client) }
+}
+
+// Register adds a new client
+func (h *Hub) Register(client *Client) {
+	h.register <- client
+}
+
+// Unregister removes a client
+func (h *Hub) Unregister(client *Client) {
+	h.unregister <- client
+}
+
+// publish assigns jobID's next sequence number, marshals the message build
+// produces for it, appends the result to the replay buffer, and fans it out
+// to every subscribed API replica over Redis pub/sub (falling back to
+// local-only delivery if Redis is unset or the PUBLISH fails, so a single
+// Redis hiccup doesn't silently drop the update). msgType labels the
+// ws_hub_messages_total counter and should match the model.WSMessageType
+// build embeds in its result.
+func (h *Hub) publish(jobID, msgType string, build func(seq int64) interface{}) {
+	seq := h.nextSeq(jobID)
+
+	data, err := json.Marshal(build(seq))
+	if err != nil {
+		log.Error(log.WithFields(context.Background(), "jobId", jobID), "failed to marshal ws message", "err", err)
+		return
+	}
+
+	h.recordEvent(jobID, seq, data)
+	wsMessagesTotal.WithLabelValues(msgType).Inc()
+
+	if h.redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := h.redis.Publish(ctx, jobChannel(jobID), data).Err()
+		cancel()
+		if err == nil {
+			// Delivered to every replica subscribed via Start, including this
+			// one -- its subscribeLoop fans it out to local clients.
+			return
+		}
+		log.Error(log.WithFields(context.Background(), "jobId", jobID), "failed to publish job event, falling back to local-only delivery", "err", err)
+	}
+
+	h.broadcast <- &BroadcastMessage{
+		JobID:   jobID,
+		Message: data,
+	}
+}
+
+// jobChannel is the Redis pub/sub channel a job's events are published on,
+// shared by every API replica's Start subscription.
+func jobChannel(jobID string) string {
+	return fmt.Sprintf("job:%s", jobID)
+}
+
+// Start subscribes to job:* over Redis pub/sub so a client connected to one
+// API replica still receives progress published by whichever replica
+// actually handled the runnerapi callback for that job. It blocks until ctx
+// is canceled, reconnecting with exponential backoff if the subscription
+// drops. No-op if the Hub was built without a Redis client.
+func (h *Hub) Start(ctx context.Context) {
+	if h.redis == nil {
+		return
+	}
+
+	backoff := subscribeBaseBackoff
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := h.subscribeLoop(ctx); err != nil {
+			log.Error(ctx, "job pub/sub subscription dropped, retrying", "err", err, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > subscribeMaxBackoff {
+				backoff = subscribeMaxBackoff
+			}
+			continue
+		}
+		backoff = subscribeBaseBackoff
+	}
+}
+
+// subscribeLoop runs one PSubscribe("job:*") session, dispatching received
+// messages to local clients until ctx is canceled (returning nil) or the
+// subscription drops (returning a non-nil error for Start to retry on).
+func (h *Hub) subscribeLoop(ctx context.Context) error {
+	pubsub := h.redis.PSubscribe(ctx, "job:*")
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to job:*: %w", err)
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("job:* pub/sub channel closed")
+			}
+			jobID := strings.TrimPrefix(msg.Channel, "job:")
+			h.broadcast <- &BroadcastMessage{JobID: jobID, Message: []byte(msg.Payload)}
+		}
+	}
+}
+
+// WatchWithTimeout long-polls jobID for a state change, for callers that
+// prefer a single HTTP request over holding a WebSocket open. If any
+// buffered events are newer than lastSeq, it returns the oldest of them
+// immediately; if none are available, it subscribes to jobID's channel and
+// waits up to timeout for the next one.
+// Returns (nil, nil) on timeout with nothing new.
+func (h *Hub) WatchWithTimeout(ctx context.Context, jobID string, lastSeq int64, timeout time.Duration) ([]byte, error) {
+	if buffered, err := h.ReplayEvents(ctx, jobID, lastSeq); err != nil {
+		return nil, err
+	} else if len(buffered) > 0 {
+		return buffered[0], nil
+	}
+
+	if h.redis == nil {
+		return nil, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pubsub := h.redis.Subscribe(waitCtx, jobChannel(jobID))
+	defer pubsub.Close()
+
+	select {
+	case msg, ok := <-pubsub.Channel():
+		if !ok {
+			return nil, nil
+		}
+		return []byte(msg.Payload), nil
+	case <-waitCtx.Done():
+		return nil, nil
+	}
+}
+
+// BroadcastProgress sends a progress update to all job subscribers
+func (h *Hub) BroadcastProgress(jobID string, progress int, status model.JobStatus, step string) {
+	h.publish(jobID, model.WSMessageTypeProgress, func(seq int64) interface{} {
+		return model.WSProgressMessage{
+			Type:        model.WSMessageTypeProgress,
+			JobID:       jobID,
+			Progress:    progress,
+			Status:      status,
+			CurrentStep: step,
+			Seq:         seq,
+		}
+	})
+}
+
+// BroadcastComplete sends a completion message to all job subscribers
+func (h *Hub) BroadcastComplete(jobID string, result interface{}) {
+	h.publish(jobID, model.WSMessageTypeComplete, func(seq int64) interface{} {
+		return model.WSCompleteMessage{
+			Type:   model.WSMessageTypeComplete,
+			JobID:  jobID,
+			Result: result,
+			Seq:    seq,
+		}
+	})
+}
+
+// BroadcastError sends an error message to all job subscribers
+func (h *Hub) BroadcastError(jobID string, code, message string) {
+	h.publish(jobID, model.WSMessageTypeError, func(seq int64) interface{} {
+		return model.WSErrorMessage{
+			Type:  model.WSMessageTypeError,
+			JobID: jobID,
+			Error: model.WSError{
+				Code:    code,
+				Message: message,
+			},
+			Seq: seq,
+		}
+	})
+}
+
+// BroadcastLog sends a batch of log lines for a job to all subscribers.
+func (h *Hub) BroadcastLog(jobID, stream string, lines []string) {
+	h.publish(jobID, model.WSMessageTypeLog, func(seq int64) interface{} {
+		return model.WSLogMessage{
+			Type:   model.WSMessageTypeLog,
+			JobID:  jobID,
+			Stream: stream,
+			Lines:  lines,
+		}
+	})
+}
+
+// BroadcastStructuredError sends a structured error to all job subscribers,
+// including the per-item failure breakdown for partially-failed batch jobs.
+func (h *Hub) BroadcastStructuredError(jobID string, structErr *model.JobStructuredError) {
+	h.publish(jobID, model.WSMessageTypeError, func(seq int64) interface{} {
+		return model.WSErrorMessage{
+			Type:  model.WSMessageTypeError,
+			JobID: jobID,
+			Error: model.WSError{
+				Code:    structErr.Code,
+				Message: structErr.Message,
+				Items:   structErr.Items,
+			},
+			Seq: seq,
+		}
+	})
+}
+
+// nextSeq reserves the next monotonic sequence number for jobID's event
+// stream. Returns 0 (and leaves the event unrecorded) if no Redis client is
+// configured or the reservation fails — the message still gets broadcast
+// live, it just won't be replayable on reconnect.
+func (h *Hub) nextSeq(jobID string) int64 {
+	if h.redis == nil {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	key := eventsSeqKey(jobID)
+	seq, err := h.redis.Incr(ctx, key).Result()
+	if err != nil {
+		log.Error(log.WithFields(context.Background(), "jobId", jobID), "failed to reserve event sequence", "err", err)
+		return 0
+	}
+	h.redis.Expire(ctx, key, eventsStreamTTL)
+	return seq
+}
+
+// recordEvent appends data to jobID's capped replay stream under seq,
+// trimming it back down to eventsStreamMaxLen entries.
+func (h *Hub) recordEvent(jobID string, seq int64, data []byte) {
+	if h.redis == nil || seq == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	key := eventsStreamKey(jobID)
+	h.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		ID:     seqToStreamID(seq),
+		MaxLen: eventsStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"payload": data},
+	})
+	h.redis.Expire(ctx, key, eventsStreamTTL)
+}
+
+// ReplayEvents returns the JSON payloads of jobID's buffered events with
+// sequence greater than since, oldest first. since <= 0 returns the entire
+// buffered history (bounded by eventsStreamMaxLen).
+func (h *Hub) ReplayEvents(ctx context.Context, jobID string, since int64) ([][]byte, error) {
+	if h.redis == nil {
+		return nil, nil
+	}
+
+	start := "-"
+	if since > 0 {
+		start = "(" + seqToStreamID(since)
+	}
+
+	entries, err := h.redis.XRange(ctx, eventsStreamKey(jobID), start, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		payload, ok := entry.Values["payload"].(string)
+		if !ok {
+			continue
+		}
+		payloads = append(payloads, []byte(payload))
+	}
+	return payloads, nil
+}
+
+func eventsStreamKey(jobID string) string {
+	return fmt.Sprintf("job:%s:events", jobID)
+}
+
+func eventsSeqKey(jobID string) string {
+	return fmt.Sprintf("job:%s:seq", jobID)
+}
+
+// seqToStreamID turns a sequence number into a Redis stream entry ID.
+// Sequence numbers come from INCR, so they're already strictly increasing —
+// the fixed "-1" suffix just satisfies the stream ID format.
+func seqToStreamID(seq int64) string {
+	return strconv.FormatInt(seq, 10) + "-1"
+}
+
+// HandleConnection handles a WebSocket connection. If the client passes a
+// `since` query parameter (the last `seq` it saw), buffered events newer
+// than that are written after the client registers for the live broadcast
+// but before any live message is sent — the two can overlap at the
+// boundary, so clients should dedupe on Seq.
+func (h *Hub) HandleConnection(c *websocket.Conn, jobID string) {
+	client := &Client{
+		JobID: jobID,
+		Conn:  c,
+		Send:  make(chan []byte, 256),
+	}
+
+	h.Register(client)
+	defer h.Unregister(client)
+
+	if since, err := strconv.ParseInt(c.Query("since"), 10, 64); err == nil {
+		h.replaySince(client, since)
+	}
+
+	// Start writer goroutine
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case message, ok := <-client.Send:
+				if !ok {
+					c.WriteMessage(websocket.CloseMessage, []byte{})
+					return
+				}
+				if err := c.WriteMessage(websocket.TextMessage, message); err != nil {
+					return
+				}
+
+			case <-ticker.C:
+				// Send ping for keep-alive
+				if err := c.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Reader loop
+	for {
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Error(log.WithFields(context.Background(), "jobId", jobID), "websocket error", "err", err)
+			}
+			break
+		}
+
+		// Handle client messages (ping/pong)
+		var msg model.WSMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			continue
+		}
+
+		if msg.Type == model.WSMessageTypePing {
+			pong := model.WSMessage{Type: model.WSMessageTypePong}
+			data, _ := json.Marshal(pong)
+			client.Send <- data
+		}
+	}
+}
+
+// replaySince writes buffered events newer than since directly to the
+// connection, ahead of the live writer goroutine.
+func (h *Hub) replaySince(client *Client, since int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	payloads, err := h.ReplayEvents(ctx, client.JobID, since)
+	if err != nil {
+		log.Error(log.WithFields(context.Background(), "jobId", client.JobID), "failed to replay events", "err", err)
+		return
+	}
+
+	for _, payload := range payloads {
+		if err := client.Conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			return
+		}
+	}
+}
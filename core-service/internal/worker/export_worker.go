@@ -0,0 +1,702 @@
+package worker
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/log"
+	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/runnerapi"
+	"github.com/makeasinger/api/internal/service"
+)
+
+// exportURLTTL is how long a presigned export download URL stays valid.
+const exportURLTTL = 24 * time.Hour
+
+// ExportWorker processes export jobs (MP3/WAV/ALAC/FLAC/Opus/Ogg/Atmos and
+// stems ZIP). Like RenderWorker
+// and MasterWorker, it never writes job state directly — progress,
+// completion, and failure are reported to the API over the runnerapi RPC
+// contract.
+type ExportWorker struct {
+	redis        *redis.Client
+	audioClient  client.AudioProcessor
+	r2Client     client.StorageClient
+	runnerClient *runnerapi.Client
+}
+
+// NewExportWorker creates a new export worker
+func NewExportWorker(redisClient *redis.Client, audioClient client.AudioProcessor, r2Client client.StorageClient, runnerClient *runnerapi.Client) *ExportWorker {
+	return &ExportWorker{
+		redis:        redisClient,
+		audioClient:  audioClient,
+		r2Client:     r2Client,
+		runnerClient: runnerClient,
+	}
+}
+
+// ProcessTask handles export task processing
+func (w *ExportWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var taskPayload struct {
+		JobID   string          `json:"jobId"`
+		Payload json.RawMessage `json:"payload"`
+	}
+
+	if err := json.Unmarshal(t.Payload(), &taskPayload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", err)
+	}
+
+	jobID := taskPayload.JobID
+	ctx = log.WithFields(ctx, "jobId", jobID)
+	log.Info(ctx, "starting export job")
+
+	ctx, cancel := service.WatchForCancellation(ctx, w.redis, jobID)
+	defer cancel()
+
+	var payload model.ExportJobPayload
+	if err := json.Unmarshal(taskPayload.Payload, &payload); err != nil {
+		w.failJob(ctx, jobID, "Invalid payload")
+		return fmt.Errorf("failed to unmarshal export payload: %w", err)
+	}
+
+	var (
+		result *model.ExportResultResponse
+		err    error
+	)
+
+	// Without an audio microservice configured, fall back to shelling out
+	// to ffmpeg directly rather than failing the export outright.
+	if w.audioClient != nil {
+		result, err = w.processWithAudioService(ctx, jobID, &payload)
+	} else {
+		result, err = w.processLocal(ctx, jobID, &payload)
+	}
+	if err != nil {
+		w.failJob(ctx, jobID, err.Error())
+		return err
+	}
+
+	if err := w.runnerClient.Complete(ctx, jobID, result, nil); err != nil {
+		w.failJob(ctx, jobID, "Failed to save result")
+		return err
+	}
+
+	log.Info(ctx, "export job completed")
+	return nil
+}
+
+// processWithAudioService delegates encoding/zipping to the Python audio
+// microservice, the same way this job type worked before it was queued.
+func (w *ExportWorker) processWithAudioService(ctx context.Context, jobID string, payload *model.ExportJobPayload) (*model.ExportResultResponse, error) {
+	w.updateProgress(ctx, jobID, 10, "Preparing export...")
+
+	switch payload.Kind {
+	case model.ExportKindStems:
+		files := make([]client.ZipFileEntry, len(payload.SourceURLs))
+		for i, url := range payload.SourceURLs {
+			files[i] = client.ZipFileEntry{URL: url, Filename: payload.Filenames[i]}
+		}
+		if payload.LyricsLRC != "" {
+			files = append(files, client.ZipFileEntry{Content: payload.LyricsLRC, Filename: "lyrics.lrc"})
+		} else if payload.LyricsLRCURL != "" {
+			files = append(files, client.ZipFileEntry{URL: payload.LyricsLRCURL, Filename: "lyrics.lrc"})
+		}
+
+		zipReq := &client.ZipRequest{Files: files, OutputKey: payload.OutputKey}
+
+		// Best-effort: a streaming URL the client can start downloading from
+		// immediately, while CreateZip below still does the archive+R2
+		// upload that produces the permanent FileURL. Not fatal if the audio
+		// service doesn't offer it.
+		streamURL, err := w.audioClient.StreamZip(ctx, jobID, zipReq)
+		if err != nil {
+			log.Warn(ctx, "stream zip unavailable, falling back to archive-then-serve", "err", err)
+		}
+
+		w.updateProgress(ctx, jobID, 30, "Building archive...")
+		resp, err := w.audioClient.CreateZip(ctx, jobID, zipReq)
+		if err != nil {
+			return nil, fmt.Errorf("ZIP creation failed: %w", err)
+		}
+
+		fileURL, expiresAt := w.resolveOutputURL(ctx, payload.OutputKey, resp.OutputURL)
+		if streamURL == "" {
+			streamURL = fileURL
+		}
+		return &model.ExportResultResponse{FileURL: fileURL, StreamURL: streamURL, Size: resp.Size, FileCount: resp.FileCount, ExpiresAt: expiresAt}, nil
+
+	default:
+		var metadata map[string]string
+		if payload.Metadata != nil {
+			metadata = map[string]string{}
+			if payload.Metadata.Title != "" {
+				metadata["title"] = payload.Metadata.Title
+			}
+			if payload.Metadata.Artist != "" {
+				metadata["artist"] = payload.Metadata.Artist
+			}
+			if payload.Metadata.Album != "" {
+				metadata["album"] = payload.Metadata.Album
+			}
+			if payload.Metadata.Year != nil {
+				metadata["year"] = fmt.Sprintf("%d", *payload.Metadata.Year)
+			}
+			if payload.EmbedLyrics && payload.Metadata.Lyrics != "" {
+				metadata["lyrics"] = payload.Metadata.Lyrics
+			}
+		}
+
+		var coverURL, coverSize, coverFormat, animatedArtworkURL string
+		if payload.Metadata != nil {
+			coverURL = payload.Metadata.CoverURL
+			coverSize = payload.Metadata.CoverSize
+			coverFormat = payload.Metadata.CoverFormat
+			animatedArtworkURL = payload.Metadata.AnimatedArtworkURL
+		}
+
+		w.updateProgress(ctx, jobID, 30, "Encoding...")
+		resp, err := w.audioClient.Encode(ctx, jobID, &client.EncodeRequest{
+			InputURL:             payload.SourceURLs[0],
+			Format:               string(payload.Kind),
+			Quality:              payload.Quality,
+			SampleRate:           payload.SampleRate,
+			BitDepth:             payload.BitDepth,
+			Metadata:             metadata,
+			OutputKey:            payload.OutputKey,
+			CompressionLevel:     payload.CompressionLevel,
+			BedLayout:            payload.BedLayout,
+			ObjectCount:          payload.ObjectCount,
+			Binaural:             payload.Binaural,
+			ObjectAutomationURLs: payload.ObjectAutomationURLs,
+			EmbedCover:           payload.EmbedCover,
+			CoverURL:             coverURL,
+			CoverSize:            coverSize,
+			CoverFormat:          coverFormat,
+			AnimatedArtworkURL:   animatedArtworkURL,
+			Bitrate:              payload.Bitrate,
+			VBR:                  payload.VBR,
+			Application:          payload.Application,
+			ChannelLayout:        payload.ChannelLayout,
+			LyricsLRC:            payload.LyricsLRC,
+			LyricsLRCURL:         payload.LyricsLRCURL,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s encoding failed: %w", payload.Kind, err)
+		}
+
+		fileURL, expiresAt := w.resolveOutputURL(ctx, payload.OutputKey, resp.OutputURL)
+		return &model.ExportResultResponse{
+			FileURL: fileURL, Size: resp.Size, Format: string(payload.Kind),
+			Quality: payload.Quality, BitDepth: payload.BitDepth, SampleRate: payload.SampleRate, CompressionLevel: payload.CompressionLevel,
+			CoverEmbedded: resp.CoverEmbedded, AnimatedArtworkEmbedded: resp.AnimatedArtworkEmbedded,
+			ExpiresAt: expiresAt,
+		}, nil
+	}
+}
+
+// processLocal builds the export directly in this process: it downloads the
+// source audio, shells out to ffmpeg to encode MP3/WAV/ALAC/FLAC/Opus/Ogg
+// (or streams a ZIP of the sources for stems), and uploads the result to
+// object storage, so exports work even without the Python audio
+// microservice configured. Atmos has no local path and returns an error.
+func (w *ExportWorker) processLocal(ctx context.Context, jobID string, payload *model.ExportJobPayload) (*model.ExportResultResponse, error) {
+	switch payload.Kind {
+	case model.ExportKindMP3:
+		args := append(mp3FFmpegArgs(payload.Quality), metadataFFmpegArgs(payload.Metadata, payload.EmbedLyrics)...)
+		lyricsArgs, err := w.syncedLyricsFFmpegArgs(ctx, payload)
+		if err != nil {
+			log.Error(ctx, "failed to resolve synced lyrics, continuing without them", "err", err)
+		} else {
+			args = append(args, lyricsArgs...)
+		}
+		return w.encodeLocal(ctx, jobID, payload, "audio/mpeg", args)
+	case model.ExportKindWAV:
+		return w.encodeLocal(ctx, jobID, payload, "audio/wav", wavFFmpegArgs(payload.BitDepth, payload.SampleRate))
+	case model.ExportKindALAC:
+		args := append(alacFFmpegArgs(payload.BitDepth, payload.SampleRate), metadataFFmpegArgs(payload.Metadata, payload.EmbedLyrics)...)
+		return w.encodeLocal(ctx, jobID, payload, "audio/mp4", args)
+	case model.ExportKindFLAC:
+		args := append(flacFFmpegArgs(payload.BitDepth, payload.SampleRate, payload.CompressionLevel), metadataFFmpegArgs(payload.Metadata, false)...)
+		return w.encodeLocal(ctx, jobID, payload, "audio/flac", args)
+	case model.ExportKindOpus:
+		return w.encodeLocal(ctx, jobID, payload, "audio/opus", opusFFmpegArgs(payload.Bitrate, payload.VBR, payload.Application, payload.ChannelLayout))
+	case model.ExportKindOgg:
+		return w.encodeLocal(ctx, jobID, payload, "audio/ogg", oggFFmpegArgs(payload.Quality, payload.Bitrate, payload.VBR, payload.ChannelLayout))
+	case model.ExportKindStems:
+		return w.zipLocal(ctx, jobID, payload)
+	case model.ExportKindAtmos:
+		// ffmpeg has no Dolby Atmos / EC-3 JOC encoder, so there's no honest
+		// local fallback here — Atmos exports require the audio
+		// microservice (processWithAudioService) to be configured.
+		return nil, fmt.Errorf("atmos export requires the audio microservice; no local EC-3 JOC encoder is available")
+	default:
+		return nil, fmt.Errorf("unknown export kind %q", payload.Kind)
+	}
+}
+
+// encodeLocal downloads payload.SourceURLs[0], runs ffmpeg with codecArgs to
+// produce the output format, and uploads the real encoded bytes — no
+// placeholder size or duration. When payload requests a cover image, it's
+// downloaded and muxed in as an attached picture (APIC/covr/
+// METADATA_BLOCK_PICTURE depending on container); animated artwork has no
+// local-encode path: AnimatedArtworkURL is ignored here and
+// AnimatedArtworkEmbedded is left false.
+func (w *ExportWorker) encodeLocal(ctx context.Context, jobID string, payload *model.ExportJobPayload, contentType string, codecArgs []string) (*model.ExportResultResponse, error) {
+	w.updateProgress(ctx, jobID, 10, "Downloading source...")
+	inPath, cleanup, err := downloadToTempFile(ctx, payload.SourceURLs[0], "export-src-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download source: %w", err)
+	}
+	defer cleanup()
+
+	args := []string{"-y", "-i", inPath}
+
+	coverEmbedded := false
+	if payload.EmbedCover && payload.Metadata != nil && payload.Metadata.CoverURL != "" {
+		w.updateProgress(ctx, jobID, 25, "Downloading cover art...")
+		coverPath, coverCleanup, err := downloadToTempFile(ctx, payload.Metadata.CoverURL, "export-cover-*")
+		if err != nil {
+			log.Error(ctx, "failed to download cover art, continuing without it", "err", err)
+		} else {
+			defer coverCleanup()
+			args = append(args, "-i", coverPath, "-map", "0:a", "-map", "1:v", "-disposition:v", "attached_pic")
+			coverEmbedded = true
+		}
+	}
+	if !coverEmbedded {
+		args = append(args, "-vn")
+	}
+
+	outPath := inPath + "-out" + extensionFor(payload.Kind)
+	defer os.Remove(outPath)
+
+	w.updateProgress(ctx, jobID, 40, "Encoding with ffmpeg...")
+	args = append(args, codecArgs...)
+	args = append(args, outPath)
+	if out, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("ffmpeg encode failed: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+
+	w.updateProgress(ctx, jobID, 80, "Uploading...")
+	size, err := w.uploadLocalFile(ctx, payload.OutputKey, outPath, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	fileURL, expiresAt := w.resolveOutputURL(ctx, payload.OutputKey, "")
+	return &model.ExportResultResponse{
+		FileURL: fileURL, Size: size, Format: string(payload.Kind),
+		Quality: payload.Quality, BitDepth: payload.BitDepth, SampleRate: payload.SampleRate, CompressionLevel: payload.CompressionLevel,
+		CoverEmbedded: coverEmbedded,
+		ExpiresAt:     expiresAt,
+	}, nil
+}
+
+// zipLocal streams a ZIP of every source directly into the storage upload —
+// each entry is downloaded and copied into the archive one at a time, so the
+// whole archive is never buffered in memory.
+func (w *ExportWorker) zipLocal(ctx context.Context, jobID string, payload *model.ExportJobPayload) (*model.ExportResultResponse, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		zw := zip.NewWriter(pw)
+		total := len(payload.SourceURLs)
+		for i, url := range payload.SourceURLs {
+			w.updateProgress(ctx, jobID, 10+i*70/total, fmt.Sprintf("Adding %s...", payload.Filenames[i]))
+			if err := addZipEntry(ctx, zw, url, payload.Filenames[i]); err != nil {
+				zw.Close()
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		lrc, err := resolveLyricsLRC(ctx, payload)
+		if err != nil {
+			zw.Close()
+			pw.CloseWithError(fmt.Errorf("failed to resolve synced lyrics: %w", err))
+			return
+		}
+		if lrc != "" {
+			if err := addZipEntryContent(zw, "lyrics.lrc", lrc); err != nil {
+				zw.Close()
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		if err := zw.Close(); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.Close()
+	}()
+
+	w.updateProgress(ctx, jobID, 85, "Uploading archive...")
+	if w.r2Client == nil {
+		return nil, fmt.Errorf("object storage is not configured")
+	}
+	if _, err := w.r2Client.PutObjectStream(ctx, payload.OutputKey, pr, "application/zip"); err != nil {
+		return nil, fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	signedURL, err := w.r2Client.PresignGetURL(ctx, payload.OutputKey, exportURLTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign archive URL: %w", err)
+	}
+
+	// No audio microservice configured to stream from ahead of time, so the
+	// stream URL is just the same presigned archive URL once it exists.
+	return &model.ExportResultResponse{
+		FileURL: signedURL, StreamURL: signedURL, FileCount: len(payload.SourceURLs), ExpiresAt: time.Now().Add(exportURLTTL),
+	}, nil
+}
+
+// addZipEntry downloads url and copies it into zw under name without
+// buffering the whole file.
+func addZipEntry(ctx context.Context, zw *zip.Writer, url, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download of %s returned status %d", name, resp.StatusCode)
+	}
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// addZipEntryContent writes literal content into zw under name, for files
+// assembled in-process rather than fetched from a URL (e.g. a derived
+// lyrics.lrc companion file).
+func addZipEntryContent(zw *zip.Writer, name, content string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(content))
+	return err
+}
+
+// fetchTextURL downloads url and returns its body as a string, for small
+// text payloads (e.g. a synced-lyrics .lrc file) that don't need a temp
+// file on disk the way audio sources do.
+func fetchTextURL(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// resolveLyricsLRC returns payload's synced-lyrics LRC text, fetching
+// LyricsLRCURL if that's how it was supplied instead of raw LyricsLRC text.
+func resolveLyricsLRC(ctx context.Context, payload *model.ExportJobPayload) (string, error) {
+	if payload.LyricsLRC != "" {
+		return payload.LyricsLRC, nil
+	}
+	if payload.LyricsLRCURL == "" {
+		return "", nil
+	}
+	return fetchTextURL(ctx, payload.LyricsLRCURL)
+}
+
+// lrcTimestampPattern matches LRC's [mm:ss.xx] (or [mm:ss]) line tags.
+var lrcTimestampPattern = regexp.MustCompile(`\[\d{2}:\d{2}(?:\.\d{1,3})?\]`)
+
+// stripLRCTimestamps removes LRC timestamp tags, leaving plain lyric text.
+func stripLRCTimestamps(lrc string) string {
+	return strings.TrimSpace(lrcTimestampPattern.ReplaceAllString(lrc, ""))
+}
+
+// syncedLyricsFFmpegArgs resolves payload's synced-lyrics source and, since
+// ffmpeg has no way to write a real ID3v2 SYLT frame, falls back to
+// embedding the plain (unsynced) text as the USLT "lyrics" tag. True synced
+// playback requires routing the export through the audio microservice (see
+// processWithAudioService), which writes USLT+SYLT natively.
+func (w *ExportWorker) syncedLyricsFFmpegArgs(ctx context.Context, payload *model.ExportJobPayload) ([]string, error) {
+	lrc, err := resolveLyricsLRC(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if lrc == "" {
+		return nil, nil
+	}
+	return []string{"-metadata", "lyrics=" + stripLRCTimestamps(lrc)}, nil
+}
+
+// uploadLocalFile streams a local file at path into key and returns its
+// exact size.
+func (w *ExportWorker) uploadLocalFile(ctx context.Context, key, path, contentType string) (int64, error) {
+	if w.r2Client == nil {
+		return 0, fmt.Errorf("object storage is not configured")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat encoded output: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open encoded output: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := w.r2Client.PutObjectStream(ctx, key, f, contentType); err != nil {
+		return 0, fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// resolveOutputURL presigns outputKey through r2Client when storage is
+// configured, falling back to fallbackURL (the audio microservice's own
+// OutputURL) otherwise.
+func (w *ExportWorker) resolveOutputURL(ctx context.Context, outputKey, fallbackURL string) (string, time.Time) {
+	expiresAt := time.Now().Add(exportURLTTL)
+	if w.r2Client == nil {
+		return fallbackURL, expiresAt
+	}
+	signedURL, err := w.r2Client.PresignGetURL(ctx, outputKey, exportURLTTL)
+	if err != nil {
+		return fallbackURL, expiresAt
+	}
+	return signedURL, expiresAt
+}
+
+func (w *ExportWorker) updateProgress(ctx context.Context, jobID string, progress int, step string) {
+	if err := w.runnerClient.Progress(ctx, jobID, progress, step); err != nil {
+		log.Error(ctx, "failed to report progress", "err", err)
+	}
+}
+
+func (w *ExportWorker) failJob(ctx context.Context, jobID, errMsg string) {
+	if err := w.runnerClient.Fail(ctx, jobID, errMsg); err != nil {
+		log.Error(ctx, "failed to report job failure", "err", err)
+	}
+}
+
+// downloadToTempFile downloads url into a new temp file matching pattern
+// and returns its path plus a cleanup func that removes it.
+func downloadToTempFile(ctx context.Context, url, pattern string) (string, func(), error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", nil, err
+	}
+	f.Close()
+
+	return f.Name(), func() { os.Remove(f.Name()) }, nil
+}
+
+// mp3FFmpegArgs maps quality (the API's fixed 128/192/256/320 kbps choices)
+// onto libmp3lame's CBR bitrate flag.
+func mp3FFmpegArgs(quality int) []string {
+	if quality <= 0 {
+		quality = 320
+	}
+	return []string{"-codec:a", "libmp3lame", "-b:a", strconv.Itoa(quality) + "k"}
+}
+
+// wavFFmpegArgs maps bitDepth onto the matching PCM codec and sets the
+// target sample rate.
+func wavFFmpegArgs(bitDepth, sampleRate int) []string {
+	codec := "pcm_s24le"
+	switch bitDepth {
+	case 16:
+		codec = "pcm_s16le"
+	case 32:
+		codec = "pcm_s32le"
+	}
+	if sampleRate <= 0 {
+		sampleRate = 48000
+	}
+	return []string{"-codec:a", codec, "-ar", strconv.Itoa(sampleRate)}
+}
+
+// alacFFmpegArgs encodes Apple Lossless into an MP4 (.m4a) container, the
+// only container ffmpeg's alac encoder supports.
+func alacFFmpegArgs(bitDepth, sampleRate int) []string {
+	sample := "s16p"
+	switch bitDepth {
+	case 24:
+		sample = "s32p" // ffmpeg's alac encoder packs 24-bit audio into s32p
+	case 32:
+		sample = "s32p"
+	}
+	if sampleRate <= 0 {
+		sampleRate = 48000
+	}
+	return []string{"-codec:a", "alac", "-sample_fmt", sample, "-ar", strconv.Itoa(sampleRate)}
+}
+
+// flacFFmpegArgs maps bitDepth onto libFLAC's PCM sample format and passes
+// compressionLevel straight through to -compression_level.
+func flacFFmpegArgs(bitDepth, sampleRate, compressionLevel int) []string {
+	sample := "s24"
+	if bitDepth == 16 {
+		sample = "s16"
+	}
+	if sampleRate <= 0 {
+		sampleRate = 48000
+	}
+	if compressionLevel < 0 || compressionLevel > 8 {
+		compressionLevel = 5
+	}
+	return []string{
+		"-codec:a", "flac",
+		"-sample_fmt", sample,
+		"-ar", strconv.Itoa(sampleRate),
+		"-compression_level", strconv.Itoa(compressionLevel),
+	}
+}
+
+// opusFFmpegArgs maps bitrate/vbr/application/channelLayout onto libopus's
+// flags. vbr selects on vs off (ffmpeg has no separate "constrained" knob
+// exposed here); application and channelLayout fall back to sensible
+// defaults if ExportService somehow passed them through empty.
+func opusFFmpegArgs(bitrate int, vbr bool, application, channelLayout string) []string {
+	if bitrate <= 0 {
+		bitrate = 128
+	}
+	if application == "" {
+		application = "audio"
+	}
+	vbrFlag := "off"
+	if vbr {
+		vbrFlag = "on"
+	}
+	args := []string{"-codec:a", "libopus", "-b:a", strconv.Itoa(bitrate) + "k", "-vbr", vbrFlag, "-application", application}
+	return append(args, channelArgs(channelLayout)...)
+}
+
+// oggFFmpegArgs maps quality/bitrate/vbr/channelLayout onto libvorbis's
+// flags: -q:a (vbr) or -b:a (cbr fallback — libvorbis doesn't encode true
+// CBR, but ffmpeg accepts -b:a as a target-bitrate hint).
+func oggFFmpegArgs(quality, bitrate int, vbr bool, channelLayout string) []string {
+	var args []string
+	if vbr {
+		if quality < -1 || quality > 10 {
+			quality = 6
+		}
+		args = []string{"-codec:a", "libvorbis", "-q:a", strconv.Itoa(quality)}
+	} else {
+		if bitrate <= 0 {
+			bitrate = 192
+		}
+		args = []string{"-codec:a", "libvorbis", "-b:a", strconv.Itoa(bitrate) + "k"}
+	}
+	return append(args, channelArgs(channelLayout)...)
+}
+
+// channelArgs maps the API's mono/stereo channelLayout choice onto ffmpeg's
+// -ac channel count; an empty or unrecognized layout defaults to stereo.
+func channelArgs(channelLayout string) []string {
+	if channelLayout == "mono" {
+		return []string{"-ac", "1"}
+	}
+	return []string{"-ac", "2"}
+}
+
+// metadataFFmpegArgs turns ExportMetadata into generic "-metadata key=value"
+// flags. ffmpeg maps the generic "lyrics" key onto the container's native
+// lyrics tag for us: a USLT frame in an ID3/MP3 stream, or the ©lyr atom in
+// an MP4/ALAC container. embedLyrics gates the lyrics tag specifically,
+// since it's opt-in per ExportMP3Request/ExportALACRequest while the rest of
+// the metadata is written whenever it's present.
+func metadataFFmpegArgs(meta *model.ExportMetadata, embedLyrics bool) []string {
+	if meta == nil {
+		return nil
+	}
+
+	var args []string
+	if meta.Title != "" {
+		args = append(args, "-metadata", "title="+meta.Title)
+	}
+	if meta.Artist != "" {
+		args = append(args, "-metadata", "artist="+meta.Artist)
+	}
+	if meta.Album != "" {
+		args = append(args, "-metadata", "album="+meta.Album)
+	}
+	if meta.Year != nil {
+		args = append(args, "-metadata", fmt.Sprintf("date=%d", *meta.Year))
+	}
+	if embedLyrics && meta.Lyrics != "" {
+		args = append(args, "-metadata", "lyrics="+meta.Lyrics)
+	}
+	return args
+}
+
+func extensionFor(kind model.ExportKind) string {
+	switch kind {
+	case model.ExportKindMP3:
+		return ".mp3"
+	case model.ExportKindWAV:
+		return ".wav"
+	case model.ExportKindALAC:
+		return ".m4a"
+	case model.ExportKindFLAC:
+		return ".flac"
+	case model.ExportKindAtmos:
+		return ".ec3"
+	case model.ExportKindOpus:
+		return ".opus"
+	case model.ExportKindOgg:
+		return ".ogg"
+	default:
+		return ".bin"
+	}
+}
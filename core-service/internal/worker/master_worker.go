@@ -3,37 +3,41 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/audio"
 	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/log"
 	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/runnerapi"
 	"github.com/makeasinger/api/internal/service"
-	"github.com/makeasinger/api/internal/websocket"
-	"github.com/redis/go-redis/v9"
 )
 
-// MasterWorker processes mastering jobs
+// MasterWorker processes mastering jobs. Like RenderWorker, it never writes
+// job state directly — progress, completion, and failure are reported to
+// the API over the runnerapi RPC contract.
 type MasterWorker struct {
 	redis        *redis.Client
 	audioClient  client.AudioProcessor
 	r2Client     client.StorageClient
-	masterService *service.MasterService
-	hub          *websocket.Hub
+	runnerClient *runnerapi.Client
 }
 
 // NewMasterWorker creates a new master worker
-func NewMasterWorker(redisClient *redis.Client, audioClient client.AudioProcessor, r2Client client.StorageClient, masterService *service.MasterService, hub *websocket.Hub) *MasterWorker {
+func NewMasterWorker(redisClient *redis.Client, audioClient client.AudioProcessor, r2Client client.StorageClient, runnerClient *runnerapi.Client) *MasterWorker {
 	return &MasterWorker{
 		redis:        redisClient,
 		audioClient:  audioClient,
 		r2Client:     r2Client,
-		masterService: masterService,
-		hub:          hub,
+		runnerClient: runnerClient,
 	}
 }
 
@@ -49,7 +53,11 @@ func (w *MasterWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
 	}
 
 	jobID := taskPayload.JobID
-	log.Printf("Starting master job: %s", jobID)
+	ctx = log.WithFields(ctx, "jobId", jobID)
+	log.Info(ctx, "starting master job")
+
+	ctx, cancel := service.WatchForCancellation(ctx, w.redis, jobID)
+	defer cancel()
 
 	var payload model.MasterJobPayload
 	if err := json.Unmarshal(taskPayload.Payload, &payload); err != nil {
@@ -59,7 +67,7 @@ func (w *MasterWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
 
 	// Check if audio client is configured
 	if w.audioClient == nil {
-		return w.processWithMock(ctx, jobID, &payload)
+		return w.processNative(ctx, jobID, &payload)
 	}
 
 	return w.processWithAudioService(ctx, jobID, &payload)
@@ -68,17 +76,17 @@ func (w *MasterWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
 // processWithAudioService handles real mastering using the Python microservice
 func (w *MasterWorker) processWithAudioService(ctx context.Context, jobID string, payload *model.MasterJobPayload) error {
 	// Step 1: Update status
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 5, "Preparing stems...")
+	w.updateProgress(ctx, jobID, 5, "Preparing stems...")
 
 	// Step 2: Build mix settings from payload
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 10, "Building mix settings...")
+	w.updateProgress(ctx, jobID, 10, "Building mix settings...")
 	mixSettings := w.buildMixSettings(payload)
 
 	// Step 3: Build vocal takes if present
 	vocalTakes := w.buildVocalTakes(payload)
 
 	// Step 4: Call audio service for mastering
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 20, "Starting mastering process...")
+	w.updateProgress(ctx, jobID, 20, "Starting mastering process...")
 
 	outputKey := fmt.Sprintf("masters/%s/%s.wav", payload.ProjectID, uuid.New().String())
 
@@ -91,16 +99,29 @@ func (w *MasterWorker) processWithAudioService(ctx context.Context, jobID string
 	}
 
 	// Step 5: Wait for mastering to complete (with progress updates)
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 40, "Applying EQ and compression...")
+	w.updateProgress(ctx, jobID, 40, "Applying EQ and compression...")
 
-	masterResp, err := w.audioClient.Master(ctx, masterReq)
+	masterResp, err := w.audioClient.Master(ctx, jobID, masterReq)
 	if err != nil {
-		w.failJob(ctx, jobID, fmt.Sprintf("Mastering failed: %v", err))
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// The job was canceled out from under us (status already flipped
+			// to Canceled by RenderService.CancelRender) — just stop the
+			// audio service from continuing to burn CPU on it.
+			w.audioClient.CancelRemoteJob(context.Background(), jobID)
+			log.Info(ctx, "master job canceled")
+			return ctx.Err()
+		}
+		var structErr *client.StructuredError
+		if errors.As(err, &structErr) {
+			w.failJobStructured(ctx, jobID, structErr)
+		} else {
+			w.failJob(ctx, jobID, fmt.Sprintf("Mastering failed: %v", err))
+		}
 		return err
 	}
 
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 80, "Applying limiter...")
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 95, "Finalizing...")
+	w.updateProgress(ctx, jobID, 80, "Applying limiter...")
+	w.updateProgress(ctx, jobID, 95, "Finalizing...")
 
 	// Step 6: Generate result
 	result := &model.MasterResultResponse{
@@ -112,53 +133,156 @@ func (w *MasterWorker) processWithAudioService(ctx context.Context, jobID string
 		ExpiresAt: time.Now().Add(24 * time.Hour),
 	}
 
-	// Complete the job
-	w.completeJob(ctx, jobID, result)
-	w.hub.BroadcastComplete(jobID, result)
+	// Complete the job. Archiving the output to cold storage happens on the
+	// API side once the result is saved.
+	if err := w.runnerClient.Complete(ctx, jobID, result, []string{outputKey}); err != nil {
+		w.failJob(ctx, jobID, "Failed to save result")
+		return err
+	}
 
-	log.Printf("Master job %s completed", jobID)
+	log.Info(ctx, "master job completed")
 	return nil
 }
 
-// processWithMock handles mastering with mock data for development
-func (w *MasterWorker) processWithMock(ctx context.Context, jobID string, payload *model.MasterJobPayload) error {
-	w.updateJobStatus(ctx, jobID, model.JobStatusRunning, 0, "Starting mastering...")
-
-	steps := []struct {
-		progress int
-		step     string
-		duration time.Duration
-	}{
-		{10, "Loading stems...", 2 * time.Second},
-		{25, "Applying EQ...", 3 * time.Second},
-		{40, "Applying compression...", 3 * time.Second},
-		{55, "Stereo widening...", 2 * time.Second},
-		{70, "Applying limiter...", 2 * time.Second},
-		{85, "Final adjustments...", 2 * time.Second},
-		{95, "Rendering output...", 3 * time.Second},
-	}
-
-	for _, step := range steps {
-		select {
-		case <-ctx.Done():
-			log.Printf("Master job %s cancelled", jobID)
+// processNative masters the stems in-process with internal/audio — EQ,
+// compression, BS.1770-4 loudness measurement, and true-peak limiting —
+// when no audio microservice is configured, instead of the sleep-based mock
+// this path used to run. Loudness targeting is linear gain-iteration against
+// a BS.1770-4 meter rather than ffmpeg's two-pass loudnorm filter: that
+// engine (and its EQ/compressor/limiter stages) was built out in
+// chunk3-3 specifically so this path wouldn't have to shell out per
+// mastering stage, so progress here reports audio.Run's real stage
+// completions rather than reintroducing an ffmpeg loudnorm pipeline
+// alongside it.
+func (w *MasterWorker) processNative(ctx context.Context, jobID string, payload *model.MasterJobPayload) error {
+	w.updateProgress(ctx, jobID, 0, "Starting mastering...")
+
+	w.updateProgress(ctx, jobID, 10, "Loading stems...")
+	stems, cleanup, err := w.downloadStems(ctx, payload)
+	if err != nil {
+		w.failJob(ctx, jobID, fmt.Sprintf("Failed to prepare stems: %v", err))
+		return err
+	}
+	defer cleanup()
+
+	out, err := os.CreateTemp("", "master-out-*.wav")
+	if err != nil {
+		w.failJob(ctx, jobID, "Failed to allocate output file")
+		return err
+	}
+	out.Close()
+	defer os.Remove(out.Name())
+
+	onProgress := func(percent int, step string) {
+		w.updateProgress(ctx, jobID, percent, step)
+	}
+	mastered, err := audio.Run(ctx, stems, string(payload.Profile), out.Name(), onProgress)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			log.Info(ctx, "master job canceled")
 			return ctx.Err()
-		default:
 		}
+		w.failJob(ctx, jobID, fmt.Sprintf("Mastering failed: %v", err))
+		return err
+	}
+
+	outputKey := fmt.Sprintf("masters/%s/%s.wav", payload.ProjectID, uuid.New().String())
+	fileURL, err := w.uploadMasterFile(ctx, outputKey, mastered.OutPath)
+	if err != nil {
+		w.failJob(ctx, jobID, fmt.Sprintf("Failed to upload master: %v", err))
+		return err
+	}
+	w.updateProgress(ctx, jobID, 95, "Finalizing...")
 
-		w.updateJobStatus(ctx, jobID, model.JobStatusRunning, step.progress, step.step)
-		w.hub.BroadcastProgress(jobID, step.progress, model.JobStatusRunning, step.step)
-		time.Sleep(step.duration)
+	result := &model.MasterResultResponse{
+		FileURL:   fileURL,
+		Duration:  mastered.Duration,
+		Profile:   payload.Profile,
+		PeakDb:    mastered.PeakDb,
+		LUFS:      int(math.Round(mastered.LUFS)),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
 	}
 
-	result := w.generateMockResult(payload)
-	w.completeJob(ctx, jobID, result)
-	w.hub.BroadcastComplete(jobID, result)
+	if err := w.runnerClient.Complete(ctx, jobID, result, []string{outputKey}); err != nil {
+		w.failJob(ctx, jobID, "Failed to save result")
+		return err
+	}
 
-	log.Printf("Master job %s completed (mock)", jobID)
+	log.Info(ctx, "master job completed (native)")
 	return nil
 }
 
+// downloadStems fetches every stem and vocal take referenced by payload to
+// local temp files, matching stem i to mix channel i's gain/mute/solo as
+// buildMixSettings does for the audio-service path. Unlike buildMixSettings,
+// which drops stems past the last channel when the snapshot has any
+// channels, this keeps them at unity gain.
+func (w *MasterWorker) downloadStems(ctx context.Context, payload *model.MasterJobPayload) ([]audio.StemInput, func(), error) {
+	var (
+		inputs   []audio.StemInput
+		cleanups []func()
+	)
+	cleanupAll := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+
+	for i, url := range payload.StemURLs {
+		path, cleanup, err := downloadToTempFile(ctx, url, "master-stem-*")
+		if err != nil {
+			cleanupAll()
+			return nil, nil, err
+		}
+		cleanups = append(cleanups, cleanup)
+
+		gain, mute, solo := 1.0, false, false
+		if i < len(payload.MixSnapshot.Channels) {
+			ch := payload.MixSnapshot.Channels[i]
+			gain, mute, solo = dbToLinear(ch.VolumeDb), ch.Mute, ch.Solo
+		}
+		inputs = append(inputs, audio.StemInput{Path: path, Gain: gain, Mute: mute, Solo: solo})
+	}
+
+	for _, take := range payload.VocalTakes {
+		path, cleanup, err := downloadToTempFile(ctx, take.FileURL, "master-vocal-*")
+		if err != nil {
+			cleanupAll()
+			return nil, nil, err
+		}
+		cleanups = append(cleanups, cleanup)
+		inputs = append(inputs, audio.StemInput{Path: path, Gain: 1.0})
+	}
+
+	return inputs, cleanupAll, nil
+}
+
+// uploadMasterFile streams the locally-mastered file at path into key and
+// returns a URL for it — a presigned GET URL when object storage is
+// configured, or a placeholder CDN URL otherwise (mirroring the prior
+// mock's fallback for local development without R2 configured).
+func (w *MasterWorker) uploadMasterFile(ctx context.Context, key, path string) (string, error) {
+	if w.r2Client == nil {
+		return fmt.Sprintf("https://cdn.makeasinger.com/%s", key), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open mastered output: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := w.r2Client.PutObjectStream(ctx, key, f, "audio/wav"); err != nil {
+		return "", fmt.Errorf("failed to upload master: %w", err)
+	}
+
+	signedURL, err := w.r2Client.PresignGetURL(ctx, key, 24*time.Hour)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign master URL: %w", err)
+	}
+	return signedURL, nil
+}
+
 func (w *MasterWorker) buildMixSettings(payload *model.MasterJobPayload) []client.MixChannel {
 	var settings []client.MixChannel
 
@@ -215,89 +339,32 @@ func (w *MasterWorker) buildVocalTakes(payload *model.MasterJobPayload) []client
 	return takes
 }
 
-func (w *MasterWorker) updateJobStatus(ctx context.Context, jobID string, status model.JobStatus, progress int, step string) {
-	job, err := w.getJob(ctx, jobID)
-	if err != nil {
-		log.Printf("Failed to get job: %v", err)
-		return
-	}
-
-	job.Status = status
-	job.Progress = progress
-	job.CurrentStep = step
-
-	if status == model.JobStatusRunning && job.StartedAt == nil {
-		now := time.Now()
-		job.StartedAt = &now
+func (w *MasterWorker) updateProgress(ctx context.Context, jobID string, progress int, step string) {
+	if err := w.runnerClient.Progress(ctx, jobID, progress, step); err != nil {
+		log.Error(ctx, "failed to report progress", "err", err)
 	}
-
-	w.saveJob(ctx, job)
-	w.hub.BroadcastProgress(jobID, progress, status, step)
-}
-
-func (w *MasterWorker) completeJob(ctx context.Context, jobID string, result *model.MasterResultResponse) {
-	job, err := w.getJob(ctx, jobID)
-	if err != nil {
-		log.Printf("Failed to get job: %v", err)
-		return
-	}
-
-	resultBytes, _ := json.Marshal(result)
-	job.Status = model.JobStatusSucceeded
-	job.Progress = 100
-	job.Result = resultBytes
-	now := time.Now()
-	job.CompletedAt = &now
-
-	w.saveJob(ctx, job)
 }
 
 func (w *MasterWorker) failJob(ctx context.Context, jobID, errMsg string) {
-	job, err := w.getJob(ctx, jobID)
-	if err != nil {
-		log.Printf("Failed to get job: %v", err)
-		return
+	if err := w.runnerClient.Fail(ctx, jobID, errMsg); err != nil {
+		log.Error(ctx, "failed to report job failure", "err", err)
 	}
-
-	job.Status = model.JobStatusFailed
-	job.Error = &errMsg
-	now := time.Now()
-	job.CompletedAt = &now
-
-	w.saveJob(ctx, job)
-	w.hub.BroadcastError(jobID, "MASTER_FAILED", errMsg)
 }
 
-func (w *MasterWorker) getJob(ctx context.Context, jobID string) (*model.Job, error) {
-	data, err := w.redis.Get(ctx, fmt.Sprintf("job:%s", jobID)).Bytes()
-	if err != nil {
-		return nil, err
-	}
-
-	var job model.Job
-	if err := json.Unmarshal(data, &job); err != nil {
-		return nil, err
+// failJobStructured reports a failure with per-item detail, e.g. when the
+// audio service reports that only some stems could be mastered.
+func (w *MasterWorker) failJobStructured(ctx context.Context, jobID string, structErr *client.StructuredError) {
+	items := make([]model.ItemFailure, len(structErr.Items))
+	for i, it := range structErr.Items {
+		items[i] = model.ItemFailure{Index: it.Index, Ref: it.Ref, Reason: it.Reason}
 	}
-
-	return &job, nil
-}
-
-func (w *MasterWorker) saveJob(ctx context.Context, job *model.Job) {
-	data, err := json.Marshal(job)
-	if err != nil {
-		log.Printf("Failed to marshal job: %v", err)
-		return
+	jobStructErr := &model.JobStructuredError{
+		Code:    structErr.Code,
+		Message: structErr.Message,
+		Items:   items,
 	}
-	w.redis.Set(ctx, fmt.Sprintf("job:%s", job.ID), data, 24*time.Hour)
-}
 
-func (w *MasterWorker) generateMockResult(payload *model.MasterJobPayload) *model.MasterResultResponse {
-	return &model.MasterResultResponse{
-		FileURL:   fmt.Sprintf("https://cdn.makeasinger.com/masters/%s.wav", uuid.New().String()),
-		Duration:  180.5,
-		Profile:   payload.Profile,
-		PeakDb:    -0.3,
-		LUFS:      -14,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+	if err := w.runnerClient.FailStructured(ctx, jobID, jobStructErr); err != nil {
+		log.Error(ctx, "failed to report job failure", "err", err)
 	}
 }
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// taskDuration/taskFailuresTotal are labeled by task type (render:process,
+// master:process, export:process) rather than job ID, for the same
+// bounded-cardinality reason as renderStepDuration in render_worker.go.
+var (
+	taskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "asynq_task_duration_seconds",
+		Help:    "Duration of ProcessTask, by task type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"task_type"})
+	taskFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "asynq_task_failures_total",
+		Help: "Total ProcessTask calls that returned an error, by task type.",
+	}, []string{"task_type"})
+)
+
+// MetricsMiddleware is an asynq.MiddlewareFunc that times every task this
+// runner processes and counts failures, regardless of which worker handles
+// it. Register it on the asynq.ServeMux ahead of the type-specific
+// handlers: mux.Use(worker.MetricsMiddleware()).
+func MetricsMiddleware() asynq.MiddlewareFunc {
+	return func(h asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+			start := time.Now()
+			err := h.ProcessTask(ctx, task)
+			taskDuration.WithLabelValues(task.Type()).Observe(time.Since(start).Seconds())
+			if err != nil {
+				taskFailuresTotal.WithLabelValues(task.Type()).Inc()
+			}
+			return err
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/makeasinger/api/internal/model"
+)
+
+// RenderEngine actually produces the audio for a render job. RenderWorker
+// resolves one per process (based on whether a Suno API key is configured)
+// and drives every job through it, so ProcessTask itself doesn't branch on
+// what's configured.
+type RenderEngine interface {
+	// Render runs the full pipeline for payload and reports progress,
+	// completion, and failure on jobID through the same runnerClient calls
+	// RenderWorker itself would make. A nil return means the job reached a
+	// terminal state (completed or, for a cancellation, already reported as
+	// such) and should not be retried by asynq.
+	Render(ctx context.Context, jobID string, payload *model.RenderJobPayload) error
+}
+
+// sunoEngine generates real audio through the Suno API: prompt, music
+// generation, stem splitting, then upload -- resuming from a checkpoint on
+// retry. See RenderWorker.processWithSuno.
+type sunoEngine struct {
+	w *RenderWorker
+}
+
+func (e *sunoEngine) Render(ctx context.Context, jobID string, payload *model.RenderJobPayload) error {
+	err := e.w.processWithSuno(ctx, jobID, payload)
+	if err != nil && errors.Is(err, errRenderCancelled) {
+		// Already terminal: CancelRender saved the Canceled status before
+		// flagging it. Returning nil tells asynq the task is done, not
+		// failed, so it isn't retried into resuming a dead job.
+		return nil
+	}
+	return err
+}
+
+// mockEngine fabricates a result on a fixed timeline, for local development
+// when no Suno API key is configured. See RenderWorker.processWithMock.
+type mockEngine struct {
+	w *RenderWorker
+}
+
+func (e *mockEngine) Render(ctx context.Context, jobID string, payload *model.RenderJobPayload) error {
+	return e.w.processWithMock(ctx, jobID, payload)
+}
@@ -3,34 +3,77 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/makeasinger/api/internal/audio"
 	"github.com/makeasinger/api/internal/client"
+	"github.com/makeasinger/api/internal/log"
 	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/runnerapi"
 	"github.com/makeasinger/api/internal/service"
-	"github.com/makeasinger/api/internal/websocket"
 )
 
-// RenderWorker processes render jobs
+// stemWaveformBuckets is how many peak-bucketed points RenderWorker computes
+// per stem for the frontend's waveform display.
+const stemWaveformBuckets = 100
+
+// renderStepDuration tracks how long each named pipeline step takes, by
+// step, so a slowdown in e.g. stem splitting shows up on a dashboard
+// instead of only as a slower overall job. Labeled by step name rather than
+// job/project ID: those are per-job identifiers with unbounded cardinality,
+// which Prometheus counters/histograms aren't meant to carry -- per-job
+// timing is already visible via structured logs and the job's own
+// progress/status record.
+var renderStepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "render_step_duration_seconds",
+	Help:    "Duration of each named render pipeline step.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"step"})
+
+// stemURLTTL is how long a stem's presigned download URL stays valid once
+// it's been uploaded to object storage.
+const stemURLTTL = 24 * time.Hour
+
+// RenderWorker processes render jobs. It never touches job state directly —
+// progress, completion, and failure are reported to the API over the
+// runnerapi RPC contract, which owns the job record and fans updates out to
+// WebSocket clients.
 type RenderWorker struct {
-	renderService *service.RenderService
-	sunoClient    *client.SunoClient
-	r2Client      client.StorageClient
-	hub           *websocket.Hub
+	redis        *redis.Client
+	sunoClient   *client.SunoClient
+	r2Client     client.StorageClient
+	runnerClient *runnerapi.Client
+	engine       RenderEngine
 }
 
-// NewRenderWorker creates a new render worker
-func NewRenderWorker(renderService *service.RenderService, sunoClient *client.SunoClient, r2Client client.StorageClient, hub *websocket.Hub) *RenderWorker {
-	return &RenderWorker{
-		renderService: renderService,
-		sunoClient:    sunoClient,
-		r2Client:      r2Client,
-		hub:           hub,
+// NewRenderWorker creates a new render worker. It resolves its RenderEngine
+// once here -- sunoEngine when a Suno API key is configured, mockEngine
+// otherwise -- rather than re-checking sunoClient.IsConfigured() on every
+// job.
+func NewRenderWorker(redisClient *redis.Client, sunoClient *client.SunoClient, r2Client client.StorageClient, runnerClient *runnerapi.Client) *RenderWorker {
+	w := &RenderWorker{
+		redis:        redisClient,
+		sunoClient:   sunoClient,
+		r2Client:     r2Client,
+		runnerClient: runnerClient,
+	}
+
+	if sunoClient != nil && sunoClient.IsConfigured() {
+		w.engine = &sunoEngine{w: w}
+	} else {
+		w.engine = &mockEngine{w: w}
 	}
+
+	return w
 }
 
 // ProcessTask handles render task processing
@@ -45,7 +88,11 @@ func (w *RenderWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
 	}
 
 	jobID := taskPayload.JobID
-	log.Printf("Starting render job: %s", jobID)
+	ctx = log.WithFields(ctx, "jobId", jobID)
+	log.Info(ctx, "starting render job")
+
+	ctx, cancel := service.WatchForCancellation(ctx, w.redis, jobID)
+	defer cancel()
 
 	var payload model.RenderJobPayload
 	if err := json.Unmarshal(taskPayload.Payload, &payload); err != nil {
@@ -53,119 +100,226 @@ func (w *RenderWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
 		return fmt.Errorf("failed to unmarshal render payload: %w", err)
 	}
 
-	// Check if Suno client is configured
-	if w.sunoClient == nil || !w.sunoClient.IsConfigured() {
-		return w.processWithMock(ctx, jobID, &payload)
-	}
-
-	return w.processWithSuno(ctx, jobID, &payload)
+	return w.engine.Render(ctx, jobID, &payload)
 }
 
-// processWithSuno handles real rendering using Suno API
+// errRenderCancelled is returned internally when a cancellation flag is
+// observed between pipeline steps. sunoEngine.Render translates it into a nil
+// return (no asynq retry: the job is already terminal, CancelRender already
+// saved the Canceled status) rather than surfacing it as a task failure.
+var errRenderCancelled = errors.New("render job cancelled")
+
+// processWithSuno handles real rendering using Suno API. Each step that
+// costs real Suno usage records a checkpoint before moving on, so a retried
+// task (network blip, runner restart) resumes from the last completed step
+// instead of re-running the whole pipeline and re-charging the account.
 func (w *RenderWorker) processWithSuno(ctx context.Context, jobID string, payload *model.RenderJobPayload) error {
+	cp, err := service.GetCheckpoint(ctx, w.redis, jobID)
+	if err != nil {
+		log.Error(ctx, "failed to load checkpoint, starting from scratch", "err", err)
+		cp = &service.RenderCheckpoint{}
+	}
+
 	// Step 1: Build prompt from brief
+	stepStart := time.Now()
 	w.updateProgress(ctx, jobID, 5, "Building music prompt...")
 	prompt := w.buildMusicPrompt(payload)
+	renderStepDuration.WithLabelValues("build_prompt").Observe(time.Since(stepStart).Seconds())
 
-	// Step 2: Generate music via Suno
+	if err := w.checkCancelled(ctx, jobID); err != nil {
+		return err
+	}
+
+	// Step 2: Generate music via Suno (skipped on resume)
+	stepStart = time.Now()
 	w.updateProgress(ctx, jobID, 10, "Generating music...")
-	musicReq := &client.GenerateMusicRequest{
-		Prompt:           prompt,
-		Style:            string(payload.Brief.Genre),
-		MakeInstrumental: true,
+	if cp.SunoMusicTaskID == "" {
+		musicReq := &client.GenerateMusicRequest{
+			Prompt:           prompt,
+			Style:            string(payload.Brief.Genre),
+			MakeInstrumental: true,
+		}
+
+		musicResp, err := w.sunoClient.GenerateMusic(ctx, musicReq)
+		if err != nil {
+			w.failJob(ctx, jobID, fmt.Sprintf("Music generation failed: %v", err))
+			return err
+		}
+		cp.SunoMusicTaskID = musicResp.TaskID
+		cp.Stage = "music_queued"
+		w.saveCheckpoint(ctx, jobID, cp)
 	}
+	renderStepDuration.WithLabelValues("generate_music").Observe(time.Since(stepStart).Seconds())
 
-	musicResp, err := w.sunoClient.GenerateMusic(ctx, musicReq)
-	if err != nil {
-		w.failJob(ctx, jobID, fmt.Sprintf("Music generation failed: %v", err))
+	if err := w.checkCancelled(ctx, jobID); err != nil {
 		return err
 	}
 
 	// Step 3: Poll for music completion
+	stepStart = time.Now()
 	w.updateProgress(ctx, jobID, 30, "Waiting for music generation...")
-	musicResult, err := w.sunoClient.PollMusicStatus(ctx, musicResp.TaskID, 5*time.Second, 10*time.Minute)
+	musicResult, err := w.sunoClient.PollMusicStatus(ctx, cp.SunoMusicTaskID, 10*time.Minute, func(step string) {
+		w.updateProgress(ctx, jobID, 30, step)
+	})
 	if err != nil {
 		w.failJob(ctx, jobID, fmt.Sprintf("Music generation timed out: %v", err))
 		return err
 	}
+	renderStepDuration.WithLabelValues("poll_music").Observe(time.Since(stepStart).Seconds())
+	if cp.Stage == "music_queued" {
+		cp.Stage = "music_done"
+		w.saveCheckpoint(ctx, jobID, cp)
+	}
+
+	if err := w.checkCancelled(ctx, jobID); err != nil {
+		return err
+	}
 
-	// Step 4: Split stems
+	// Step 4: Split stems (skipped on resume)
+	stepStart = time.Now()
 	w.updateProgress(ctx, jobID, 60, "Splitting stems...")
-	stemResp, err := w.sunoClient.SplitStems(ctx, musicResult.AudioURL)
-	if err != nil {
-		w.failJob(ctx, jobID, fmt.Sprintf("Stem splitting failed: %v", err))
+	if cp.SunoStemTaskID == "" {
+		stemResp, err := w.sunoClient.SplitStems(ctx, musicResult.AudioURL)
+		if err != nil {
+			w.failJob(ctx, jobID, fmt.Sprintf("Stem splitting failed: %v", err))
+			return err
+		}
+		cp.SunoStemTaskID = stemResp.TaskID
+		cp.Stage = "stems_queued"
+		w.saveCheckpoint(ctx, jobID, cp)
+	}
+	renderStepDuration.WithLabelValues("split_stems").Observe(time.Since(stepStart).Seconds())
+
+	if err := w.checkCancelled(ctx, jobID); err != nil {
 		return err
 	}
 
 	// Step 5: Poll for stem split completion
+	stepStart = time.Now()
 	w.updateProgress(ctx, jobID, 75, "Waiting for stem separation...")
-	stemResult, err := w.sunoClient.PollStemSplitStatus(ctx, stemResp.TaskID, 5*time.Second, 5*time.Minute)
+	stemResult, err := w.sunoClient.PollStemSplitStatus(ctx, cp.SunoStemTaskID, 5*time.Minute, func(step string) {
+		w.updateProgress(ctx, jobID, 75, step)
+	})
 	if err != nil {
 		w.failJob(ctx, jobID, fmt.Sprintf("Stem splitting timed out: %v", err))
 		return err
 	}
+	renderStepDuration.WithLabelValues("poll_stems").Observe(time.Since(stepStart).Seconds())
+	if cp.Stage == "stems_queued" {
+		cp.Stage = "stems_done"
+		w.saveCheckpoint(ctx, jobID, cp)
+	}
+
+	if err := w.checkCancelled(ctx, jobID); err != nil {
+		return err
+	}
 
-	// Step 6: Upload stems to R2
+	// Step 6: Upload stems to R2 (already-uploaded stems are re-presigned,
+	// not re-downloaded, on resume)
+	stepStart = time.Now()
 	w.updateProgress(ctx, jobID, 90, "Uploading stems...")
-	stems, err := w.uploadStems(ctx, payload.ProjectID, stemResult.Stems)
+	stems, err := w.uploadStems(ctx, jobID, payload.ProjectID, stemResult.Stems, cp)
 	if err != nil {
 		w.failJob(ctx, jobID, fmt.Sprintf("Stem upload failed: %v", err))
 		return err
 	}
+	renderStepDuration.WithLabelValues("upload_stems").Observe(time.Since(stepStart).Seconds())
+	cp.Stage = "stems_uploaded"
+	w.saveCheckpoint(ctx, jobID, cp)
 
 	// Step 7: Generate result
+	stepStart = time.Now()
 	w.updateProgress(ctx, jobID, 95, "Finalizing...")
 	result := w.generateResult(payload, musicResult, stems)
+	renderStepDuration.WithLabelValues("finalize").Observe(time.Since(stepStart).Seconds())
+
+	// Complete the job. Archiving the stems to cold storage happens on the
+	// API side once the result is saved; we just tell it which keys to copy.
+	var archiveKeys []string
+	if w.r2Client != nil {
+		archiveKeys = make([]string, 0, len(stems))
+		for _, stem := range stems {
+			archiveKeys = append(archiveKeys, w.r2Client.KeyFromURL(stem.FileURL))
+		}
+	}
 
-	// Complete the job
-	if err := w.renderService.CompleteJob(ctx, jobID, result); err != nil {
+	if err := w.runnerClient.Complete(ctx, jobID, result, archiveKeys); err != nil {
 		w.failJob(ctx, jobID, "Failed to save result")
 		return err
 	}
 
-	w.hub.BroadcastComplete(jobID, result)
-	log.Printf("Render job %s completed", jobID)
+	log.Info(ctx, "render job completed")
+	return nil
+}
+
+// checkCancelled returns errRenderCancelled if jobID has been flagged for
+// cancellation since the pipeline started. It's checked between steps in
+// addition to the ctx.Done() cancellation WatchForCancellation wires up,
+// because the durable flag (unlike the pub/sub notice) still catches a
+// cancellation published while a checkpoint-resumed retry wasn't yet
+// subscribed to hear it.
+func (w *RenderWorker) checkCancelled(ctx context.Context, jobID string) error {
+	cancelled, err := service.IsCancelled(ctx, w.redis, jobID)
+	if err != nil {
+		log.Error(ctx, "failed to check cancellation flag", "err", err)
+		return nil
+	}
+	if cancelled {
+		log.Info(ctx, "render job cancelled")
+		return errRenderCancelled
+	}
 	return nil
 }
 
+// saveCheckpoint persists cp, logging rather than failing the step on a
+// Redis error: losing a checkpoint only costs a redundant Suno call on
+// retry, not correctness.
+func (w *RenderWorker) saveCheckpoint(ctx context.Context, jobID string, cp *service.RenderCheckpoint) {
+	if err := service.SaveCheckpoint(ctx, w.redis, jobID, cp); err != nil {
+		log.Error(ctx, "failed to save render checkpoint", "err", err)
+	}
+}
+
 // processWithMock handles rendering with mock data for development
 func (w *RenderWorker) processWithMock(ctx context.Context, jobID string, payload *model.RenderJobPayload) error {
 	steps := []struct {
 		progress int
 		step     string
+		metric   string
 		duration time.Duration
 	}{
-		{10, "Analyzing song structure...", 2 * time.Second},
-		{20, "Generating drums track...", 3 * time.Second},
-		{35, "Generating bass track...", 3 * time.Second},
-		{50, "Generating piano track...", 3 * time.Second},
-		{65, "Generating guitar track...", 3 * time.Second},
-		{80, "Generating synth track...", 3 * time.Second},
-		{90, "Mixing stems...", 2 * time.Second},
-		{95, "Finalizing...", 1 * time.Second},
+		{10, "Analyzing song structure...", "analyze", 2 * time.Second},
+		{20, "Generating drums track...", "generate_drums", 3 * time.Second},
+		{35, "Generating bass track...", "generate_bass", 3 * time.Second},
+		{50, "Generating piano track...", "generate_piano", 3 * time.Second},
+		{65, "Generating guitar track...", "generate_guitar", 3 * time.Second},
+		{80, "Generating synth track...", "generate_synth", 3 * time.Second},
+		{90, "Mixing stems...", "mix", 2 * time.Second},
+		{95, "Finalizing...", "finalize", 1 * time.Second},
 	}
 
 	for _, step := range steps {
 		select {
 		case <-ctx.Done():
-			log.Printf("Render job %s cancelled", jobID)
+			log.Info(ctx, "render job cancelled")
 			return ctx.Err()
 		default:
 		}
 
+		stepStart := time.Now()
 		w.updateProgress(ctx, jobID, step.progress, step.step)
 		time.Sleep(step.duration)
+		renderStepDuration.WithLabelValues(step.metric).Observe(time.Since(stepStart).Seconds())
 	}
 
 	result := w.generateMockResult(payload)
 
-	if err := w.renderService.CompleteJob(ctx, jobID, result); err != nil {
+	if err := w.runnerClient.Complete(ctx, jobID, result, nil); err != nil {
 		w.failJob(ctx, jobID, "Failed to save result")
 		return err
 	}
 
-	w.hub.BroadcastComplete(jobID, result)
-	log.Printf("Render job %s completed (mock)", jobID)
+	log.Info(ctx, "render job completed (mock)")
 	return nil
 }
 
@@ -189,19 +343,45 @@ func (w *RenderWorker) buildMusicPrompt(payload *model.RenderJobPayload) string
 	)
 }
 
-func (w *RenderWorker) uploadStems(ctx context.Context, projectID string, stems []client.Stem) ([]model.StemResult, error) {
+// uploadStems uploads each stem to object storage, or re-presigns it without
+// re-downloading/re-uploading if cp already recorded it as uploaded by a
+// prior attempt at this same job.
+func (w *RenderWorker) uploadStems(ctx context.Context, jobID, projectID string, stems []client.Stem, cp *service.RenderCheckpoint) ([]model.StemResult, error) {
 	var results []model.StemResult
+	if cp.UploadedStemKeys == nil {
+		cp.UploadedStemKeys = make(map[string]string)
+	}
 
 	for _, stem := range stems {
 		stemID := uuid.New().String()
 
-		// If R2 client is available, we could download from Suno and re-upload to R2
-		// For now, we'll use the Suno URLs directly
+		// Without an R2 client (dev/unconfigured), fall back to the Suno
+		// URL directly rather than failing the whole render.
 		fileURL := stem.URL
+		waveform := make([]float64, stemWaveformBuckets)
 		if w.r2Client != nil {
-			// In a real implementation, download from stem.URL and upload to R2
-			key := fmt.Sprintf("stems/%s/%s.wav", projectID, stemID)
-			fileURL = w.r2Client.GetPublicURL(key)
+			if key, done := cp.UploadedStemKeys[stem.Name]; done {
+				signedURL, err := w.r2Client.PresignGetURL(ctx, key, stemURLTTL)
+				if err != nil {
+					log.Error(ctx, "failed to re-presign already-uploaded stem, falling back to Suno URL", "err", err, "key", key)
+				} else {
+					fileURL = signedURL
+				}
+				// The waveform isn't part of the checkpoint, so a resumed
+				// retry that hits this branch re-presigns a flat waveform
+				// rather than re-downloading the stem solely to re-plot it.
+			} else {
+				key := fmt.Sprintf("stems/%s/%s.wav", projectID, stemID)
+				signedURL, wf, err := w.downloadAndStoreStem(ctx, stem.URL, key)
+				if err != nil {
+					log.Error(ctx, "failed to store stem in object storage, falling back to Suno URL", "err", err, "stemURL", stem.URL)
+				} else {
+					fileURL = signedURL
+					waveform = wf
+					cp.UploadedStemKeys[stem.Name] = key
+					w.saveCheckpoint(ctx, jobID, cp)
+				}
+			}
 		}
 
 		results = append(results, model.StemResult{
@@ -209,13 +389,49 @@ func (w *RenderWorker) uploadStems(ctx context.Context, projectID string, stems
 			Instrument:   model.Instrument(stem.Name),
 			FileURL:      fileURL,
 			Duration:     stem.Duration,
-			WaveformData: generateWaveform(100),
+			WaveformData: waveform,
 		})
 	}
 
 	return results, nil
 }
 
+// downloadAndStoreStem downloads stemURL to a temp file, uploads it to
+// object storage at key, and computes a real peak-bucketed waveform from
+// the decoded audio -- unlike a direct body-to-upload pipe, this costs a
+// local temp file per stem, but it's what lets WaveformData reflect the
+// actual rendered audio instead of a placeholder.
+func (w *RenderWorker) downloadAndStoreStem(ctx context.Context, stemURL, key string) (string, []float64, error) {
+	path, cleanup, err := downloadToTempFile(ctx, stemURL, "render-stem-*.wav")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download stem: %w", err)
+	}
+	defer cleanup()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open downloaded stem: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := w.r2Client.PutObjectStream(ctx, key, f, "audio/wav"); err != nil {
+		return "", nil, fmt.Errorf("failed to upload stem: %w", err)
+	}
+
+	signedURL, err := w.r2Client.PresignGetURL(ctx, key, stemURLTTL)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to presign stem URL: %w", err)
+	}
+
+	waveform, err := audio.Waveform(ctx, path, stemWaveformBuckets)
+	if err != nil {
+		log.Error(ctx, "failed to compute stem waveform", "err", err, "key", key)
+		waveform = make([]float64, stemWaveformBuckets)
+	}
+
+	return signedURL, waveform, nil
+}
+
 func (w *RenderWorker) generateResult(payload *model.RenderJobPayload, musicResult *client.MusicResult, stems []model.StemResult) *model.RenderResultResponse {
 	tonic := model.TonicC
 	scale := model.ScaleMajor
@@ -242,17 +458,15 @@ func (w *RenderWorker) generateResult(payload *model.RenderJobPayload, musicResu
 }
 
 func (w *RenderWorker) updateProgress(ctx context.Context, jobID string, progress int, step string) {
-	if err := w.renderService.UpdateJobProgress(ctx, jobID, progress, step); err != nil {
-		log.Printf("Failed to update progress: %v", err)
+	if err := w.runnerClient.Progress(ctx, jobID, progress, step); err != nil {
+		log.Error(ctx, "failed to report progress", "err", err)
 	}
-	w.hub.BroadcastProgress(jobID, progress, model.JobStatusRunning, step)
 }
 
 func (w *RenderWorker) failJob(ctx context.Context, jobID, errMsg string) {
-	if err := w.renderService.FailJob(ctx, jobID, errMsg); err != nil {
-		log.Printf("Failed to mark job as failed: %v", err)
+	if err := w.runnerClient.Fail(ctx, jobID, errMsg); err != nil {
+		log.Error(ctx, "failed to report job failure", "err", err)
 	}
-	w.hub.BroadcastError(jobID, "RENDER_FAILED", errMsg)
 }
 
 func (w *RenderWorker) generateMockResult(payload *model.RenderJobPayload) *model.RenderResultResponse {
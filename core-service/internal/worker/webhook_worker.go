@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/makeasinger/api/internal/model"
+	"github.com/makeasinger/api/internal/webhook"
+)
+
+// webhookDeliveryTimeout bounds a single delivery attempt, so a receiver
+// that accepts the connection and never responds doesn't hold this worker's
+// goroutine past Asynq's own retry schedule.
+const webhookDeliveryTimeout = 10 * time.Second
+
+// WebhookWorker delivers TaskTypeWebhookDelivery tasks: unlike
+// RenderWorker/MasterWorker/ExportWorker, it never calls back into the
+// runnerapi RPC contract, since a webhook delivery doesn't advance any job's
+// status -- the job already reached its terminal state before this task was
+// enqueued (see RenderService.fireWebhook). A returned error is Asynq's own
+// signal to retry with its default exponential backoff, up to
+// webhookMaxRetries, after which the task is archived -- that archive is
+// this subsystem's dead-letter log (see AdminQueueService / GET
+// /api/admin/queues).
+type WebhookWorker struct {
+	httpClient *http.Client
+}
+
+func NewWebhookWorker() *WebhookWorker {
+	return &WebhookWorker{httpClient: &http.Client{
+		Timeout:       webhookDeliveryTimeout,
+		CheckRedirect: refuseCrossHostRedirect,
+	}}
+}
+
+// refuseCrossHostRedirect rejects a redirect that would send the delivery
+// to a different host than the one CallbackURL was validated against --
+// otherwise a receiver that's allowed at submission time could redirect
+// the request anywhere, including a disallowed address, and
+// ValidateCallbackURL's check of the original URL would never see it.
+func refuseCrossHostRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Host != via[0].URL.Host {
+		return fmt.Errorf("refusing cross-host webhook redirect from %s to %s", via[0].URL.Host, req.URL.Host)
+	}
+	return nil
+}
+
+func (w *WebhookWorker) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.WebhookDeliveryPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal webhook delivery payload: %w", err)
+	}
+
+	if err := webhook.Deliver(ctx, w.httpClient, payload.URL, payload.Secret, payload.Body); err != nil {
+		return fmt.Errorf("job %s: %w", payload.JobID, err)
+	}
+	return nil
+}
@@ -64,15 +64,16 @@ type RenderStartResponse struct {
 
 // RenderStatusResponse represents the status of a render job
 type RenderStatusResponse struct {
-	JobID       string     `json:"jobId"`
-	Status      JobStatus  `json:"status"`
-	Progress    int        `json:"progress"`
-	CurrentStep string     `json:"currentStep,omitempty"`
-	Error       *string    `json:"error"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	StartedAt   *time.Time `json:"startedAt"`
-	CompletedAt *time.Time `json:"completedAt"`
-	RetryCount  int        `json:"retryCount"`
+	JobID         string     `json:"jobId"`
+	Status        JobStatus  `json:"status"`
+	Progress      int        `json:"progress"`
+	CurrentStep   string     `json:"currentStep,omitempty"`
+	Error         *string    `json:"error"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	StartedAt     *time.Time `json:"startedAt"`
+	CompletedAt   *time.Time `json:"completedAt"`
+	RetryCount    int        `json:"retryCount"`
+	ArchiveStatus string     `json:"archiveStatus,omitempty"`
 }
 
 // RenderResultResponse represents the result of a completed render
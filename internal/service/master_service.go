@@ -105,10 +105,11 @@ func (s *MasterService) GetStatus(ctx context.Context, jobID string) (*model.Mas
 	}
 
 	return &model.MasterStatusResponse{
-		JobID:       job.ID,
-		Status:      job.Status,
-		Progress:    job.Progress,
-		CurrentStep: job.CurrentStep,
+		JobID:         job.ID,
+		Status:        job.Status,
+		Progress:      job.Progress,
+		CurrentStep:   job.CurrentStep,
+		ArchiveStatus: string(job.ArchiveStatus),
 	}, nil
 }
 
@@ -169,6 +169,52 @@ func (h *Hub) BroadcastError(jobID string, code, message string) {
 	}
 }
 
+// BroadcastLog sends a batch of log lines for a job to all subscribers.
+func (h *Hub) BroadcastLog(jobID, stream string, lines []string) {
+	msg := model.WSLogMessage{
+		Type:   model.WSMessageTypeLog,
+		JobID:  jobID,
+		Stream: stream,
+		Lines:  lines,
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Failed to marshal log message: %v", err)
+		return
+	}
+
+	h.broadcast <- &BroadcastMessage{
+		JobID:   jobID,
+		Message: data,
+	}
+}
+
+// BroadcastStructuredError sends a structured error to all job subscribers,
+// including the per-item failure breakdown for partially-failed batch jobs.
+func (h *Hub) BroadcastStructuredError(jobID string, structErr *model.JobStructuredError) {
+	msg := model.WSErrorMessage{
+		Type:  model.WSMessageTypeError,
+		JobID: jobID,
+		Error: model.WSError{
+			Code:    structErr.Code,
+			Message: structErr.Message,
+			Items:   structErr.Items,
+		},
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Failed to marshal structured error message: %v", err)
+		return
+	}
+
+	h.broadcast <- &BroadcastMessage{
+		JobID:   jobID,
+		Message: data,
+	}
+}
+
 // HandleConnection handles a WebSocket connection
 func (h *Hub) HandleConnection(c *websocket.Conn, jobID string) {
 	client := &Client{
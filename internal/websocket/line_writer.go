@@ -0,0 +1,166 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// logFlushInterval bounds how long a line can sit buffered before being
+	// pushed to subscribers, even if the batch isn't full yet.
+	logFlushInterval = 200 * time.Millisecond
+	// logFlushMaxLines flushes early once a batch gets this large.
+	logFlushMaxLines = 64
+	// logJobCapBytes is the total amount of log output accepted per job;
+	// anything past this is silently dropped so a runaway job can't flood
+	// Redis or the socket.
+	logJobCapBytes = 2 * 1024 * 1024
+	// logTailMaxBytes is how much of the tail is kept in Redis for late
+	// subscribers that connect after some output has already streamed.
+	logTailMaxBytes = 64 * 1024
+	logTailTTL      = 24 * time.Hour
+)
+
+// LineWriter is an io.Writer that splits incoming writes on newlines,
+// batches them over a short window, and pushes the batches through a Hub as
+// WSMessageTypeLog messages. It also persists a bounded tail in Redis under
+// job:{id}:logs so clients that subscribe late still see recent output.
+type LineWriter struct {
+	hub    *Hub
+	redis  *redis.Client
+	jobID  string
+	stream string
+
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	pending []string
+	written int
+	timer   *time.Timer
+}
+
+// NewLineWriter creates a LineWriter that streams lines for jobID/stream
+// (e.g. "stdout", "stderr") through hub, persisting the tail via redisClient.
+// redisClient may be nil, in which case only live streaming happens.
+func NewLineWriter(hub *Hub, redisClient *redis.Client, jobID, stream string) *LineWriter {
+	return &LineWriter{
+		hub:    hub,
+		redis:  redisClient,
+		jobID:  jobID,
+		stream: stream,
+	}
+}
+
+// Write implements io.Writer, batching complete lines for later flush.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	if w.written >= logJobCapBytes {
+		return n, nil
+	}
+	if remaining := logJobCapBytes - w.written; len(p) > remaining {
+		p = p[:remaining]
+	}
+	w.written += len(p)
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Incomplete line — keep it buffered for the next Write/Close.
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.pending = append(w.pending, strings.TrimSuffix(line, "\n"))
+		if len(w.pending) >= logFlushMaxLines {
+			w.flushLocked()
+		}
+	}
+	w.scheduleFlushLocked()
+
+	return n, nil
+}
+
+// Close flushes any buffered partial line and stops the flush timer.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.pending = append(w.pending, w.buf.String())
+		w.buf.Reset()
+	}
+	w.flushLocked()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	return nil
+}
+
+func (w *LineWriter) scheduleFlushLocked() {
+	if w.timer != nil || len(w.pending) == 0 {
+		return
+	}
+	w.timer = time.AfterFunc(logFlushInterval, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.timer = nil
+		w.flushLocked()
+	})
+}
+
+// flushLocked pushes any pending lines to the hub and persists the tail.
+// Callers must hold w.mu.
+func (w *LineWriter) flushLocked() {
+	if len(w.pending) == 0 {
+		return
+	}
+	lines := w.pending
+	w.pending = nil
+
+	w.hub.BroadcastLog(w.jobID, w.stream, lines)
+	if w.redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		AppendLogTail(ctx, w.redis, w.jobID, lines)
+		cancel()
+	}
+}
+
+// AppendLogTail appends lines to the Redis-backed tail for a job's logs,
+// trimming the key back down to logTailMaxBytes so it doesn't grow
+// unbounded for long-running or chatty jobs.
+func AppendLogTail(ctx context.Context, redisClient *redis.Client, jobID string, lines []string) {
+	key := fmt.Sprintf("job:%s:logs", jobID)
+
+	chunk := strings.Join(lines, "\n") + "\n"
+	if err := redisClient.Append(ctx, key, chunk).Err(); err != nil {
+		return
+	}
+	redisClient.Expire(ctx, key, logTailTTL)
+
+	if length, err := redisClient.StrLen(ctx, key).Result(); err == nil && length > logTailMaxBytes {
+		tail, err := redisClient.GetRange(ctx, key, length-logTailMaxBytes, -1).Result()
+		if err == nil {
+			redisClient.Set(ctx, key, tail, logTailTTL)
+		}
+	}
+}
+
+// GetLogTail returns the persisted tail of a job's logs for late subscribers.
+func GetLogTail(ctx context.Context, redisClient *redis.Client, jobID string) (string, error) {
+	key := fmt.Sprintf("job:%s:logs", jobID)
+	tail, err := redisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return tail, err
+}
@@ -0,0 +1,149 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type problemTestRequest struct {
+	Genre string `validate:"required"`
+}
+
+func doProblemTestRequest(t *testing.T, accept string) *http.Response {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/validate", func(c *fiber.Ctx) error {
+		c.Locals(TraceIDKey, "trace-123")
+		err := validator.New().Struct(&problemTestRequest{})
+		return ValidationErrors(c, "Validation failed", err.(validator.ValidationErrors))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/validate", nil)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test failed: %v", err)
+	}
+	return resp
+}
+
+// TestValidationErrors_LegacyEnvelope confirms clients that don't ask for
+// problem+json keep getting the existing {error:{code,message,details}}
+// shape untouched.
+func TestValidationErrors_LegacyEnvelope(t *testing.T) {
+	resp := doProblemTestRequest(t, "application/json")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	var body ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode legacy envelope: %v", err)
+	}
+	if body.Error.Code != CodeValidationError {
+		t.Errorf("expected code %q, got %q", CodeValidationError, body.Error.Code)
+	}
+	details, ok := body.Error.Details.(map[string]interface{})
+	if !ok || details["Genre"] != "required" {
+		t.Errorf("expected details[Genre]=required, got %v", body.Error.Details)
+	}
+}
+
+// TestValidationErrors_ProblemJSON confirms a caller that asks for
+// application/problem+json gets an RFC 7807 body with a violations[] list
+// and the request's traceId echoed back.
+func TestValidationErrors_ProblemJSON(t *testing.T) {
+	resp := doProblemTestRequest(t, "application/problem+json")
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != "application/problem+json" {
+		t.Errorf("expected Content-Type application/problem+json, got %q", ct)
+	}
+
+	raw, _ := io.ReadAll(resp.Body)
+	var problem ProblemDetail
+	if err := json.Unmarshal(raw, &problem); err != nil {
+		t.Fatalf("failed to decode problem+json: %v\nbody: %s", err, raw)
+	}
+
+	if problem.Status != fiber.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", problem.Status)
+	}
+	if problem.Code != CodeValidationError {
+		t.Errorf("expected code %q, got %q", CodeValidationError, problem.Code)
+	}
+	if problem.TraceID != "trace-123" {
+		t.Errorf("expected traceId trace-123, got %q", problem.TraceID)
+	}
+	if len(problem.Violations) != 1 || problem.Violations[0].Field != "Genre" || problem.Violations[0].Rule != "required" {
+		t.Errorf("expected one Genre/required violation, got %+v", problem.Violations)
+	}
+	if problem.Violations[0].Slug != "required" {
+		t.Errorf("expected slug %q, got %q", "required", problem.Violations[0].Slug)
+	}
+	if len(problem.Violations[0].Path) != 1 || problem.Violations[0].Path[0] != "genre" {
+		t.Errorf("expected path [genre], got %+v", problem.Violations[0].Path)
+	}
+}
+
+type problemTestNestedRequest struct {
+	Channels []problemTestChannel `validate:"dive"`
+}
+
+type problemTestChannel struct {
+	VolumeDb float64 `validate:"gte=-60,lte=12"`
+}
+
+// TestPathFromNamespace_NestedSliceField confirms a validation failure on a
+// slice element's field reports an indexable path (["channels", 0,
+// "volumeDb"]) rather than just the leaf field name.
+func TestPathFromNamespace_NestedSliceField(t *testing.T) {
+	app := fiber.New()
+	app.Get("/validate", func(c *fiber.Ctx) error {
+		err := validator.New().Struct(&problemTestNestedRequest{
+			Channels: []problemTestChannel{{VolumeDb: 100}},
+		})
+		return ValidationErrors(c, "Validation failed", err.(validator.ValidationErrors))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/validate", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/problem+json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var problem ProblemDetail
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode problem+json: %v", err)
+	}
+
+	if len(problem.Violations) != 1 {
+		t.Fatalf("expected one violation, got %+v", problem.Violations)
+	}
+	v := problem.Violations[0]
+	wantPath := []interface{}{"channels", float64(0), "volumeDb"}
+	if len(v.Path) != len(wantPath) {
+		t.Fatalf("expected path %+v, got %+v", wantPath, v.Path)
+	}
+	for i := range wantPath {
+		if v.Path[i] != wantPath[i] {
+			t.Errorf("expected path %+v, got %+v", wantPath, v.Path)
+		}
+	}
+	if v.Params["max"] != "12" {
+		t.Errorf("expected params[max]=12, got %+v", v.Params)
+	}
+}
@@ -1,6 +1,13 @@
 package response
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/makeasinger/api/internal/errs"
+)
 
 // Error codes
 const (
@@ -9,9 +16,18 @@ const (
 	CodeForbidden       = "FORBIDDEN"
 	CodeNotFound        = "NOT_FOUND"
 	CodeRateLimited     = "RATE_LIMITED"
+	CodeConflict        = "CONFLICT"
 	CodeJobFailed       = "JOB_FAILED"
 	CodeServiceError    = "SERVICE_ERROR"
 	CodeAIError         = "AI_ERROR"
+
+	// Codes below surface failure modes specific to the export/master audio
+	// pipeline, where the job itself ran but the requested output could not
+	// be produced.
+	CodeUnsupportedFormat     = "UNSUPPORTED_FORMAT"
+	CodeStemFetchFailed       = "STEM_FETCH_FAILED"
+	CodeLUFSTargetUnreachable = "LUFS_TARGET_UNREACHABLE"
+	CodeCoverDecodeFailed     = "COVER_DECODE_FAILED"
 )
 
 type ErrorResponse struct {
@@ -25,6 +41,10 @@ type ErrorDetail struct {
 }
 
 func Error(c *fiber.Ctx, status int, code, message string, details interface{}) error {
+	if wantsProblemJSON(c) {
+		violations, _ := details.([]Violation)
+		return writeProblem(c, status, code, message, violations)
+	}
 	return c.Status(status).JSON(ErrorResponse{
 		Error: ErrorDetail{
 			Code:    code,
@@ -38,6 +58,47 @@ func ValidationError(c *fiber.Ctx, message string, details interface{}) error {
 	return Error(c, fiber.StatusBadRequest, CodeValidationError, message, details)
 }
 
+// ValidationErrors builds its details straight from go-playground/validator's
+// FieldErrors, so callers don't need their own formatValidationErrors-style
+// helper. It produces the same field->tag map as the legacy envelope, and a
+// richer violations[] list when the caller negotiated problem+json.
+func ValidationErrors(c *fiber.Ctx, message string, verrs validator.ValidationErrors) error {
+	if wantsProblemJSON(c) {
+		return writeProblem(c, fiber.StatusBadRequest, CodeValidationError, message, violationsFrom(verrs))
+	}
+	details := make(map[string]string, len(verrs))
+	for _, e := range verrs {
+		details[e.Field()] = e.Tag()
+	}
+	return ValidationError(c, message, details)
+}
+
+// UnsupportedFormat reports that the caller asked for an export/render
+// format this deployment can't produce (e.g. a codec FFmpeg wasn't built
+// with support for).
+func UnsupportedFormat(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusBadRequest, CodeUnsupportedFormat, message, nil)
+}
+
+// StemFetchFailed reports that a render's stem could not be retrieved from
+// storage (R2/Suno) for mastering or export.
+func StemFetchFailed(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusBadGateway, CodeStemFetchFailed, message, nil)
+}
+
+// LUFSTargetUnreachable reports that the audio microservice could not bring
+// a master within the requested loudness target without unacceptable
+// clipping or gain.
+func LUFSTargetUnreachable(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusUnprocessableEntity, CodeLUFSTargetUnreachable, message, nil)
+}
+
+// CoverDecodeFailed reports that a supplied cover art image could not be
+// decoded (corrupt file, unsupported encoding).
+func CoverDecodeFailed(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusBadRequest, CodeCoverDecodeFailed, message, nil)
+}
+
 func Unauthorized(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusUnauthorized, CodeUnauthorized, message, nil)
 }
@@ -54,10 +115,32 @@ func RateLimited(c *fiber.Ctx) error {
 	return Error(c, fiber.StatusTooManyRequests, CodeRateLimited, "Rate limit exceeded", nil)
 }
 
+func Conflict(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusConflict, CodeConflict, message, nil)
+}
+
 func ServiceError(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusInternalServerError, CodeServiceError, message, nil)
 }
 
+// FromError unwraps err into an *errs.Error and emits its Code/Status/
+// Message/Details, falling back to ServiceError for anything a service
+// hasn't migrated onto internal/errs yet. Handlers that already do their
+// own err.Error() inspection (string-matching "job not found" and the
+// like) can be simplified to call this once the service they call
+// returns internal/errs errors for every case they care about.
+func FromError(c *fiber.Ctx, err error) error {
+	var e *errs.Error
+	if errors.As(err, &e) {
+		var details interface{}
+		if e.Details != nil {
+			details = e.Details
+		}
+		return Error(c, e.Status, e.Code, e.Message, details)
+	}
+	return ServiceError(c, err.Error())
+}
+
 func AIError(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusBadGateway, CodeAIError, message, nil)
 }